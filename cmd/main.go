@@ -39,6 +39,22 @@ func main() {
 	r.POST("/whip", server.HandleWHIP)
 	// 会话管理端点
 	r.DELETE("/whip/sessions/:id", server.HandleDelete)
+	// Prometheus 指标
+	r.GET("/metrics", server.HandleMetrics)
+	// 会话数据与管理端点包含用户的对话内容并可修改会话，需携带令牌访问，未配置令牌时不开放
+	if auth, ok := server.AdminAuth(); ok {
+		protected := r.Group("", auth)
+		// 轮次延迟追踪
+		protected.GET("/sessions/:id/traces", server.HandleTraces)
+		protected.GET("/sessions/:id/traces/:turn", server.HandleTrace)
+		protected.GET("/sessions/:id/transcript", server.HandleTranscript)
+		// 会话事件流
+		protected.GET("/sessions/:id/events", server.HandleEvents)
+		// 管理端点：会话进行中热替换组件
+		protected.PUT("/admin/sessions/:id/components/:node", server.HandleReplaceComponent)
+	} else {
+		logger.Warn("server.admin.token is not set, session and admin endpoints are disabled")
+	}
 
 	logger.Info("Link Start")
 	if err := r.Run(fmt.Sprintf(":%d", config.Server.HTTPPort)); err != nil {
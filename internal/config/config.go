@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,12 +18,108 @@ type LogConfig struct {
 }
 
 type ServerConfig struct {
-	HTTPPort          int      `yaml:"http_port"`
-	UDPPort           int      `yaml:"udp_port"`
-	PublicIP          []string `yaml:"public_ip"`
-	LowLatency        bool     `yaml:"low_latency"`
-	Interrupt         bool     `yaml:"interrupt"`
-	SemanticInterrupt bool     `yaml:"semantic_interrupt"`
+	HTTPPort            int                       `yaml:"http_port"`
+	UDPPort             int                       `yaml:"udp_port"`
+	PublicIP            []string                  `yaml:"public_ip"`
+	LowLatency          bool                      `yaml:"low_latency"`
+	Speculative         bool                      `yaml:"speculative"` // 在稳定的中间识别结果上提前生成回复，与最终结果一致时直接使用
+	Interrupt           bool                      `yaml:"interrupt"`
+	SemanticInterrupt   bool                      `yaml:"semantic_interrupt"` // 是否在 ASR 之后加入语义打断分类，关闭时 pipelines 定义中的 semantic_interrupt 节点同样被移除
+	Restart             RestartConfig             `yaml:"restart"`
+	Health              HealthConfig              `yaml:"health"`
+	Turn                TurnConfig                `yaml:"turn"`
+	BargeIn             BargeInConfig             `yaml:"barge_in"`
+	InterruptClassifier InterruptClassifierConfig `yaml:"interrupt_classifier"`
+	Transcript          TranscriptConfig          `yaml:"transcript"`
+	Admin               AdminConfig               `yaml:"admin"`
+	Pipeline            string                    `yaml:"pipeline"` // 使用的 pipelines 定义名，为空时使用内置的语音对话链路
+}
+
+// RestartConfig 组件失败后的自动重启策略，零值字段使用默认值
+type RestartConfig struct {
+	InitialBackoff time.Duration `yaml:"initial_backoff"` // 两次重启之间的初始等待时间
+	MaxBackoff     time.Duration `yaml:"max_backoff"`     // 退避时间上限
+	MaxRestarts    int           `yaml:"max_restarts"`    // 窗口内最大重启次数，超过后结束会话
+	Window         time.Duration `yaml:"window"`          // 统计重启次数的时间窗口
+}
+
+// HealthConfig 组件健康检查的间隔与判定阈值，零值字段使用默认值
+type HealthConfig struct {
+	Interval          time.Duration `yaml:"interval"`            // 健康检查间隔
+	QueueGrowthChecks int           `yaml:"queue_growth_checks"` // 输入队列连续增长多少次检查后告警
+	StallWarning      time.Duration `yaml:"stall_warning"`       // 有待处理数据但没有进展多久后告警
+	StallError        time.Duration `yaml:"stall_error"`         // 有待处理数据但没有进展多久后判定组件失败
+	ErrorRateWarning  float64       `yaml:"error_rate_warning"`  // 检查间隔内错误率达到多少后告警
+}
+
+// TurnConfig 轮次切分的静音超时与按语言配置的句子结束规则，零值字段使用默认值
+type TurnConfig struct {
+	SilenceTimeout  time.Duration                  `yaml:"silence_timeout"`   // 距最近一次语音活动超过该时间后结束句子
+	MaxTurnDuration time.Duration                  `yaml:"max_turn_duration"` // 一句话的最长持续时间
+	SpeechThreshold float64                        `yaml:"speech_threshold"`  // 输入音频的 RMS 能量达到该值时视为仍在说话
+	Language        string                         `yaml:"language"`          // 选用 languages 中的规则
+	Languages       map[string]EndpointRulesConfig `yaml:"languages"`         // 覆盖或新增语言的规则
+
+	// 语义断句：按识别文本的完整度在最短与最长静音超时之间调整，不再按标点立即结束句子
+	Scorer            string        `yaml:"scorer"`              // 完整度评估方式 heuristic 或 openai，为空时不开启
+	ScorerModel       string        `yaml:"scorer_model"`        // openai 评估使用的模型，使用 llm.openai 的 api_key 与 base_url
+	MinSilenceTimeout time.Duration `yaml:"min_silence_timeout"` // 确定说完时的静音超时
+	MaxSilenceTimeout time.Duration `yaml:"max_silence_timeout"` // 明显没说完时的静音超时
+}
+
+// EndpointRulesConfig 一种语言按标点结束句子的规则
+type EndpointRulesConfig struct {
+	PunctuationMarks  []string `yaml:"punctuation_marks"`   // 表示句子结束的标点符号
+	MinSentenceLength int      `yaml:"min_sentence_length"` // 按标点结束句子所需的最少字符数，更短的句子等待静音超时
+}
+
+// BargeInConfig 回复播放期间用户说话打断的检测参数，零值字段使用默认值
+type BargeInConfig struct {
+	Enabled         bool          `yaml:"enabled"`          // 是否在 ASR 之前加入打断检测，关闭时 pipelines 定义中的 barge_in 节点同样被移除
+	MinDuration     time.Duration `yaml:"min_duration"`     // 播放期间用户持续说话多久后打断
+	EnergyThreshold float64       `yaml:"energy_threshold"` // 输入音频的 RMS 能量达到该值时视为说话
+	Cooldown        time.Duration `yaml:"cooldown"`         // 两次打断之间的最短间隔
+}
+
+// InterruptClassifierConfig 语义打断的分类方式，零值字段使用默认值
+type InterruptClassifierConfig struct {
+	Type        string        `yaml:"type"`         // rules 或 openai，openai 使用 llm.openai 的 api_key 与 base_url
+	Model       string        `yaml:"model"`        // openai 分类使用的模型，应选用响应快的小模型
+	HoldTimeout time.Duration `yaml:"hold_timeout"` // 打断检测触发后等待识别结果的最长时间，超时视为噪声
+}
+
+// AdminConfig 会话与管理接口的访问控制
+type AdminConfig struct {
+	Token string `yaml:"token"` // 请求需携带 Authorization: Bearer <token>，以 $ 开头时从环境变量读取，为空时不开放这些接口
+}
+
+// TranscriptConfig 会话记录的保存位置
+type TranscriptConfig struct {
+	Dir string `yaml:"dir"` // 会话结束时将记录保存为该目录下的 <会话 ID>.json，为空时不保存
+}
+
+// PipelineConfig 声明式的 pipeline 定义，节点按名称引用
+type PipelineConfig struct {
+	Nodes []NodeConfig `yaml:"nodes"`
+	Chain []string     `yaml:"chain"` // 按顺序串联的节点名，等价于依次添加边
+	Edges []EdgeConfig `yaml:"edges"` // 额外的边，用于扇出与扇入
+}
+
+// NodeConfig 定义一个组件节点
+type NodeConfig struct {
+	Name       string                 `yaml:"name"`
+	Type       string                 `yaml:"type"` // 注册的组件类型，为空时由运行时绑定（如连接的 source/sink）
+	Params     map[string]interface{} `yaml:"params"`
+	IgnoreTurn bool                   `yaml:"ignore_turn"` // 不按轮次过滤数据包，音频前处理组件通常需要开启
+}
+
+// EdgeConfig 定义两个节点之间的连接
+type EdgeConfig struct {
+	From         string        `yaml:"from"`
+	To           string        `yaml:"to"`
+	BufferSize   int           `yaml:"buffer_size"`  // 边上 channel 的缓冲大小，0 表示沿用上游组件的输出 channel
+	Backpressure string        `yaml:"backpressure"` // drop_newest/drop_oldest/block/coalesce，为空时沿用上游组件的策略
+	Timeout      time.Duration `yaml:"timeout"`      // block/coalesce 策略的最长等待时间
 }
 
 type LLMConfig struct {
@@ -64,6 +161,8 @@ type Config struct {
 	LLM    LLMConfig    `yaml:"llm"`
 	ASR    ASRConfig    `yaml:"asr"`
 	TTS    TTSConfig    `yaml:"tts"`
+
+	Pipelines map[string]PipelineConfig `yaml:"pipelines"`
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -74,6 +74,37 @@ func TestWAVReadWrite(t *testing.T) {
 		assert.Equal(t, testData, readData[:n])
 	})
 
+	t.Run("Flush WAV Header", func(t *testing.T) {
+		filename := path.Join(testDir, "test_flush.wav")
+
+		writer, err := NewFileWriter(filename, format)
+		assert.NoError(t, err)
+		assert.NoError(t, writer.WriteSamples(testData[:1000]))
+		assert.NoError(t, writer.Flush())
+
+		// 冲刷后文件头即包含已写入的数据大小
+		file, err := os.Open(filename)
+		assert.NoError(t, err)
+		reader, err := NewReader(file)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(2000), reader.GetDataSize())
+		file.Close()
+
+		// 冲刷后继续写入追加到数据末尾
+		assert.NoError(t, writer.WriteSamples(testData[1000:2000]))
+		assert.NoError(t, writer.Close())
+
+		file, err = os.Open(filename)
+		assert.NoError(t, err)
+		defer file.Close()
+		reader, err = NewReader(file)
+		assert.NoError(t, err)
+		readData := make([]int16, 2000)
+		n, err := reader.ReadSamples(readData)
+		assert.NoError(t, err)
+		assert.Equal(t, testData[:2000], readData[:n])
+	})
+
 	t.Run("Write and Read WAV Memory", func(t *testing.T) {
 		// 使用内存缓冲区
 		buf := &bytes.Buffer{}
@@ -88,9 +88,20 @@ func (w *Writer) WriteSamples(samples []int16) error {
 	return nil
 }
 
-// Close 更新文件头并关闭写入器
-func (w *Writer) Close() error {
-	// 更新文件头中的数据大小
+// Flush 按已写入的数据大小更新文件头，之后可继续写入
+func (w *Writer) Flush() error {
+	if err := w.updateHeader(); err != nil {
+		return err
+	}
+	// 回到数据末尾继续写入
+	if _, err := w.writer.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("failed to seek to end: %v", err)
+	}
+	return nil
+}
+
+// updateHeader 更新文件头中的数据大小
+func (w *Writer) updateHeader() error {
 	w.header.Subchunk2Size = w.dataSize
 	w.header.ChunkSize = 36 + w.dataSize
 
@@ -104,6 +115,14 @@ func (w *Writer) Close() error {
 	if err := w.writeHeader(); err != nil {
 		return fmt.Errorf("failed to update header: %v", err)
 	}
+	return nil
+}
+
+// Close 更新文件头并关闭写入器
+func (w *Writer) Close() error {
+	if err := w.updateHeader(); err != nil {
+		return err
+	}
 
 	// 关闭写入器
 	if closer, ok := w.writer.(io.Closer); ok {
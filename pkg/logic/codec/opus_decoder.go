@@ -32,6 +32,10 @@ func NewOpusDecoder(sampleRateIn, channelsIn int) (*OpusDecoder, error) {
 		channelsIn:    channelsIn,
 	}
 
+	decoder.SetInputFormat(pipeline.NewOpusFormat(sampleRateIn, channelsIn))
+	decoder.SetOutputFormat(pipeline.NewPCMFormat(sampleRateIn, channelsIn))
+	decoder.SetBackpressure(pipeline.DropOldestPolicy())
+
 	// 设置处理函数
 	decoder.BaseComponent.SetProcess(decoder.processPacket)
 	decoder.RegisterCommandHandler(pipeline.PacketCommandInterrupt, decoder.handleInterrupt)
@@ -48,22 +52,23 @@ func (d *OpusDecoder) handleInterrupt(packet pipeline.Packet) {
 
 // processPacket 处理输入的数据包
 func (d *OpusDecoder) processPacket(packet pipeline.Packet) {
-	audioPacket, ok := packet.Data.(AudioPacket)
+	frame, ok := d.ExpectAudioFrame(packet)
 	if !ok {
-		d.HandleUnsupportedData(packet.Data)
 		return
 	}
 
-	// 处理音频包
-	d.processAudio(audioPacket, packet)
+	// 处理音频帧
+	d.processAudio(frame)
 }
 
-// processAudio 处理音频包
-func (d *OpusDecoder) processAudio(audioPacket AudioPacket, packet pipeline.Packet) {
-	// 解码 Opus 数据为 PCM
-	pcmData := make([]int16, 960*d.channelsIn)
-	n, err := d.opusDecoder.Decode(audioPacket.Payload(), pcmData)
+// processAudio 处理音频帧
+func (d *OpusDecoder) processAudio(frame pipeline.AudioFrame) {
+	// 解码 Opus 数据为 PCM，单帧最长 120ms
+	pcm := pipeline.NewSampleBuffer(maxOpusFrameSamples(d.sampleRateIn) * d.channelsIn)
+	n, err := d.opusDecoder.Decode(frame.Payload, pcm.Samples)
+	frame.Release()
 	if err != nil {
+		pcm.Release()
 		if strings.Contains(err.Error(), "no data supplied") {
 			return
 		}
@@ -73,47 +78,11 @@ func (d *OpusDecoder) processAudio(audioPacket AudioPacket, packet pipeline.Pack
 	}
 
 	// 将解码后的 PCM 数据传递给下一个组件
-	d.SendPacket(pcmData[:n*d.channelsIn], d)
-}
-
-// GetID 实现 Component 接口
-func (d *OpusDecoder) GetID() interface{} {
-	return d.GetSeq()
-}
-
-// 为了向后兼容，保留这些方法
-func (d *OpusDecoder) Process(packet pipeline.Packet) {
-	select {
-	case d.GetInputChan() <- packet:
-	default:
-		logger.Error("**%s** Input channel full, dropping packet", d.GetName())
-	}
-}
-
-func (d *OpusDecoder) SetOutput(output func(pipeline.Packet)) {
-	outChan := make(chan pipeline.Packet, 100)
-	d.SetOutputChan(outChan)
-	go func() {
-		for packet := range outChan {
-			if output != nil {
-				output(packet)
-			}
-		}
-	}()
-}
-
-// Start 实现 Component 接口
-func (d *OpusDecoder) Start() error {
-	d.BaseComponent.Start()
-	return nil
-}
-
-// GetHealth 实现 Component 接口
-func (d *OpusDecoder) GetHealth() pipeline.ComponentHealth {
-	return d.BaseComponent.GetHealth()
+	pcm.Samples = pcm.Samples[:n*d.channelsIn]
+	d.SendPacket(pipeline.NewPooledPCMFrame(pcm, d.sampleRateIn, d.channelsIn, frame.Timestamp), d)
 }
 
-// UpdateHealth 实现 Component 接口
-func (d *OpusDecoder) UpdateHealth(health pipeline.ComponentHealth) {
-	d.BaseComponent.UpdateHealth(health)
+// maxOpusFrameSamples 返回单个 Opus 包在给定采样率下的最大采样点数(每声道)
+func maxOpusFrameSamples(sampleRate int) int {
+	return sampleRate * 120 / 1000
 }
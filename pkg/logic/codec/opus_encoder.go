@@ -13,16 +13,22 @@ import (
 type OpusEncoder struct {
 	*pipeline.BaseComponent
 	opusEncoder *opus.Encoder
+	sampleRate  int
+	channels    int
 	frameSize   int                // 每帧的采样点数
 	dataBuffer  []int16            // PCM 数据缓冲区
+	bufferTs    time.Duration      // 缓冲区首个采样的媒体时间戳
 	encodeChan  chan encodeRequest // 新增：编码请求通道
 	metrics     pipeline.TurnMetrics
 }
 
 // 新增：编码请求结构
 type encodeRequest struct {
-	data    []int16
-	turnSeq int
+	data      *pipeline.AudioBuffer // 待编码的采样，编码协程复制后释放
+	turnSeq   int
+	timestamp time.Duration       // 首个采样的媒体时间戳
+	trace     *pipeline.TurnTrace // 所属轮次的延迟追踪
+	flush     chan struct{}       // 非空时用静音补齐剩余采样并编码，完成后关闭
 }
 
 func NewOpusEncoder(sampleRate, channels int) (*OpusEncoder, error) {
@@ -35,16 +41,26 @@ func NewOpusEncoder(sampleRate, channels int) (*OpusEncoder, error) {
 	encoder := &OpusEncoder{
 		BaseComponent: pipeline.NewBaseComponent("OpusEncoder", 4000),
 		opusEncoder:   opusEncoder,
-		frameSize:     960 * channels, // 每帧 20ms，对于 48kHz 采样率，就是 960 个采样点
+		sampleRate:    sampleRate,
+		channels:      channels,
+		frameSize:     sampleRate / 50 * channels, // 每帧 20ms，对于 48kHz 采样率，就是 960 个采样点
 		dataBuffer:    make([]int16, 0),
 		encodeChan:    make(chan encodeRequest, 100),
 	}
 
+	encoder.SetInputFormat(pipeline.NewPCMFormat(sampleRate, channels))
+	encoder.SetOutputFormat(pipeline.NewOpusFormat(sampleRate, channels))
+	encoder.SetBackpressure(pipeline.DropOldestPolicy())
+
 	// 注册打断指令处理函数
 	encoder.RegisterCommandHandler(pipeline.PacketCommandInterrupt, encoder.handleInterrupt)
 
 	// 设置处理函数
 	encoder.BaseComponent.SetProcess(encoder.processPacket)
+	// 冲刷或流结束时编码不足一帧的剩余采样
+	encoder.SetFlushHandler(encoder.flush)
+	// 输入排空后关闭编码队列，编码协程处理完剩余数据后退出
+	encoder.SetDrainHandler(func() { close(encoder.encodeChan) })
 
 	return encoder, nil
 }
@@ -68,79 +84,150 @@ func (e *OpusEncoder) processPacket(packet pipeline.Packet) {
 	if packet.TurnSeq < e.GetCurTurnSeq() {
 		logger.Info("**%s** cur turn: %d, drop old turn packet(seq: %d)", e.GetName(), e.GetCurTurnSeq(), packet.TurnSeq)
 		e.dataBuffer = e.dataBuffer[:0]
+		pipeline.ReleasePacket(packet)
 		return
 	}
 
-	data, ok := packet.Data.([]int16)
+	frame, ok := e.ExpectAudioFrame(packet)
 	if !ok {
-		e.HandleUnsupportedData(packet.Data)
 		return
 	}
 
+	// 缓冲区为空时，以当前帧的时间戳作为起点
+	if len(e.dataBuffer) == 0 {
+		e.bufferTs = frame.Timestamp
+	}
+
 	// 将新数据添加到缓冲区
-	e.dataBuffer = append(e.dataBuffer, data...)
+	e.dataBuffer = append(e.dataBuffer, frame.Samples...)
+	frame.Release()
 
 	// 发送编码请求
+	data := e.takeBuffered()
 	select {
-	case e.encodeChan <- encodeRequest{data: e.dataBuffer, turnSeq: packet.TurnSeq}:
-		// print turn_metric_stat
-		for _, k := range packet.TurnMetricKeys {
-			logger.Info("turn_metric_stat: %s, %d, %d, latency: %d ms", k,
-				packet.TurnMetricStat[k].TurnStartTs,
-				packet.TurnMetricStat[k].TurnEndTs,
-				packet.TurnMetricStat[k].TurnEndTs-packet.TurnMetricStat[k].TurnStartTs)
-		}
-		// log.Printf("turn latency(asr start): %d ms",
-		// 	e.metrics.TurnStartTs-packet.TurnMetricStat[packet.TurnMetricKeys[0]].TurnStartTs)
-		// log.Printf("turn latency(asr end): %d ms",
-		// 	e.metrics.TurnStartTs-packet.TurnMetricStat[packet.TurnMetricKeys[0]].TurnEndTs)
-
-		e.dataBuffer = make([]int16, 0) // 清空缓冲区
+	case e.encodeChan <- encodeRequest{data: data, turnSeq: packet.TurnSeq, timestamp: e.bufferTs, trace: packet.Trace}:
+		e.dataBuffer = e.dataBuffer[:0] // 清空缓冲区
 	default:
+		data.Release()
 		logger.Error("**%s** Encode channel full, dropping data", e.GetName())
 	}
 }
 
+// takeBuffered 将缓冲区中的采样复制到池化的缓冲区，交给编码协程
+func (e *OpusEncoder) takeBuffered() *pipeline.AudioBuffer {
+	data := pipeline.NewSampleBuffer(len(e.dataBuffer))
+	copy(data.Samples, e.dataBuffer)
+	return data
+}
+
+// flush 请求编码协程补齐并编码剩余采样，等待完成后返回，保证转发的指令位于编码数据之后
+func (e *OpusEncoder) flush() {
+	done := make(chan struct{})
+	data := e.takeBuffered()
+	select {
+	case e.encodeChan <- encodeRequest{data: data, turnSeq: e.GetCurTurnSeq(), timestamp: e.bufferTs, flush: done}:
+		e.dataBuffer = e.dataBuffer[:0]
+	case <-e.GetStopCh():
+		data.Release()
+		return
+	}
+	select {
+	case <-done:
+	case <-e.GetStopCh():
+	}
+}
+
 // encodeLoop 在单独的 goroutine 中处理编码
+// 不足一帧的采样留到下一个请求，轮次切换时丢弃
 func (e *OpusEncoder) encodeLoop() {
-	for req := range e.encodeChan {
-		data := req.data
-		for len(data) >= e.frameSize {
-			if req.turnSeq < e.GetCurTurnSeq() {
-				logger.Debug("**%s** encode loop drop old turn packet(seq: %d)", e.GetName(), req.turnSeq)
-				break
+	var pending []int16
+	var pendingTs time.Duration
+	pendingTurn := -1
+	for {
+		var req encodeRequest
+		select {
+		case <-e.GetStopCh():
+			return
+		case r, ok := <-e.encodeChan:
+			if !ok {
+				return
 			}
+			req = r
+		}
 
-			// 获取一帧数据
-			frame := data[:e.frameSize]
-
-			// 分配足够大的缓冲区用于 Opus 编码
-			opusFrame := make([]byte, 2048)
-			n, err := e.opusEncoder.Encode(frame, opusFrame)
-			if err != nil {
-				logger.Error("**%s** Opus encoding failed: %v", e.GetName(), err)
-				e.UpdateErrorStatus(err)
-				break
+		if req.turnSeq != pendingTurn {
+			pending = pending[:0]
+			pendingTurn = req.turnSeq
+		}
+		if len(pending) == 0 {
+			pendingTs = req.timestamp
+		}
+		pending = append(pending, req.data.Samples...)
+		req.data.Release()
+		if req.flush != nil {
+			if rem := len(pending) % e.frameSize; rem != 0 {
+				pending = append(pending, make([]int16, e.frameSize-rem)...)
 			}
+		}
+		// 剩余采样移到开头，复用 pending 的底层数组
+		var rest []int16
+		rest, pendingTs = e.encodeFrames(pending, pendingTs, req.turnSeq, req.trace)
+		pending = pending[:copy(pending, rest)]
+		if req.flush != nil {
+			close(req.flush)
+		}
+	}
+}
 
-			// 创建 AudioPacket
-			audioPacket := NewRTPAudioPacket(opusFrame[:n], uint32(time.Now().UnixNano()/1e6))
-
-			// 发送编码后的数据
-			e.SendPacket(audioPacket, e)
-
-			// 更新缓冲区
-			data = data[e.frameSize:]
-
-			// 更新健康状态
-			health := e.GetHealth()
-			health.ProcessedCount++
-			health.LastUpdateTime = time.Now()
-			e.UpdateHealth(health)
+// encodeFrames 按帧编码并输出，返回不足一帧的剩余采样及其时间戳
+func (e *OpusEncoder) encodeFrames(data []int16, ts time.Duration, turnSeq int, trace *pipeline.TurnTrace) ([]int16, time.Duration) {
+	frameDuration := pipeline.SamplesDuration(e.frameSize, e.sampleRate, e.channels)
+	for len(data) >= e.frameSize {
+		if e.Context().Err() != nil {
+			return nil, ts
+		}
+		if turnSeq < e.GetCurTurnSeq() {
+			logger.Debug("**%s** encode loop drop old turn packet(seq: %d)", e.GetName(), turnSeq)
+			return nil, ts
+		}
 
-			time.Sleep(18 * time.Millisecond)
+		// 获取一帧数据
+		frame := data[:e.frameSize]
+
+		// 从缓冲池取出足够大的缓冲区用于 Opus 编码，由输出端发送后释放
+		opusFrame := pipeline.NewByteBuffer(2048)
+		n, err := e.opusEncoder.Encode(frame, opusFrame.Payload)
+		if err != nil {
+			opusFrame.Release()
+			logger.Error("**%s** Opus encoding failed: %v", e.GetName(), err)
+			e.UpdateErrorStatus(err)
+			return nil, ts
 		}
+		opusFrame.Payload = opusFrame.Payload[:n]
+
+		// 发送编码后的数据
+		e.ForwardPacket(pipeline.Packet{
+			Data:    pipeline.NewPooledEncodedFrame(opusFrame, e.GetOutputFormat(), frameDuration, ts),
+			Seq:     e.GetSeq(),
+			Src:     e,
+			TurnSeq: e.GetCurTurnSeq(),
+			Trace:   trace,
+		})
+		e.IncrSeq()
+
+		// 更新缓冲区
+		data = data[e.frameSize:]
+		ts += frameDuration
+
+		// 更新健康状态
+		health := e.GetHealth()
+		health.ProcessedCount++
+		health.LastUpdateTime = time.Now()
+		e.UpdateHealth(health)
+
+		time.Sleep(18 * time.Millisecond)
 	}
+	return data, ts
 }
 
 // SetFrameSize 设置每帧的采样点数
@@ -151,50 +238,11 @@ func (e *OpusEncoder) SetFrameSize(size int) {
 // Stop 实现 Component 接口
 func (e *OpusEncoder) Stop() {
 	e.BaseComponent.Stop()
-	close(e.encodeChan)
-	e.opusEncoder = nil
-	e.dataBuffer = nil
-}
-
-// GetID 实现 Component 接口
-func (e *OpusEncoder) GetID() interface{} {
-	return e.GetSeq()
-}
-
-// 为了向后兼容，保留这些方法
-func (e *OpusEncoder) Process(packet pipeline.Packet) {
-	select {
-	case e.GetInputChan() <- packet:
-	default:
-		logger.Error("**%s** Input channel full, dropping packet", e.GetName())
-	}
-}
-
-func (e *OpusEncoder) SetOutput(output func(pipeline.Packet)) {
-	outChan := make(chan pipeline.Packet, 100)
-	e.SetOutputChan(outChan)
-	go func() {
-		for packet := range e.GetOutputChan() {
-			if output != nil {
-				output(packet)
-			}
-		}
-	}()
 }
 
 // Start 实现 Component 接口
 func (e *OpusEncoder) Start() error {
-	// 启动编码 goroutine
-	go e.encodeLoop()
+	// 启动编码 goroutine，组件退出前会等待其编码完已提交的数据
+	e.Go(e.encodeLoop)
 	return e.BaseComponent.Start()
 }
-
-// GetHealth 实现 Component 接口
-func (e *OpusEncoder) GetHealth() pipeline.ComponentHealth {
-	return e.BaseComponent.GetHealth()
-}
-
-// UpdateHealth 实现 Component 接口
-func (e *OpusEncoder) UpdateHealth(health pipeline.ComponentHealth) {
-	e.BaseComponent.UpdateHealth(health)
-}
@@ -0,0 +1,12 @@
+package codec
+
+import "streamlink/pkg/logic/pipeline"
+
+func init() {
+	pipeline.Register("opus_decoder", func(params pipeline.Params) (pipeline.Component, error) {
+		return NewOpusDecoder(params.Int("sample_rate", 48000), params.Int("channels", 2))
+	})
+	pipeline.Register("opus_encoder", func(params pipeline.Params) (pipeline.Component, error) {
+		return NewOpusEncoder(params.Int("sample_rate", 48000), params.Int("channels", 2))
+	})
+}
@@ -5,8 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"streamlink/pkg/logger"
-	"streamlink/pkg/logic/codec"
 	"streamlink/pkg/logic/pipeline"
+	"sync"
+	"time"
 
 	"github.com/pion/rtp"
 	"github.com/pion/webrtc/v4/pkg/media/oggwriter"
@@ -15,8 +16,10 @@ import (
 // OggDumper 结构体 (实现 Component 接口)
 type OggDumper struct {
 	*pipeline.BaseComponent
-	oggFile *oggwriter.OggWriter
-	seq     int
+	oggFile    *oggwriter.OggWriter
+	seq        int
+	sampleRate uint32
+	closeOnce  sync.Once
 }
 
 func NewOggDumper(sampleRateIn uint32, channelsIn uint16, fileName string) (*OggDumper, error) {
@@ -36,11 +39,15 @@ func NewOggDumper(sampleRateIn uint32, channelsIn uint16, fileName string) (*Ogg
 		BaseComponent: pipeline.NewBaseComponent("OggDumper", 100),
 		oggFile:       oggFile,
 		seq:           0,
+		sampleRate:    sampleRateIn,
 	}
+	d.SetInputFormat(pipeline.NewOpusFormat(int(sampleRateIn), int(channelsIn)))
 
 	// 设置处理函数
 	d.BaseComponent.SetProcess(d.processPacket)
 	d.RegisterCommandHandler(pipeline.PacketCommandInterrupt, d.handleInterrupt)
+	// 流结束时关闭文件，写出最后一页
+	d.SetDrainHandler(func() { d.Close() })
 
 	return d, nil
 }
@@ -60,95 +67,44 @@ func (d *OggDumper) processPacket(packet pipeline.Packet) {
 		return
 	}
 
-	switch data := packet.Data.(type) {
-	case *rtp.Packet:
-		if err := d.oggFile.WriteRTP(data); err != nil {
-			logger.Error("**%s** Failed to write RTP to OGG: %v", d.GetName(), err)
-			d.UpdateErrorStatus(err)
-			return
-		}
-		// 转发数据包
-		d.SendPacket(data, d)
-	case codec.AudioPacket:
-		// 创建 RTP 包
-		rtpPacket := &rtp.Packet{
-			Header: rtp.Header{
-				Version:        2,
-				PayloadType:    111, // Opus 的默认 payload type
-				SequenceNumber: uint16(d.seq),
-				Timestamp:      data.Timestamp(),
-				SSRC:           0,
-			},
-			Payload: data.Payload(),
-		}
-
-		if err := d.oggFile.WriteRTP(rtpPacket); err != nil {
-			logger.Error("**%s** Failed to write AudioPacket to OGG: %v", d.GetName(), err)
-			d.UpdateErrorStatus(err)
-			return
-		}
-		// 转发数据包
-		d.SendPacket(data, d)
-	default:
-		d.HandleUnsupportedData(packet.Data)
+	frame, ok := d.ExpectAudioFrame(packet)
+	if !ok {
+		return
 	}
-}
 
-// GetID 实现 Component 接口
-func (d *OggDumper) GetID() interface{} {
-	return d.GetSeq()
-}
-
-// Stop 实现 Component 接口，扩展基础组件的 Stop 方法
-func (d *OggDumper) Stop() {
-	d.BaseComponent.Stop()
-	if d.oggFile != nil {
-		d.oggFile.Close()
-		d.oggFile = nil
+	// 创建 RTP 包，时间戳由媒体时间戳换算为 RTP 时钟
+	rtpPacket := &rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			PayloadType:    111, // Opus 的默认 payload type
+			SequenceNumber: uint16(d.seq),
+			Timestamp:      uint32(frame.Timestamp * time.Duration(d.sampleRate) / time.Second),
+			SSRC:           0,
+		},
+		Payload: frame.Payload,
 	}
-}
+	d.seq++
 
-// 为了向后兼容，保留这些方法
-func (d *OggDumper) Process(packet pipeline.Packet) {
-	select {
-	case d.GetInputChan() <- packet:
-	default:
-		logger.Error("**%s** Input channel full, dropping packet", d.GetName())
+	if err := d.oggFile.WriteRTP(rtpPacket); err != nil {
+		logger.Error("**%s** Failed to write audio frame to OGG: %v", d.GetName(), err)
+		d.UpdateErrorStatus(err)
+		return
 	}
+	// 转发数据包
+	d.SendPacket(frame, d)
 }
 
-func (d *OggDumper) SetOutput(output func(pipeline.Packet)) {
-	outChan := make(chan pipeline.Packet, 100)
-	d.SetOutputChan(outChan)
-	go func() {
-		for packet := range d.GetOutputChan() {
-			if output != nil {
-				output(packet)
-			}
-		}
-	}()
+// Stop 实现 Component 接口，扩展基础组件的 Stop 方法
+func (d *OggDumper) Stop() {
+	d.BaseComponent.Stop()
+	d.Close()
 }
 
-// Close 关闭 OGG 文件
+// Close 关闭 OGG 文件，可重复调用
 func (d *OggDumper) Close() error {
-	if d.oggFile != nil {
-		return d.oggFile.Close()
-	}
-	return nil
-}
-
-// Start 实现 Component 接口
-func (d *OggDumper) Start() error {
-	d.BaseComponent.Start()
-	return nil
-}
-
-// GetHealth 实现 Component 接口
-func (d *OggDumper) GetHealth() pipeline.ComponentHealth {
-	return d.BaseComponent.GetHealth()
-}
-
-// UpdateHealth 实现 Component 接口
-func (d *OggDumper) UpdateHealth(health pipeline.ComponentHealth) {
-	d.BaseComponent.UpdateHealth(health)
+	var err error
+	d.closeOnce.Do(func() {
+		err = d.oggFile.Close()
+	})
+	return err
 }
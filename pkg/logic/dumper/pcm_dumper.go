@@ -6,14 +6,15 @@ import (
 	"path/filepath"
 	"streamlink/pkg/logger"
 	"streamlink/pkg/logic/pipeline"
+	"sync"
 )
 
-// PCMDumper 结构体 (实现 Component 接口)
+// PCMDumper 将经过的 PCM 音频写入文件，数据原样转发
 type PCMDumper struct {
-	*pipeline.BaseComponent
-	file     *os.File
-	fileName string
-	seq      int
+	*pipeline.Stage[pipeline.AudioFrame, pipeline.AudioFrame]
+	file      *os.File
+	fileName  string
+	closeOnce sync.Once
 }
 
 // ffplay -ar 48000 -ch_layout stereo  -f s16le -i input.pcm
@@ -35,96 +36,48 @@ func NewPCMDumper(fileName string) (*PCMDumper, error) {
 	}
 
 	dumper := &PCMDumper{
-		BaseComponent: pipeline.NewBaseComponent("PCMDumper", 100),
-		file:          file,
-		fileName:      fileName,
-		seq:           0,
+		file:     file,
+		fileName: fileName,
 	}
+	dumper.Stage = pipeline.NewStage("PCMDumper", 100, dumper.write)
 
-	// 设置处理函数
-	dumper.BaseComponent.SetProcess(dumper.processPacket)
-	dumper.RegisterCommandHandler(pipeline.PacketCommandInterrupt, dumper.handleInterrupt)
-	return dumper, nil
-}
-
-func (d *PCMDumper) handleInterrupt(packet pipeline.Packet) {
-	logger.Info("**%s** Received interrupt command for turn %d", d.GetName(), packet.TurnSeq)
-	d.SetCurTurnSeq(packet.TurnSeq)
+	// 采样率和声道数由上游决定，这里只要求 PCM
+	dumper.SetInputFormat(pipeline.AudioFormat{Format: pipeline.SampleFormatS16})
 
-	d.ForwardPacket(packet)
+	// 冲刷时将数据落盘，流结束时关闭文件
+	dumper.SetFlushHandler(dumper.flush)
+	dumper.SetDrainHandler(dumper.closeFile)
+	return dumper, nil
 }
 
-// processPacket 处理输入的数据包
-func (d *PCMDumper) processPacket(packet pipeline.Packet) {
-	// 处理指令
-	if d.HandleCommandPacket(packet) {
-		return
+// write 将 PCM 数据写入文件并转发
+func (d *PCMDumper) write(frame pipeline.AudioFrame, _ pipeline.Packet, emit func(pipeline.AudioFrame)) error {
+	// 先写入再转发，下游可能在处理后释放池化的缓冲区
+	_, err := d.file.Write(frame.Bytes())
+	emit(frame)
+	if err != nil {
+		return fmt.Errorf("failed to write PCM data: %w", err)
 	}
+	return nil
+}
 
-	if data, ok := packet.Data.([]int16); ok {
-		// 将 PCM 数据写入文件
-		pcmBytes := make([]byte, len(data)*2)
-		for i, sample := range data {
-			pcmBytes[i*2] = byte(sample)
-			pcmBytes[i*2+1] = byte(sample >> 8)
-		}
-		if _, err := d.file.Write(pcmBytes); err != nil {
-			logger.Error("**%s** Failed to write PCM data: %v", d.GetName(), err)
-			d.UpdateErrorStatus(err)
-		}
-
-		// 转发数据包
-		d.ForwardPacket(packet)
-		d.seq++
-	} else {
-		d.HandleUnsupportedData(packet.Data)
+// flush 将已写入的数据落盘
+func (d *PCMDumper) flush() {
+	if err := d.file.Sync(); err != nil {
+		logger.Error("**%s** Failed to sync PCM file: %v", d.GetName(), err)
+		d.UpdateErrorStatus(err)
 	}
 }
 
-// GetID 实现 Component 接口
-func (d *PCMDumper) GetID() interface{} {
-	return d.seq
+// closeFile 关闭文件，只执行一次
+func (d *PCMDumper) closeFile() {
+	d.closeOnce.Do(func() {
+		d.file.Close()
+	})
 }
 
 // Stop 实现 Component 接口，扩展基础组件的 Stop 方法
 func (d *PCMDumper) Stop() {
-	d.BaseComponent.Stop()
-	d.file.Close()
-}
-
-// 为了向后兼容，保留这些方法
-func (d *PCMDumper) Process(packet pipeline.Packet) {
-	select {
-	case d.GetInputChan() <- packet:
-	default:
-		logger.Error("PCMDumper: input channel full, dropping packet")
-	}
-}
-
-func (d *PCMDumper) SetOutput(output func(pipeline.Packet)) {
-	// 创建一个适配器函数，将 channel 输出转换为函数调用
-	d.SetOutputChan(make(chan pipeline.Packet, 100))
-	go func() {
-		for packet := range d.GetOutputChan() {
-			if output != nil {
-				output(packet)
-			}
-		}
-	}()
-}
-
-// Start 实现 Component 接口
-func (d *PCMDumper) Start() error {
-	d.BaseComponent.Start()
-	return nil
-}
-
-// GetHealth 实现 Component 接口
-func (d *PCMDumper) GetHealth() pipeline.ComponentHealth {
-	return d.BaseComponent.GetHealth()
-}
-
-// UpdateHealth 实现 Component 接口
-func (d *PCMDumper) UpdateHealth(health pipeline.ComponentHealth) {
-	d.BaseComponent.UpdateHealth(health)
+	d.Stage.Stop()
+	d.closeFile()
 }
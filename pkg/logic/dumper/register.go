@@ -0,0 +1,30 @@
+package dumper
+
+import (
+	"fmt"
+	"streamlink/pkg/logic/pipeline"
+)
+
+func init() {
+	pipeline.Register("wav_dumper", func(params pipeline.Params) (pipeline.Component, error) {
+		file := params.String("file", "")
+		if file == "" {
+			return nil, fmt.Errorf("wav_dumper: file is required")
+		}
+		return NewWAVDumper(file, uint32(params.Int("sample_rate", 16000)), uint16(params.Int("channels", 1)))
+	})
+	pipeline.Register("ogg_dumper", func(params pipeline.Params) (pipeline.Component, error) {
+		file := params.String("file", "")
+		if file == "" {
+			return nil, fmt.Errorf("ogg_dumper: file is required")
+		}
+		return NewOggDumper(uint32(params.Int("sample_rate", 48000)), uint16(params.Int("channels", 2)), file)
+	})
+	pipeline.Register("pcm_dumper", func(params pipeline.Params) (pipeline.Component, error) {
+		file := params.String("file", "")
+		if file == "" {
+			return nil, fmt.Errorf("pcm_dumper: file is required")
+		}
+		return NewPCMDumper(file)
+	})
+}
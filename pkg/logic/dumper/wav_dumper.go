@@ -7,17 +7,19 @@ import (
 	"streamlink/internal/protocol/wav"
 	"streamlink/pkg/logger"
 	"streamlink/pkg/logic/pipeline"
+	"sync"
 )
 
 // WAVDumper 结构体 (实现 Component 接口)
 type WAVDumper struct {
 	*pipeline.BaseComponent
-	file     *os.File
-	fileName string
-	writer   *wav.Writer
-	format   wav.WAVFormat
-	seq      int
-	dataSize uint32
+	file      *os.File
+	fileName  string
+	writer    *wav.Writer
+	format    wav.WAVFormat
+	seq       int
+	dataSize  uint32
+	closeOnce sync.Once
 }
 
 // NewWAVDumper 创建新的 WAV 转储器
@@ -61,9 +63,14 @@ func NewWAVDumper(fileName string, sampleRate uint32, channels uint16) (*WAVDump
 		dataSize:      0,
 	}
 
+	dumper.SetInputFormat(pipeline.NewPCMFormat(int(sampleRate), int(channels)))
+
 	// 设置处理函数
 	dumper.BaseComponent.SetProcess(dumper.processPacket)
 	dumper.RegisterCommandHandler(pipeline.PacketCommandInterrupt, dumper.handleInterrupt)
+	// 冲刷时更新文件头，流结束时写入最终文件头并关闭文件
+	dumper.SetFlushHandler(dumper.flush)
+	dumper.SetDrainHandler(dumper.closeFile)
 
 	return dumper, nil
 }
@@ -82,77 +89,47 @@ func (d *WAVDumper) processPacket(packet pipeline.Packet) {
 		return
 	}
 
-	if data, ok := packet.Data.([]int16); ok {
-		// 写入 WAV 数据
-		if err := d.writer.WriteSamples(data); err != nil {
-			logger.Error("**%s** Failed to write WAV data: %v", d.GetName(), err)
-			d.UpdateErrorStatus(err)
-		}
-
-		// 更新数据大小
-		d.dataSize += uint32(len(data) * 2) // 每个采样点 2 字节
+	frame, ok := d.ExpectAudioFrame(packet)
+	if !ok {
+		return
+	}
 
-		// 转发数据包
-		d.ForwardPacket(packet)
-		d.seq++
-	} else {
-		d.HandleUnsupportedData(packet.Data)
+	// 写入 WAV 数据
+	if err := d.writer.WriteSamples(frame.Samples); err != nil {
+		logger.Error("**%s** Failed to write WAV data: %v", d.GetName(), err)
+		d.UpdateErrorStatus(err)
 	}
-}
 
-// GetID 实现 Component 接口
-func (d *WAVDumper) GetID() interface{} {
-	return d.seq
-}
+	// 更新数据大小
+	d.dataSize += uint32(len(frame.Samples) * 2) // 每个采样点 2 字节
 
-// Stop 实现 Component 接口，扩展基础组件的 Stop 方法
-func (d *WAVDumper) Stop() {
-	d.BaseComponent.Stop()
-	if d.writer != nil {
-		d.writer.Close()
-		d.writer = nil
-	}
-	if d.file != nil {
-		d.file.Close()
-		d.file = nil
-	}
+	// 转发数据包
+	d.ForwardPacket(packet)
+	d.seq++
 }
 
-// 为了向后兼容，保留这些方法
-func (d *WAVDumper) Process(packet pipeline.Packet) {
-	select {
-	case d.GetInputChan() <- packet:
-	default:
-		logger.Error("**%s** Input channel full, dropping packet", d.GetName())
+// flush 按已写入的数据更新文件头，文件在此之后也是完整的 WAV
+func (d *WAVDumper) flush() {
+	if err := d.writer.Flush(); err != nil {
+		logger.Error("**%s** Failed to flush WAV header: %v", d.GetName(), err)
+		d.UpdateErrorStatus(err)
 	}
 }
 
-func (d *WAVDumper) SetOutput(output func(pipeline.Packet)) {
-	outChan := make(chan pipeline.Packet, 100)
-	d.SetOutputChan(outChan)
-	go func() {
-		for packet := range d.GetOutputChan() {
-			if output != nil {
-				output(packet)
-			}
+// closeFile 写入最终文件头并关闭文件，只执行一次
+func (d *WAVDumper) closeFile() {
+	d.closeOnce.Do(func() {
+		if err := d.writer.Close(); err != nil {
+			logger.Error("**%s** Failed to close WAV file: %v", d.GetName(), err)
+			d.UpdateErrorStatus(err)
 		}
-	}()
-}
-
-// Start 实现 Component 接口
-func (d *WAVDumper) Start() error {
-	d.BaseComponent.Start()
-	return nil
+	})
 }
 
-// GetHealth 实现 Component 接口
-func (d *WAVDumper) GetHealth() pipeline.ComponentHealth {
-	return d.BaseComponent.GetHealth()
-}
-
-// UpdateHealth 实现 Component 接口
-func (d *WAVDumper) UpdateHealth(health pipeline.ComponentHealth) {
-	d.BaseComponent.UpdateHealth(health)
+// Stop 实现 Component 接口，扩展基础组件的 Stop 方法
+func (d *WAVDumper) Stop() {
+	d.BaseComponent.Stop()
+	d.closeFile()
 }
 
 // GetDataSize 获取已写入的数据大小
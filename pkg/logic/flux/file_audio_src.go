@@ -6,7 +6,6 @@ import (
 	"os"
 	"streamlink/internal/protocol/wav"
 	"streamlink/pkg/logger"
-	"streamlink/pkg/logic/codec"
 	"streamlink/pkg/logic/pipeline"
 	"time"
 )
@@ -19,9 +18,9 @@ type FileAudioSource struct {
 	reader     *wav.Reader
 	file       *os.File
 	seq        int
-	stopCh     chan struct{}
 	frameSize  int // 每帧的采样点数
 	isRunning  bool
+	mediaTs    time.Duration // 下一帧的媒体时间戳
 }
 
 // NewFileAudioSource 创建新的文件音频源
@@ -31,7 +30,6 @@ func NewFileAudioSource(filePath string, sampleRate int) Source {
 		filePath:      filePath,
 		sampleRate:    sampleRate,
 		seq:           0,
-		stopCh:        make(chan struct{}),
 		frameSize:     960, // 20ms at 48kHz
 		isRunning:     false,
 	}
@@ -66,6 +64,7 @@ func (s *FileAudioSource) Start() error {
 	if int(format.SampleRate) != s.sampleRate {
 		return fmt.Errorf("unexpected sample rate: %d (expected %d)", format.SampleRate, s.sampleRate)
 	}
+	s.SetOutputFormat(pipeline.NewPCMFormat(s.sampleRate, int(format.NumChannels)))
 
 	s.isRunning = true
 	go s.readLoop()
@@ -87,11 +86,12 @@ func (s *FileAudioSource) readLoop() {
 	}()
 
 	// 分配缓冲区
-	pcmBuf := make([]int16, s.frameSize*int(s.reader.GetFormat().NumChannels))
+	channels := int(s.reader.GetFormat().NumChannels)
+	pcmBuf := make([]int16, s.frameSize*channels)
 
 	for {
 		select {
-		case <-s.stopCh:
+		case <-s.GetStopCh():
 			return
 		default:
 			// 读取 PCM 数据
@@ -101,6 +101,12 @@ func (s *FileAudioSource) readLoop() {
 				s.UpdateErrorStatus(err)
 				return
 			}
+			if n == 0 && err == io.EOF {
+				// 文件读完，通知下游冲刷缓存并结束
+				logger.Info("**%s** Reached end of file, sending end of stream", s.GetName())
+				s.ForwardPacket(pipeline.Packet{Command: pipeline.PacketCommandEndOfStream, TurnSeq: s.GetCurTurnSeq()})
+				return
+			}
 
 			// 如果读取的数据不足一帧，用静音填充
 			if n < len(pcmBuf) {
@@ -109,15 +115,12 @@ func (s *FileAudioSource) readLoop() {
 				}
 			}
 
-			// change pcmBuf to byte slice
-			byteBuf := make([]byte, len(pcmBuf)*2)
-			for i, v := range pcmBuf {
-				byteBuf[i*2] = byte(v)
-				byteBuf[i*2+1] = byte(v >> 8)
-			}
-
-			// 发送数据包
-			s.SendPacket(codec.NewRTPAudioPacket(byteBuf, uint32(s.seq)), s)
+			// 发送数据包，下游可能持有数据，因此每帧拷贝到池化的缓冲区
+			buf := pipeline.NewSampleBuffer(len(pcmBuf))
+			copy(buf.Samples, pcmBuf)
+			frame := pipeline.NewPooledPCMFrame(buf, s.sampleRate, channels, s.mediaTs)
+			s.mediaTs += frame.Duration
+			s.SendPacket(frame, s)
 
 			// 控制发送速度，模拟实时音频流
 			time.Sleep(20 * time.Millisecond)
@@ -125,44 +128,7 @@ func (s *FileAudioSource) readLoop() {
 	}
 }
 
-// Stop 停止音频源
-func (s *FileAudioSource) Stop() {
-	if !s.isRunning {
-		return
-	}
-	close(s.stopCh)
-	s.BaseComponent.Stop()
-}
-
-// GetID 实现 Component 接口
-func (s *FileAudioSource) GetID() interface{} {
-	return s.GetSeq()
-}
-
 // Process 实现 Component 接口
 func (s *FileAudioSource) Process(packet pipeline.Packet) {
 	// 音频源不处理输入
 }
-
-// SetOutput 实现 Component 接口
-func (s *FileAudioSource) SetOutput(output func(pipeline.Packet)) {
-	outChan := make(chan pipeline.Packet, 100)
-	s.SetOutputChan(outChan)
-	go func() {
-		for packet := range s.GetOutputChan() {
-			if output != nil {
-				output(packet)
-			}
-		}
-	}()
-}
-
-// GetHealth 实现 Component 接口
-func (s *FileAudioSource) GetHealth() pipeline.ComponentHealth {
-	return s.BaseComponent.GetHealth()
-}
-
-// UpdateHealth 实现 Component 接口
-func (s *FileAudioSource) UpdateHealth(health pipeline.ComponentHealth) {
-	s.BaseComponent.UpdateHealth(health)
-}
@@ -3,20 +3,43 @@ package flux
 import (
 	"fmt"
 	"streamlink/pkg/logger"
-	"streamlink/pkg/logic/codec"
 	"streamlink/pkg/logic/pipeline"
+	"sync"
 	"time"
 
 	"github.com/pion/webrtc/v4"
 	"github.com/pion/webrtc/v4/pkg/media"
 )
 
+// playoutLead 音频帧相对播放时刻提前发送的时长，为接收端的抖动缓冲留出余量
+// 提前量越小，打断时用户已收到但来不及播放的音频越少
+const playoutLead = 60 * time.Millisecond
+
+// playoutQueueLimit 播放队列最多缓存的音频时长，超过时处理循环等待播放协程取走音频，
+// 后续音频留在输入 channel 中，由背压策略与健康检查处理
+const playoutQueueLimit = 500 * time.Millisecond
+
+// defaultFrameDuration 未声明时长的音频帧按 20ms 计
+const defaultFrameDuration = 20 * time.Millisecond
+
 // WebRTCSink 结构体 (实现 Component 接口)
+// 处理循环只将音频帧放入播放队列，由播放协程按媒体时间戳实时写入轨道，
+// 打断时丢弃队列中旧轮次的音频，并记录用户实际听到的位置
 type WebRTCSink struct {
 	*pipeline.BaseComponent
 	track       *webrtc.TrackLocalStaticSample
 	seq         int
-	lastTurnSeq int // 上一个处理的turn序列号
+	lastTurnSeq int             // 上一个处理的turn序列号
+	pacer       *pipeline.Pacer // 将音频帧按媒体时间戳实时输出
+
+	mu            sync.Mutex
+	queue         []pipeline.Packet         // 等待播放的音频
+	queued        time.Duration             // 队列中音频的总时长
+	minTurnSeq    int                       // 小于该轮次的音频不再播放
+	closing       bool                      // 输入已排空，队列播放完后播放协程退出
+	lastInterrupt pipeline.PlaybackPosition // 最近一次打断时的播放进度
+	wake          chan struct{}             // 队列变化或打断时唤醒播放协程
+	space         chan struct{}             // 播放协程取走音频后唤醒等待队列空间的处理循环
 }
 
 func NewWebRTCSink(track *webrtc.TrackLocalStaticSample) *WebRTCSink {
@@ -25,11 +48,17 @@ func NewWebRTCSink(track *webrtc.TrackLocalStaticSample) *WebRTCSink {
 		track:         track,
 		seq:           0,
 		lastTurnSeq:   -1, // 初始化为-1，确保第一个packet会打印日志
+		pacer:         pipeline.NewPacer(pipeline.RealClock, playoutLead),
+		wake:          make(chan struct{}, 1),
+		space:         make(chan struct{}, 1),
 	}
+	sink.SetInputFormat(pipeline.AudioFormat{Format: pipeline.SampleFormatOpus})
 
 	// 设置处理函数
 	sink.BaseComponent.SetProcess(sink.processPacket)
 	sink.RegisterCommandHandler(pipeline.PacketCommandInterrupt, sink.handleInterrupt)
+	// 输入排空后播放完队列中的音频再退出
+	sink.SetDrainHandler(sink.drain)
 
 	return sink
 }
@@ -40,105 +69,261 @@ func (s *WebRTCSink) handleInterrupt(packet pipeline.Packet) {
 
 	// 重置lastTurnSeq，确保下一个turn的第一个packet会打印日志
 	s.lastTurnSeq = -1
-	s.SetTurnStartTs(time.Now().UnixMilli())
+	s.SetTurnStartTs(s.Clock().Now().UnixMilli())
+
+	// 丢弃旧轮次未播放的音频，已发送的部分仍会在接收端播完
+	s.mu.Lock()
+	s.minTurnSeq = packet.TurnSeq
+	dropped := s.dropStaleLocked()
+	position := s.pacer.Position()
+	s.lastInterrupt = position
+	s.mu.Unlock()
+	s.notify()
+
+	if position.Playing || dropped > 0 {
+		s.Publish(pipeline.EventPlaybackInterrupted, position.TurnSeq, position)
+	}
+	logger.Info("[TurnSeq: %d] **%s** Interrupted turn %d after %v played (media ts %v, %v buffered), dropped %d queued frames",
+		packet.TurnSeq, s.GetName(), position.TurnSeq, position.TurnPlayed, position.MediaTs, position.Buffered, dropped)
 }
 
 // processPacket 处理输入的数据包
 func (s *WebRTCSink) processPacket(packet pipeline.Packet) {
 	// 检查是否是当前turn的第一个packet
 	if s.lastTurnSeq != packet.TurnSeq {
-		logger.Info("[TurnSeq: %d] **%s** Processing first packet, e2e latency=%dms", packet.TurnSeq, s.GetName(), time.Now().UnixMilli()-s.GetTurnStartTs())
+		logger.Info("[TurnSeq: %d] **%s** Processing first packet, since interrupt=%dms", packet.TurnSeq, s.GetName(), s.Clock().Now().UnixMilli()-s.GetTurnStartTs())
 		s.lastTurnSeq = packet.TurnSeq
 	}
 
-	switch data := packet.Data.(type) {
-	case codec.AudioPacket:
-		// 写入音频数据
-		if err := s.track.WriteSample(media.Sample{
-			Data:     data.Payload(),
-			Duration: time.Millisecond * 20,
-		}); err != nil {
-			logger.Error("**%s** Failed to write sample: %v", s.GetName(), err)
-			s.UpdateErrorStatus(err)
+	frame, ok := s.ExpectAudioFrame(packet)
+	if !ok {
+		return
+	}
+	if !s.waitForSpace() {
+		frame.Release()
+		return
+	}
+
+	s.mu.Lock()
+	s.queue = append(s.queue, packet)
+	s.queued += frameDuration(frame)
+	s.mu.Unlock()
+	s.notify()
+}
+
+// waitForSpace 等待播放队列低于 playoutQueueLimit，组件停止时返回 false
+// 播放协程每取走一帧就会唤醒等待，控制通道中的打断最多延后一帧的时长处理
+func (s *WebRTCSink) waitForSpace() bool {
+	for {
+		s.mu.Lock()
+		queued := s.queued
+		s.mu.Unlock()
+		if queued < playoutQueueLimit {
+			return true
+		}
+		select {
+		case <-s.space:
+		case <-s.GetStopCh():
+			return false
 		}
-	default:
-		s.HandleUnsupportedData(packet.Data)
 	}
 }
 
-// GetID 实现 Component 接口
-func (s *WebRTCSink) GetID() interface{} {
-	return s.GetSeq()
+// GetHealth 在输入 channel 之外计入播放队列中的帧，使健康检查能发现卡住的输出
+func (s *WebRTCSink) GetHealth() pipeline.ComponentHealth {
+	health := s.BaseComponent.GetHealth()
+	s.mu.Lock()
+	health.InputQueueSize += len(s.queue)
+	s.mu.Unlock()
+	return health
 }
 
-// Stop 实现 Component 接口
-func (s *WebRTCSink) Stop() {
-	s.BaseComponent.Stop()
+func frameDuration(frame pipeline.AudioFrame) time.Duration {
+	if frame.Duration == 0 {
+		return defaultFrameDuration
+	}
+	return frame.Duration
 }
 
-// Start 实现 Component 接口
-func (s *WebRTCSink) Start() error {
-	if s.track == nil {
-		return fmt.Errorf("track not set")
+// drain 在输入排空后通知播放协程，队列播放完后退出
+func (s *WebRTCSink) drain() {
+	s.mu.Lock()
+	s.closing = true
+	s.mu.Unlock()
+	s.notify()
+}
+
+func (s *WebRTCSink) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
 	}
+}
 
-	// 更新组件状态
-	s.UpdateHealth(pipeline.ComponentHealth{
-		State:          pipeline.ComponentStateRunning,
-		LastUpdateTime: time.Now(),
-	})
+func (s *WebRTCSink) signalSpace() {
+	select {
+	case s.space <- struct{}{}:
+	default:
+	}
+}
+
+// dropStaleLocked 丢弃队列中旧轮次的音频，返回丢弃的数量
+func (s *WebRTCSink) dropStaleLocked() int {
+	kept := s.queue[:0]
+	for _, packet := range s.queue {
+		if packet.TurnSeq >= s.minTurnSeq {
+			kept = append(kept, packet)
+		} else {
+			s.queued -= frameDuration(packet.Data.(pipeline.AudioFrame))
+			pipeline.ReleasePacket(packet)
+		}
+	}
+	dropped := len(s.queue) - len(kept)
+	s.queue = kept
+	if dropped > 0 {
+		s.signalSpace()
+	}
+	return dropped
+}
 
-	return s.BaseComponent.Start()
+// stale 判断轮次的音频是否已被打断
+func (s *WebRTCSink) stale(turnSeq int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return turnSeq < s.minTurnSeq
 }
 
-// GetInputChan implements pipeline.Component interface
-func (s *WebRTCSink) GetInputChan() chan pipeline.Packet {
-	return s.BaseComponent.GetInputChan()
+// playout 播放协程，按媒体时间戳依次将队列中的音频写入轨道
+func (s *WebRTCSink) playout() {
+	for {
+		packet, ok := s.next()
+		if !ok {
+			return
+		}
+		frame := packet.Data.(pipeline.AudioFrame)
+		if !s.waitUntil(s.pacer.Schedule(frame.Timestamp), packet.TurnSeq) {
+			return
+		}
+		if s.stale(packet.TurnSeq) {
+			frame.Release()
+			continue
+		}
+		s.writeFrame(packet, frame)
+	}
 }
 
-// GetOutputChan implements pipeline.Component interface
-func (s *WebRTCSink) GetOutputChan() chan pipeline.Packet {
-	return s.BaseComponent.GetOutputChan()
+// next 取出队列中的下一帧，队列为空时等待，组件停止或排空完成时返回 false
+func (s *WebRTCSink) next() (pipeline.Packet, bool) {
+	for {
+		s.mu.Lock()
+		if len(s.queue) > 0 {
+			packet := s.queue[0]
+			s.queue = s.queue[1:]
+			s.queued -= frameDuration(packet.Data.(pipeline.AudioFrame))
+			s.mu.Unlock()
+			s.signalSpace()
+			return packet, true
+		}
+		closing := s.closing
+		s.mu.Unlock()
+		if closing {
+			return pipeline.Packet{}, false
+		}
+
+		select {
+		case <-s.wake:
+		case <-s.GetStopCh():
+			return pipeline.Packet{}, false
+		}
+	}
 }
 
-// SetInputChan implements pipeline.Component interface
-func (s *WebRTCSink) SetInputChan(ch chan pipeline.Packet) {
-	s.BaseComponent.SetInputChan(ch)
+// waitUntil 等待到 due 时刻，期间该轮次被打断时提前返回，组件停止时返回 false
+func (s *WebRTCSink) waitUntil(due time.Time, turnSeq int) bool {
+	clock := s.Clock()
+	for {
+		d := due.Sub(clock.Now())
+		if d <= 0 || s.stale(turnSeq) {
+			return true
+		}
+		timer := clock.NewTimer(d)
+		select {
+		case <-timer.C():
+			return true
+		case <-s.wake:
+			timer.Stop()
+		case <-s.GetStopCh():
+			timer.Stop()
+			return false
+		}
+	}
 }
 
-// SetOutputChan implements pipeline.Component interface
-func (s *WebRTCSink) SetOutputChan(ch chan pipeline.Packet) {
-	s.BaseComponent.SetOutputChan(ch)
+// writeFrame 将音频帧写入 WebRTC 轨道
+// 写入是同步的，返回后即可归还编码数据的缓冲区
+func (s *WebRTCSink) writeFrame(packet pipeline.Packet, frame pipeline.AudioFrame) {
+	defer frame.Release()
+
+	duration := frameDuration(frame)
+
+	// 写入音频数据
+	if err := s.track.WriteSample(media.Sample{
+		Data:     frame.Payload,
+		Duration: duration,
+	}); err != nil {
+		logger.Error("**%s** Failed to write sample: %v", s.GetName(), err)
+		s.UpdateErrorStatus(err)
+		return
+	}
+	s.pacer.Sent(packet.TurnSeq, frame.Timestamp, duration)
+
+	// 回复的首个音频包已发出，轮次追踪到此结束
+	if packet.Trace.Mark(pipeline.SpanFirstRTPOut, s.GetName()) {
+		s.observeEndToEnd(packet.Trace)
+		packet.Trace.Complete()
+	}
 }
 
-// GetHealth implements pipeline.Component interface
-func (s *WebRTCSink) GetHealth() pipeline.ComponentHealth {
-	return s.BaseComponent.GetHealth()
+// PlaybackPosition 返回当前的播放进度
+func (s *WebRTCSink) PlaybackPosition() pipeline.PlaybackPosition {
+	return s.pacer.Position()
 }
 
-// UpdateHealth implements pipeline.Component interface
-func (s *WebRTCSink) UpdateHealth(health pipeline.ComponentHealth) {
-	s.BaseComponent.UpdateHealth(health)
+// LastInterruptPosition 返回最近一次打断时的播放进度，即用户实际听到的位置
+func (s *WebRTCSink) LastInterruptPosition() pipeline.PlaybackPosition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastInterrupt
 }
 
-// Process 实现 audio.Sink 接口
-func (s *WebRTCSink) Process(packet pipeline.Packet) {
-	select {
-	case s.GetInputChan() <- packet:
-	default:
-		logger.Error("**%s** Input channel full, dropping packet", s.GetName())
+// observeEndToEnd 记录用户说完话到首个回复音频包发出的延迟，缺少说话结束打点时以最终识别结果为起点
+func (s *WebRTCSink) observeEndToEnd(trace *pipeline.TurnTrace) {
+	latency, ok := trace.Between(pipeline.SpanSpeechEnd, pipeline.SpanFirstRTPOut)
+	if !ok {
+		latency, ok = trace.Between(pipeline.SpanASRFinal, pipeline.SpanFirstRTPOut)
+	}
+	if ok {
+		s.ObserveLatency(pipeline.LatencyEndToEnd, latency)
 	}
 }
 
-// SetOutput 实现 audio.Sink 接口
-func (s *WebRTCSink) SetOutput(output func(pipeline.Packet)) {
-	outChan := make(chan pipeline.Packet, 100)
-	s.SetOutputChan(outChan)
-	go func() {
-		for packet := range outChan {
-			if output != nil {
-				output(packet)
-			}
-		}
-	}()
+// Start 实现 Component 接口
+func (s *WebRTCSink) Start() error {
+	if s.track == nil {
+		return fmt.Errorf("track not set")
+	}
+
+	// 更新组件状态
+	s.UpdateHealth(pipeline.ComponentHealth{
+		State:          pipeline.ComponentStateRunning,
+		LastUpdateTime: s.Clock().Now(),
+	})
+
+	// 按注入的时间来源重新创建 Pacer
+	s.pacer = pipeline.NewPacer(s.Clock(), playoutLead)
+	if err := s.BaseComponent.Start(); err != nil {
+		return err
+	}
+	s.Go(s.playout)
+	return nil
 }
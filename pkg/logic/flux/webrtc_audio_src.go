@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"streamlink/pkg/logger"
-	"streamlink/pkg/logic/codec"
 	"streamlink/pkg/logic/pipeline"
 	"time"
 
@@ -16,8 +15,14 @@ type WebRTCSource struct {
 	*pipeline.BaseComponent
 	track *webrtc.TrackRemote
 	seq   int
+
+	firstRTPTs uint32 // 首个 RTP 包的时间戳，用于换算媒体时间戳
+	hasFirstTs bool
 }
 
+// webrtcOpusFormat WebRTC Opus 音轨的固定格式（RTP 时钟 48kHz，双声道）
+var webrtcOpusFormat = pipeline.NewOpusFormat(48000, 2)
+
 // NewWebRTCSource 创建一个新的 WebRTC 音频源
 func NewWebRTCSource(track *webrtc.TrackRemote) *WebRTCSource {
 	s := &WebRTCSource{
@@ -25,6 +30,8 @@ func NewWebRTCSource(track *webrtc.TrackRemote) *WebRTCSource {
 		track:         track,
 		seq:           0,
 	}
+	s.SetOutputFormat(webrtcOpusFormat)
+	s.SetBackpressure(pipeline.DropOldestPolicy())
 	return s
 }
 
@@ -75,11 +82,16 @@ func (s *WebRTCSource) readLoop() {
 				continue
 			}
 
-			// 将 RTP 包转换为 AudioPacket
-			audioPacket := codec.NewRTPAudioPacket(rtpPacket.Payload, rtpPacket.Timestamp)
+			// 将 RTP 包转换为音频帧
+			if !s.hasFirstTs {
+				s.firstRTPTs = rtpPacket.Timestamp
+				s.hasFirstTs = true
+			}
+			ts := time.Duration(rtpPacket.Timestamp-s.firstRTPTs) * time.Second / time.Duration(webrtcOpusFormat.SampleRate)
+			frame := pipeline.NewEncodedFrame(rtpPacket.Payload, webrtcOpusFormat, 20*time.Millisecond, ts)
 
 			// 发送数据包
-			s.SendPacket(audioPacket, s)
+			s.SendPacket(frame, s)
 
 			// 更新健康状态
 			health := s.GetHealth()
@@ -90,61 +102,7 @@ func (s *WebRTCSource) readLoop() {
 	}
 }
 
-// Stop 实现 Component 接口
-func (s *WebRTCSource) Stop() {
-	s.BaseComponent.Stop()
-}
-
-// GetID 实现 Component 接口
-func (s *WebRTCSource) GetID() interface{} {
-	return s.GetName()
-}
-
 // Process 实现 Component 接口
 func (s *WebRTCSource) Process(packet pipeline.Packet) {
 	// WebRTCSource 不需要处理输入包
 }
-
-// SetOutput 实现 Component 接口
-func (s *WebRTCSource) SetOutput(output func(pipeline.Packet)) {
-	// WebRTCSource 直接使用 BaseComponent 的输出通道
-	outChan := make(chan pipeline.Packet, 100)
-	s.SetOutputChan(outChan)
-	go func() {
-		for packet := range outChan {
-			if output != nil {
-				output(packet)
-			}
-		}
-	}()
-}
-
-// GetInputChan implements pipeline.Component interface
-func (s *WebRTCSource) GetInputChan() chan pipeline.Packet {
-	return s.BaseComponent.GetInputChan()
-}
-
-// GetOutputChan implements pipeline.Component interface
-func (s *WebRTCSource) GetOutputChan() chan pipeline.Packet {
-	return s.BaseComponent.GetOutputChan()
-}
-
-// SetInputChan implements pipeline.Component interface
-func (s *WebRTCSource) SetInputChan(ch chan pipeline.Packet) {
-	s.BaseComponent.SetInputChan(ch)
-}
-
-// SetOutputChan implements pipeline.Component interface
-func (s *WebRTCSource) SetOutputChan(ch chan pipeline.Packet) {
-	s.BaseComponent.SetOutputChan(ch)
-}
-
-// GetHealth implements pipeline.Component interface
-func (s *WebRTCSource) GetHealth() pipeline.ComponentHealth {
-	return s.BaseComponent.GetHealth()
-}
-
-// UpdateHealth implements pipeline.Component interface
-func (s *WebRTCSource) UpdateHealth(health pipeline.ComponentHealth) {
-	s.BaseComponent.UpdateHealth(health)
-}
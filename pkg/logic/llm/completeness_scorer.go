@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+const completenessScorerPrompt = `你是语音对话中的断句判断器。用户正在说话，下面是目前识别出的文本，用户此刻停顿了。
+判断用户是否已经说完这句话，只回答 0 到 1 之间的一个数字：
+接近 0：明显没说完，如停在连词、介词或半个句子上（“我想订一张去”“I want to book a flight to”）
+接近 1：已经说完，如完整的问题、请求或简短的回答（“好的”“不用了”“What time is it?”）`
+
+// LLMCompletenessScorer 使用 LLM 评估用户的话是否已经说完
+type LLMCompletenessScorer struct {
+	client ChatClient
+	model  string
+}
+
+// NewLLMCompletenessScorer 创建基于 LLM 的完整度评估器
+func NewLLMCompletenessScorer(apiKey string, baseURL string, model string) *LLMCompletenessScorer {
+	client := openai.NewClient(
+		option.WithAPIKey(apiKey),
+		option.WithBaseURL(baseURL),
+	)
+	return &LLMCompletenessScorer{
+		client: client.Chat.Completions,
+		model:  model,
+	}
+}
+
+// Score 实现 pipeline.CompletenessScorer，LLM 的回复不是数字时返回错误，由 TurnManager 使用默认的静音超时
+func (s *LLMCompletenessScorer) Score(ctx context.Context, text string) (float64, error) {
+	resp, err := s.client.New(ctx, openai.ChatCompletionNewParams{
+		Messages: openai.F([]openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(completenessScorerPrompt),
+			openai.UserMessage(text),
+		}),
+		Model:       openai.F(s.model),
+		Temperature: openai.F(0.0),
+		MaxTokens:   openai.F(int64(5)),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(resp.Choices) == 0 {
+		return 0, fmt.Errorf("empty response from %s", s.model)
+	}
+
+	reply := strings.TrimSpace(resp.Choices[0].Message.Content)
+	score, err := strconv.ParseFloat(reply, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected completeness score %q from %s", reply, s.model)
+	}
+	return score, nil
+}
@@ -2,7 +2,6 @@ package llm
 
 import (
 	"context"
-	"fmt"
 	"streamlink/pkg/logger"
 	"streamlink/pkg/logic/pipeline"
 	"sync"
@@ -27,11 +26,12 @@ type DeepSeek struct {
 	model       string
 	maxMessages int
 	streaming   bool
+	speculative bool         // 根据推测的用户输入提前生成回复
+	spec        *speculation // 进行中的推测，只在处理循环中访问
 	mu          sync.Mutex
 	metrics     pipeline.TurnMetrics
 	// 自定义指标
-	firstTokenLatencyMs int64 // 首token延迟(毫秒)
-	totalLatencyMs      int64 // 总延迟(毫秒)
+	totalLatencyMs int64 // 总延迟(毫秒)
 }
 
 // NewDeepSeek 创建一个新的 DeepSeek 实例
@@ -52,6 +52,8 @@ func NewDeepSeek(apiKey string, baseURL string) *DeepSeek {
 
 	// 设置处理函数
 	d.BaseComponent.SetProcess(d.processPacket)
+	// LLM 输出的文本不允许丢弃，缓冲满时阻塞直到下游消费
+	d.SetBackpressure(pipeline.BlockPolicy(0))
 	d.RegisterCommandHandler(pipeline.PacketCommandInterrupt, d.handleInterrupt)
 
 	return d
@@ -60,6 +62,10 @@ func NewDeepSeek(apiKey string, baseURL string) *DeepSeek {
 func (d *DeepSeek) handleInterrupt(packet pipeline.Packet) {
 	logger.Info("**%s** Received interrupt command for turn %d", d.GetName(), packet.TurnSeq)
 	d.SetCurTurnSeq(packet.TurnSeq)
+	// 被打断轮次的推测不再使用，提交推测目标轮次的语义打断除外
+	if !d.keepsSpeculation(packet) {
+		d.cancelSpeculation()
+	}
 
 	d.ForwardPacket(packet)
 }
@@ -75,7 +81,7 @@ func (d *DeepSeek) ProcessText(text string) string {
 
 	// 创建聊天完成请求
 	resp, err := d.client.New(
-		context.Background(),
+		d.Context(),
 		openai.ChatCompletionNewParams{
 			Messages: openai.F(d.messages),
 			Model:    openai.F(d.model),
@@ -105,19 +111,24 @@ func (d *DeepSeek) processPacket(packet pipeline.Packet) {
 	switch data := packet.Data.(type) {
 	case string:
 		d.mu.Lock()
-		d.metrics.TurnStartTs = time.Now().UnixMilli()
+		d.metrics.TurnStartTs = d.Clock().Now().UnixMilli()
 		d.metrics.TurnEndTs = 0
-		d.firstTokenLatencyMs = 0
 		d.totalLatencyMs = 0
 		d.mu.Unlock()
 
 		logger.Info("**%s** Process turn_seq=%d, cur_turn_seq=%d, text: %s", d.GetName(), packet.TurnSeq, d.GetCurTurnSeq(), data)
 
+		// 与推测一致时直接使用推测的回复
+		if d.commitSpeculation(data, packet) {
+			return
+		}
 		if d.streaming {
-			go d.processTextStreaming(data, packet)
+			d.processTextStreaming(data, packet)
 		} else {
 			d.processTextNonStreaming(data, packet)
 		}
+	case pipeline.SpeculativeTranscript:
+		d.speculate(data.Text, packet)
 	default:
 		d.HandleUnsupportedData(packet.Data)
 	}
@@ -138,92 +149,58 @@ func (d *DeepSeek) processTextStreaming(text string, packet pipeline.Packet) {
 	d.mu.Unlock()
 
 	// 记录开始时间
-	startTime := time.Now()
+	clock := d.Clock()
+	startTime := clock.Now()
 	var firstTokenTime time.Time
 
 	// 在单独的goroutine中处理流式响应，避免阻塞processLoop
-	go func() {
+	// 组件排空时会等待当前回复输出完毕，停止时取消请求
+	d.Go(func() {
 		// 创建上下文，使其可以被取消
-		ctx, cancel := context.WithCancel(context.Background())
+		ctx, cancel := context.WithCancel(d.Context())
 		defer cancel()
 
-		// 创建流式聊天完成请求
-		stream := d.client.NewStreaming(
-			ctx,
-			openai.ChatCompletionNewParams{
-				Messages: openai.F(messagesCopy),
-				Model:    openai.F(modelCopy),
-			},
-		)
-		defer stream.Close()
-
-		// 使用累加器收集完整响应
-		acc := openai.ChatCompletionAccumulator{}
-		var fullResponse string
-		var chunkCount int
-		isFirstToken := true
-		padding := ""
-
-		// 处理流式响应
-		for stream.Next() {
+		var firstTokenLatency time.Duration
+		stopped := false
+		onFirstToken := func() {
 			// 记录首个token的时间
-			if isFirstToken {
-				firstTokenTime = time.Now()
-				firstTokenLatency := firstTokenTime.Sub(startTime)
-				d.mu.Lock()
-				d.firstTokenLatencyMs = firstTokenLatency.Milliseconds()
-				d.mu.Unlock()
-				logger.Info("[TurnSeq: %d] **%s** First token latency: %v", packet.TurnSeq, d.GetName(), firstTokenLatency)
-				isFirstToken = false
-				padding = "。"
-			}
-
+			firstTokenTime = clock.Now()
+			firstTokenLatency = firstTokenTime.Sub(startTime)
+			packet.Trace.MarkAt(pipeline.SpanLLMFirstToken, d.GetName(), firstTokenTime)
+			d.ObserveLatency(pipeline.LatencyLLMFirstToken, firstTokenLatency)
+			logger.Info("[TurnSeq: %d] **%s** First token latency: %v", packet.TurnSeq, d.GetName(), firstTokenLatency)
+		}
+		fullResponse, err := d.streamReply(ctx, messagesCopy, modelCopy, onFirstToken, func(content string) bool {
 			// 检查当前turn sequence是否已经改变，如果改变则停止处理
 			if packet.TurnSeq < d.GetCurTurnSeq() {
 				logger.Info("**%s** Turn sequence changed from %d to %d, stopping stream", d.GetName(), packet.TurnSeq, d.GetCurTurnSeq())
-				return
-			}
-
-			chunk := stream.Current()
-			acc.AddChunk(chunk)
-			chunkCount++
-
-			// 发送内容更新
-			if content, ok := acc.JustFinishedContent(); ok {
-				logger.Debug("**%s** Streaming content: %s", d.GetName(), content)
-				d.ForwardPacket(pipeline.Packet{
-					Data:    content,
-					Seq:     d.GetSeq(),
-					TurnSeq: packet.TurnSeq,
-				})
-				fullResponse += content
-			}
-
-			// 如果当前块有内容，也发送
-			if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
-				logger.Debug("**%s** Streaming content: %s", d.GetName(), chunk.Choices[0].Delta.Content+padding)
-				d.ForwardPacket(pipeline.Packet{
-					Data:    chunk.Choices[0].Delta.Content + padding,
-					Seq:     d.GetSeq(),
-					TurnSeq: packet.TurnSeq,
-				})
-				fullResponse += chunk.Choices[0].Delta.Content
-				padding = ""
+				stopped = true
+				return false
 			}
+			d.ForwardPacket(pipeline.Packet{
+				Data:    content,
+				Seq:     d.GetSeq(),
+				TurnSeq: packet.TurnSeq,
+				Trace:   packet.Trace,
+			})
+			return true
+		})
+		if stopped {
+			d.publishReply(packet.TurnSeq, fullResponse, true)
+			return
 		}
 
 		// 计算总耗时
-		totalDuration := time.Since(startTime)
+		totalDuration := clock.Since(startTime)
 		d.mu.Lock()
 		d.totalLatencyMs = totalDuration.Milliseconds()
-		d.metrics.TurnEndTs = time.Now().UnixMilli()
+		d.metrics.TurnEndTs = clock.Now().UnixMilli()
 		d.mu.Unlock()
 
 		logger.Info("[TurnSeq: %d] **%s** Total streaming duration: %v (first token: %v)",
-			packet.TurnSeq, d.GetName(), totalDuration,
-			time.Duration(d.firstTokenLatencyMs)*time.Millisecond)
+			packet.TurnSeq, d.GetName(), totalDuration, firstTokenLatency)
 
-		if err := stream.Err(); err != nil {
+		if err != nil {
 			logger.Error("Error in stream: %v", err)
 			d.UpdateErrorStatus(err)
 			return
@@ -237,11 +214,67 @@ func (d *DeepSeek) processTextStreaming(text string, packet pipeline.Packet) {
 		// 将完整的回复添加到消息历史
 		d.messages = append(d.messages, openai.AssistantMessage(fullResponse))
 		d.mu.Unlock()
-	}()
+		d.publishReply(packet.TurnSeq, fullResponse, false)
+	})
 
 	// 立即返回，不阻塞processLoop
 }
 
+// streamReply 发起流式请求，按到达顺序将回复片段交给 emit，emit 返回 false 时停止，返回已收到的完整回复
+// onFirstToken 在收到首个片段时调用，可为 nil
+func (d *DeepSeek) streamReply(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion, model string,
+	onFirstToken func(), emit func(content string) bool) (string, error) {
+	// 创建流式聊天完成请求
+	stream := d.client.NewStreaming(
+		ctx,
+		openai.ChatCompletionNewParams{
+			Messages: openai.F(messages),
+			Model:    openai.F(model),
+		},
+	)
+	defer stream.Close()
+
+	// 使用累加器收集完整响应
+	acc := openai.ChatCompletionAccumulator{}
+	var fullResponse string
+	isFirstToken := true
+	padding := ""
+
+	// 处理流式响应
+	for stream.Next() {
+		if isFirstToken {
+			if onFirstToken != nil {
+				onFirstToken()
+			}
+			isFirstToken = false
+			padding = "。"
+		}
+
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+
+		// 发送内容更新
+		if content, ok := acc.JustFinishedContent(); ok {
+			logger.Debug("**%s** Streaming content: %s", d.GetName(), content)
+			if !emit(content) {
+				return fullResponse, nil
+			}
+			fullResponse += content
+		}
+
+		// 如果当前块有内容，也发送
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			logger.Debug("**%s** Streaming content: %s", d.GetName(), chunk.Choices[0].Delta.Content+padding)
+			if !emit(chunk.Choices[0].Delta.Content + padding) {
+				return fullResponse, nil
+			}
+			fullResponse += chunk.Choices[0].Delta.Content
+			padding = ""
+		}
+	}
+	return fullResponse, stream.Err()
+}
+
 // processTextNonStreaming 处理非流式文本请求
 func (d *DeepSeek) processTextNonStreaming(text string, packet pipeline.Packet) {
 	d.mu.Lock()
@@ -255,7 +288,7 @@ func (d *DeepSeek) processTextNonStreaming(text string, packet pipeline.Packet)
 
 	// 创建聊天完成请求
 	resp, err := d.client.New(
-		context.Background(),
+		d.Context(),
 		openai.ChatCompletionNewParams{
 			Messages: openai.F(d.messages),
 			Model:    openai.F(d.model),
@@ -280,27 +313,24 @@ func (d *DeepSeek) processTextNonStreaming(text string, packet pipeline.Packet)
 	d.messages = append(d.messages, openai.AssistantMessage(assistantMessage))
 	d.mu.Unlock()
 
-	d.metrics.TurnEndTs = time.Now().UnixMilli()
+	d.metrics.TurnEndTs = d.Clock().Now().UnixMilli()
+	// 非流式请求一次返回完整回复，整体耗时即首 token 延迟
+	d.ObserveLatency(pipeline.LatencyLLMFirstToken, time.Duration(d.metrics.TurnEndTs-d.metrics.TurnStartTs)*time.Millisecond)
+	packet.Trace.Mark(pipeline.SpanLLMFirstToken, d.GetName())
 
 	// 发送回复
-	previousMetrics := packet.TurnMetricStat
-	if packet.TurnMetricStat != nil {
-		previousMetrics[fmt.Sprintf("%s_%d", d.GetName(), d.GetSeq())] = d.metrics
-		packet.TurnMetricKeys = append(packet.TurnMetricKeys, fmt.Sprintf("%s_%d", d.GetName(), d.GetSeq()))
-	}
-
 	d.ForwardPacket(pipeline.Packet{
-		Data:           assistantMessage,
-		Seq:            d.GetSeq(),
-		TurnSeq:        d.GetCurTurnSeq(),
-		TurnMetricStat: previousMetrics,
-		TurnMetricKeys: packet.TurnMetricKeys,
+		Data:    assistantMessage,
+		Seq:     d.GetSeq(),
+		TurnSeq: d.GetCurTurnSeq(),
+		Trace:   packet.Trace,
 	})
+	d.publishReply(packet.TurnSeq, assistantMessage, false)
 }
 
-// GetID 实现 Component 接口
-func (d *DeepSeek) GetID() interface{} {
-	return d.GetSeq()
+// publishReply 发布一轮回复的文本，供会话记录保存代理的发言
+func (d *DeepSeek) publishReply(turnSeq int, reply string, interrupted bool) {
+	d.Publish(pipeline.EventReply, turnSeq, pipeline.ReplyEvent{Text: reply, Interrupted: interrupted})
 }
 
 // Stop 实现 Component 接口，扩展基础组件的 Stop 方法
@@ -312,30 +342,12 @@ func (d *DeepSeek) Stop() {
 	d.mu.Unlock()
 }
 
-// 为了向后兼容，保留这些方法
-func (d *DeepSeek) Process(packet pipeline.Packet) {
-	select {
-	case d.GetInputChan() <- packet:
-	default:
-		logger.Error("DeepSeek: input channel full, dropping packet")
-	}
-}
-
+// SetInput 创建输入通道，用于单独使用组件
 func (d *DeepSeek) SetInput() {
 	inChan := make(chan pipeline.Packet, 100)
 	d.SetInputChan(inChan)
 }
 
-func (d *DeepSeek) SetOutput(output func(pipeline.Packet)) {
-	go func() {
-		for packet := range d.GetOutputChan() {
-			if output != nil {
-				output(packet)
-			}
-		}
-	}()
-}
-
 // ClearHistory 清除对话历史
 func (d *DeepSeek) ClearHistory() {
 	d.mu.Lock()
@@ -343,6 +355,22 @@ func (d *DeepSeek) ClearHistory() {
 	d.mu.Unlock()
 }
 
+// HandOver 热替换时将对话历史迁移到新的 LLM 组件，实现 pipeline.StateHandover
+func (d *DeepSeek) HandOver(next pipeline.Component) {
+	n, ok := next.(*DeepSeek)
+	if !ok {
+		return
+	}
+	d.mu.Lock()
+	messages := make([]openai.ChatCompletionMessageParamUnion, len(d.messages))
+	copy(messages, d.messages)
+	d.mu.Unlock()
+
+	n.mu.Lock()
+	n.messages = messages
+	n.mu.Unlock()
+}
+
 // SetMaxMessages 设置保留的最大消息数量
 func (d *DeepSeek) SetMaxMessages(max int) {
 	d.maxMessages = max
@@ -357,13 +385,3 @@ func (d *DeepSeek) SetModel(model string) {
 func (d *DeepSeek) SetStreaming(enabled bool) {
 	d.streaming = enabled
 }
-
-// GetHealth 实现 Component 接口
-func (d *DeepSeek) GetHealth() pipeline.ComponentHealth {
-	return d.BaseComponent.GetHealth()
-}
-
-// UpdateHealth 实现 Component 接口
-func (d *DeepSeek) UpdateHealth(health pipeline.ComponentHealth) {
-	d.BaseComponent.UpdateHealth(health)
-}
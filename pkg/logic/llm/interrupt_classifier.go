@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"streamlink/pkg/logger"
+	"streamlink/pkg/logic/pipeline"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+const interruptClassifierPrompt = `你是语音对话中的打断判断器。助手正在说话时，用户说了一句话。
+判断这句话属于哪一类，只回答一个英文单词：
+interrupt：用户想打断助手，如提问、纠正、提出新要求或要求停下
+backchannel：用户只是附和，如“嗯”“对”“好的”“okay”，希望助手继续说
+noise：无意义的识别结果、咳嗽、环境噪声或与对话无关的话`
+
+// LLMInterruptClassifier 使用 LLM 判断代理说话期间用户的话是否为真正的打断
+// 先用词表规则分类，只有规则判断为打断时才调用 LLM，附和与噪声不产生额外请求
+type LLMInterruptClassifier struct {
+	client ChatClient
+	model  string
+	rules  pipeline.InterruptClassifier
+}
+
+// NewLLMInterruptClassifier 创建基于 LLM 的打断分类器
+func NewLLMInterruptClassifier(apiKey string, baseURL string, model string) *LLMInterruptClassifier {
+	client := openai.NewClient(
+		option.WithAPIKey(apiKey),
+		option.WithBaseURL(baseURL),
+	)
+	return &LLMInterruptClassifier{
+		client: client.Chat.Completions,
+		model:  model,
+		rules:  pipeline.DefaultRuleClassifier(),
+	}
+}
+
+// Classify 实现 pipeline.InterruptClassifier，LLM 的回复无法识别时按打断处理
+func (c *LLMInterruptClassifier) Classify(ctx context.Context, text string) (pipeline.InterruptDecision, error) {
+	decision, err := c.rules.Classify(ctx, text)
+	if err != nil || decision != pipeline.InterruptDecisionInterrupt {
+		return decision, err
+	}
+
+	resp, err := c.client.New(ctx, openai.ChatCompletionNewParams{
+		Messages: openai.F([]openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(interruptClassifierPrompt),
+			openai.UserMessage(text),
+		}),
+		Model:       openai.F(c.model),
+		Temperature: openai.F(0.0),
+		MaxTokens:   openai.F(int64(5)),
+	})
+	if err != nil {
+		return pipeline.InterruptDecisionInterrupt, err
+	}
+	if len(resp.Choices) == 0 {
+		return pipeline.InterruptDecisionInterrupt, fmt.Errorf("empty response from %s", c.model)
+	}
+
+	reply := resp.Choices[0].Message.Content
+	decision, ok := pipeline.ParseInterruptDecision(reply)
+	if !ok {
+		logger.Warn("LLMInterruptClassifier: unexpected reply %q for %q, treating as interrupt", reply, text)
+	}
+	return decision, nil
+}
@@ -0,0 +1,48 @@
+package llm
+
+import (
+	"fmt"
+	"streamlink/pkg/logic/pipeline"
+	"time"
+)
+
+func init() {
+	pipeline.Register("openai", func(params pipeline.Params) (pipeline.Component, error) {
+		d := NewDeepSeek(params.String("api_key", ""), params.String("base_url", ""))
+		if model := params.String("model", ""); model != "" {
+			d.SetModel(model)
+		}
+		d.SetStreaming(params.Bool("streaming", false))
+		d.SetSpeculative(params.Bool("speculative", false))
+		if maxMessages := params.Int("max_messages", 0); maxMessages > 0 {
+			d.SetMaxMessages(maxMessages)
+		}
+		return d, nil
+	})
+
+	pipeline.Register("semantic_interrupt", func(params pipeline.Params) (pipeline.Component, error) {
+		var classifier pipeline.InterruptClassifier
+		switch kind := params.String("classifier", "rules"); kind {
+		case "rules":
+			classifier = pipeline.DefaultRuleClassifier()
+		case "openai":
+			classifier = NewLLMInterruptClassifier(
+				params.String("api_key", ""),
+				params.String("base_url", ""),
+				params.String("model", "Qwen/Qwen2.5-7B-Instruct"),
+			)
+		default:
+			return nil, fmt.Errorf("unknown interrupt classifier %q", kind)
+		}
+		return pipeline.NewSemanticInterrupt(classifier, params.Duration("hold_timeout", 3*time.Second)), nil
+	})
+
+	// TurnManager 语义断句的完整度评估，参数与 turn_manager 节点共用
+	pipeline.RegisterScorer("openai", func(params pipeline.Params) (pipeline.CompletenessScorer, error) {
+		model := params.String("scorer_model", "")
+		if model == "" {
+			model = "Qwen/Qwen2.5-7B-Instruct"
+		}
+		return NewLLMCompletenessScorer(params.String("api_key", ""), params.String("base_url", ""), model), nil
+	})
+}
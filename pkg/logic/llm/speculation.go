@@ -0,0 +1,230 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"streamlink/pkg/logger"
+	"streamlink/pkg/logic/pipeline"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/openai/openai-go"
+)
+
+// speculation 根据推测的用户输入提前发起的生成
+// 提交前回复片段只缓存在内存中；收到一致的最终文本后提交，先转发缓存的片段，之后的片段直接转发
+type speculation struct {
+	key     string // 归一化后的推测输入，与最终文本比较
+	turnSeq int    // 推测的回复所属的轮次，即推测提交后 TurnManager 开始的轮次
+	cancel  context.CancelFunc
+
+	mu       sync.Mutex
+	chunks   []string
+	forward  func(content string) bool // 提交后的转发函数，返回 false 时停止生成
+	onFinish func(reply string, err error)
+	done     bool
+	reply    string
+	err      error
+}
+
+// emit 缓存或转发一个回复片段，由生成协程调用
+func (s *speculation) emit(content string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.forward == nil {
+		s.chunks = append(s.chunks, content)
+		return true
+	}
+	return s.forward(content)
+}
+
+// finish 生成结束，已提交时调用 onFinish
+func (s *speculation) finish(reply string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done, s.reply, s.err = true, reply, err
+	if s.onFinish != nil {
+		s.onFinish(reply, err)
+	}
+}
+
+// failed 判断生成是否已因错误结束
+func (s *speculation) failed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done && s.err != nil
+}
+
+// commit 提交推测，按顺序转发已缓存的片段，生成已结束时立即调用 onFinish
+func (s *speculation) commit(forward func(content string) bool, onFinish func(reply string, err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, content := range s.chunks {
+		if !forward(content) {
+			s.cancel()
+			break
+		}
+	}
+	s.chunks = nil
+	s.forward = forward
+	s.onFinish = onFinish
+	if s.done {
+		onFinish(s.reply, s.err)
+	}
+}
+
+// speculationKey 比较推测输入与最终文本时忽略标点、空白与大小写，最终结果通常只多出标点
+func speculationKey(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) || unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, text)
+}
+
+// SetSpeculative 设置是否根据 TurnManager 推测的用户输入提前生成回复
+func (d *DeepSeek) SetSpeculative(enabled bool) {
+	d.speculative = enabled
+}
+
+// speculate 根据推测的用户输入提前生成回复，与进行中的推测一致时保留，不一致时取消并重新生成
+func (d *DeepSeek) speculate(text string, packet pipeline.Packet) {
+	if !d.speculative {
+		return
+	}
+	key := speculationKey(text)
+	turnSeq := packet.TurnSeq + 1
+	if d.spec != nil && d.spec.key == key && d.spec.turnSeq == turnSeq {
+		return
+	}
+	d.cancelSpeculation()
+
+	// 推测基于当前的对话历史，用户消息在提交时才加入历史
+	d.mu.Lock()
+	messages := make([]openai.ChatCompletionMessageParamUnion, len(d.messages), len(d.messages)+1)
+	copy(messages, d.messages)
+	model := d.model
+	d.mu.Unlock()
+	messages = append(messages, openai.UserMessage(text))
+
+	ctx, cancel := context.WithCancel(d.Context())
+	s := &speculation{key: key, turnSeq: turnSeq, cancel: cancel}
+	d.spec = s
+	logger.Info("**%s** Speculating on: %s", d.GetName(), text)
+
+	d.Go(func() {
+		defer cancel()
+		if d.streaming {
+			s.finish(d.streamReply(ctx, messages, model, nil, s.emit))
+			return
+		}
+		resp, err := d.client.New(ctx, openai.ChatCompletionNewParams{
+			Messages: openai.F(messages),
+			Model:    openai.F(model),
+		})
+		if err != nil {
+			s.finish("", err)
+			return
+		}
+		if len(resp.Choices) == 0 {
+			s.finish("", errors.New("empty chat completion"))
+			return
+		}
+		reply := resp.Choices[0].Message.Content
+		s.emit(reply)
+		s.finish(reply, nil)
+	})
+}
+
+// cancelSpeculation 取消进行中的推测，缓存的回复随之丢弃
+func (d *DeepSeek) cancelSpeculation() {
+	if d.spec == nil {
+		return
+	}
+	d.spec.cancel()
+	d.spec = nil
+}
+
+// keepsSpeculation 判断打断是否为 TurnManager 提交推测目标轮次时发出的语义打断
+// 开启打断时该指令先于最终文本到达，推测需保留到最终文本到达时提交
+func (d *DeepSeek) keepsSpeculation(packet pipeline.Packet) bool {
+	t, _ := packet.Data.(pipeline.InterruptType)
+	return d.spec != nil && t == pipeline.InterruptTypeSemantic && packet.TurnSeq == d.spec.turnSeq
+}
+
+// commitSpeculation 最终文本与推测一致时提交推测的回复并返回 true，否则取消推测，由调用方重新生成
+func (d *DeepSeek) commitSpeculation(text string, packet pipeline.Packet) bool {
+	s := d.spec
+	d.spec = nil
+	if s == nil {
+		return false
+	}
+	if s.key != speculationKey(text) || s.turnSeq != packet.TurnSeq {
+		logger.Info("[TurnSeq: %d] **%s** Final transcript diverged from speculation, restarting", packet.TurnSeq, d.GetName())
+		s.cancel()
+		return false
+	}
+	if s.failed() {
+		logger.Info("[TurnSeq: %d] **%s** Speculation failed, restarting", packet.TurnSeq, d.GetName())
+		return false
+	}
+	logger.Info("[TurnSeq: %d] **%s** Committing speculative reply", packet.TurnSeq, d.GetName())
+
+	d.mu.Lock()
+	for len(d.messages) >= d.maxMessages {
+		d.messages = d.messages[1:]
+	}
+	d.messages = append(d.messages, openai.UserMessage(text))
+	d.mu.Unlock()
+
+	// 首 token 延迟从收到最终文本开始计算，推测已生成的片段在提交时立即输出
+	clock := d.Clock()
+	committedAt := clock.Now()
+	firstToken := true
+	stopped := false
+	forward := func(content string) bool {
+		if packet.TurnSeq < d.GetCurTurnSeq() {
+			logger.Info("**%s** Turn sequence changed from %d to %d, stopping speculative reply", d.GetName(), packet.TurnSeq, d.GetCurTurnSeq())
+			stopped = true
+			return false
+		}
+		if firstToken {
+			firstToken = false
+			now := clock.Now()
+			packet.Trace.MarkAt(pipeline.SpanLLMFirstToken, d.GetName(), now)
+			d.ObserveLatency(pipeline.LatencyLLMFirstToken, now.Sub(committedAt))
+		}
+		d.ForwardPacket(pipeline.Packet{
+			Data:    content,
+			Seq:     d.GetSeq(),
+			TurnSeq: packet.TurnSeq,
+			Trace:   packet.Trace,
+		})
+		return true
+	}
+	s.commit(forward, func(reply string, err error) {
+		d.mu.Lock()
+		d.metrics.TurnEndTs = clock.Now().UnixMilli()
+		d.totalLatencyMs = clock.Since(committedAt).Milliseconds()
+		d.mu.Unlock()
+		if err != nil {
+			logger.Error("**%s** Speculative reply failed: %v", d.GetName(), err)
+			d.UpdateErrorStatus(err)
+			return
+		}
+		if stopped {
+			d.publishReply(packet.TurnSeq, reply, true)
+			return
+		}
+		d.mu.Lock()
+		if len(d.messages) >= d.maxMessages {
+			d.messages = d.messages[1:]
+		}
+		d.messages = append(d.messages, openai.AssistantMessage(reply))
+		d.mu.Unlock()
+		d.publishReply(packet.TurnSeq, reply, false)
+	})
+	return true
+}
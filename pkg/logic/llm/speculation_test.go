@@ -0,0 +1,187 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"streamlink/internal/config"
+	"streamlink/pkg/logger"
+	"streamlink/pkg/logic/pipeline"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/packages/ssestream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger(&config.LogConfig{Level: "error"})
+	os.Exit(m.Run())
+}
+
+// fakeChat 每次请求依次返回 chunks 中的流式片段
+type fakeChat struct {
+	chunks   []string
+	hold     chan struct{} // 非 nil 时生成在其关闭前阻塞
+	calls    atomic.Int32
+	canceled atomic.Int32
+	finished chan struct{} // 每次生成结束时发送
+}
+
+func newFakeChat(chunks ...string) *fakeChat {
+	return &fakeChat{chunks: chunks, finished: make(chan struct{}, 10)}
+}
+
+func (f *fakeChat) New(context.Context, openai.ChatCompletionNewParams, ...option.RequestOption) (*openai.ChatCompletion, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (f *fakeChat) NewStreaming(ctx context.Context, _ openai.ChatCompletionNewParams, _ ...option.RequestOption) *ssestream.Stream[openai.ChatCompletionChunk] {
+	f.calls.Add(1)
+	return ssestream.NewStream[openai.ChatCompletionChunk](&fakeDecoder{ctx: ctx, chat: f}, nil)
+}
+
+type fakeDecoder struct {
+	ctx   context.Context
+	chat  *fakeChat
+	next  int
+	event ssestream.Event
+	err   error
+}
+
+func (d *fakeDecoder) Next() bool {
+	if d.next == 0 && d.chat.hold != nil {
+		select {
+		case <-d.chat.hold:
+		case <-d.ctx.Done():
+			d.chat.canceled.Add(1)
+			d.err = d.ctx.Err()
+			return false
+		}
+	}
+	if d.next >= len(d.chat.chunks) {
+		return false
+	}
+	d.event = ssestream.Event{Data: []byte(fmt.Sprintf(`{"choices":[{"index":0,"delta":{"content":%q}}]}`, d.chat.chunks[d.next]))}
+	d.next++
+	return true
+}
+
+func (d *fakeDecoder) Event() ssestream.Event { return d.event }
+func (d *fakeDecoder) Err() error             { return d.err }
+func (d *fakeDecoder) Close() error {
+	d.chat.finished <- struct{}{}
+	return nil
+}
+
+func startSpeculativeDeepSeek(t *testing.T, chat *fakeChat) *DeepSeek {
+	d := NewDeepSeek("", "")
+	d.client = chat
+	d.SetStreaming(true)
+	d.SetSpeculative(true)
+	d.SetInput()
+	assert.NoError(t, d.Start())
+	t.Cleanup(d.Stop)
+	return d
+}
+
+func waitFinished(t *testing.T, chat *fakeChat) {
+	select {
+	case <-chat.finished:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for generation")
+	}
+}
+
+func recvText(t *testing.T, d *DeepSeek) string {
+	select {
+	case packet := <-d.GetOutputChan():
+		return packet.Data.(string)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for reply")
+		return ""
+	}
+}
+
+func TestDeepSeek_SpeculationCommitted(t *testing.T) {
+	chat := newFakeChat("好的", "，帮您查询")
+	d := startSpeculativeDeepSeek(t, chat)
+
+	// 推测的回复生成完毕也不会发往下游
+	d.Process(pipeline.Packet{Data: pipeline.SpeculativeTranscript{Text: "帮我订明天去北京的机票"}})
+	waitFinished(t, chat)
+	assert.Empty(t, d.GetOutputChan())
+
+	// 最终文本只多出标点，直接提交推测的回复
+	d.Process(pipeline.Packet{Data: "帮我订明天去北京的机票。", TurnSeq: 1})
+	assert.Equal(t, "好的。", recvText(t, d))
+	assert.Equal(t, "，帮您查询", recvText(t, d))
+	assert.Equal(t, int32(1), chat.calls.Load())
+	assert.Eventually(t, func() bool {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return len(d.messages) == 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestDeepSeek_SpeculationDiverged(t *testing.T) {
+	chat := newFakeChat("好的")
+	chat.hold = make(chan struct{})
+	d := startSpeculativeDeepSeek(t, chat)
+
+	// 推测输入变化时取消之前的推测
+	d.Process(pipeline.Packet{Data: pipeline.SpeculativeTranscript{Text: "帮我订明天"}})
+	d.Process(pipeline.Packet{Data: pipeline.SpeculativeTranscript{Text: "帮我订明天去北京"}})
+	waitFinished(t, chat)
+	assert.Equal(t, int32(1), chat.canceled.Load())
+
+	// 最终文本与推测不一致时取消推测并重新生成，推测的回复不会发往下游
+	d.Process(pipeline.Packet{Data: "帮我订明天去北京的机票。", TurnSeq: 1})
+	waitFinished(t, chat)
+	assert.Equal(t, int32(2), chat.canceled.Load())
+
+	close(chat.hold)
+	assert.Equal(t, "好的。", recvText(t, d))
+	waitFinished(t, chat)
+	assert.Empty(t, d.GetOutputChan())
+	assert.Equal(t, int32(3), chat.calls.Load())
+}
+
+func TestDeepSeek_SpeculationInterrupted(t *testing.T) {
+	chat := newFakeChat("好的")
+	chat.hold = make(chan struct{})
+	d := startSpeculativeDeepSeek(t, chat)
+
+	// 打断时取消进行中的推测
+	d.Process(pipeline.Packet{Data: pipeline.SpeculativeTranscript{Text: "帮我订明天去北京的机票"}})
+	d.Process(*pipeline.GenInterruptPacket(1))
+	waitFinished(t, chat)
+	assert.Equal(t, int32(1), chat.canceled.Load())
+	assert.Equal(t, pipeline.PacketCommandInterrupt, (<-d.GetOutputChan()).Command)
+	assert.Nil(t, d.spec)
+}
+
+func TestDeepSeek_SpeculationSurvivesCommitInterrupt(t *testing.T) {
+	chat := newFakeChat("好的")
+	d := startSpeculativeDeepSeek(t, chat)
+
+	d.Process(pipeline.Packet{Data: pipeline.SpeculativeTranscript{Text: "帮我订明天去北京的机票"}})
+	waitFinished(t, chat)
+
+	// 开启打断时 TurnManager 提交轮次前先发出语义打断，推测保留到最终文本到达
+	d.Process(pipeline.Packet{Data: pipeline.InterruptTypeSemantic, TurnSeq: 1, Command: pipeline.PacketCommandInterrupt})
+	assert.Equal(t, pipeline.PacketCommandInterrupt, (<-d.GetOutputChan()).Command)
+	d.Process(pipeline.Packet{Data: "帮我订明天去北京的机票。", TurnSeq: 1})
+	assert.Equal(t, "好的。", recvText(t, d))
+	assert.Equal(t, int32(1), chat.calls.Load())
+
+	// 其他来源的打断仍取消推测
+	d.Process(pipeline.Packet{Data: pipeline.SpeculativeTranscript{Text: "换成上海"}, TurnSeq: 1})
+	waitFinished(t, chat)
+	d.Process(pipeline.Packet{Data: pipeline.InterruptTypeBargeIn, TurnSeq: 2, Command: pipeline.PacketCommandInterrupt})
+	assert.Equal(t, pipeline.PacketCommandInterrupt, (<-d.GetOutputChan()).Command)
+	assert.Nil(t, d.spec)
+}
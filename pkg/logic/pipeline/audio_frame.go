@@ -0,0 +1,202 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// SampleFormat 定义音频数据的采样/编码格式
+type SampleFormat int
+
+const (
+	SampleFormatUnknown SampleFormat = iota // 未声明
+	SampleFormatS16                         // 16 位有符号整数 PCM，多声道交织存储
+	SampleFormatOpus                        // Opus 编码帧
+	SampleFormatMP3                         // MP3 编码数据
+)
+
+// String 返回采样格式的字符串表示
+func (f SampleFormat) String() string {
+	switch f {
+	case SampleFormatS16:
+		return "s16"
+	case SampleFormatOpus:
+		return "opus"
+	case SampleFormatMP3:
+		return "mp3"
+	default:
+		return "unknown"
+	}
+}
+
+// IsEncoded 判断是否为压缩编码格式
+func (f SampleFormat) IsEncoded() bool {
+	return f == SampleFormatOpus || f == SampleFormatMP3
+}
+
+// AudioFormat 描述一路音频流的格式，零值字段表示不限制
+type AudioFormat struct {
+	SampleRate int          `json:"sample_rate"`
+	Channels   int          `json:"channels"`
+	Format     SampleFormat `json:"format"`
+}
+
+// NewPCMFormat 创建 S16 PCM 格式描述
+func NewPCMFormat(sampleRate, channels int) AudioFormat {
+	return AudioFormat{SampleRate: sampleRate, Channels: channels, Format: SampleFormatS16}
+}
+
+// NewOpusFormat 创建 Opus 格式描述
+func NewOpusFormat(sampleRate, channels int) AudioFormat {
+	return AudioFormat{SampleRate: sampleRate, Channels: channels, Format: SampleFormatOpus}
+}
+
+// IsZero 判断格式是否未声明
+func (f AudioFormat) IsZero() bool {
+	return f.SampleRate == 0 && f.Channels == 0 && f.Format == SampleFormatUnknown
+}
+
+// Matches 判断 other 是否满足 f 的约束，f 中的零值字段视为通配
+func (f AudioFormat) Matches(other AudioFormat) bool {
+	if f.SampleRate != 0 && f.SampleRate != other.SampleRate {
+		return false
+	}
+	if f.Channels != 0 && f.Channels != other.Channels {
+		return false
+	}
+	if f.Format != SampleFormatUnknown && f.Format != other.Format {
+		return false
+	}
+	return true
+}
+
+// String 返回格式的字符串表示，例如 s16/16000Hz/1ch
+func (f AudioFormat) String() string {
+	if f.IsZero() {
+		return "any"
+	}
+	return fmt.Sprintf("%s/%dHz/%dch", f.Format, f.SampleRate, f.Channels)
+}
+
+// AudioFrame 是音频组件之间传递的数据载荷，携带完整的格式信息
+type AudioFrame struct {
+	AudioFormat
+	Samples   []int16       // S16 PCM 采样数据，Format 为 SampleFormatS16 时有效
+	Payload   []byte        // 编码后的数据，Format 为编码格式时有效
+	Duration  time.Duration // 帧时长
+	Timestamp time.Duration // 媒体时间戳，相对于流的起点
+
+	buffer *AudioBuffer // 池化帧持有的缓冲区，见 Retain/Release
+}
+
+// NewPCMFrame 创建 PCM 音频帧，时长根据采样数自动计算
+func NewPCMFrame(samples []int16, sampleRate, channels int, timestamp time.Duration) AudioFrame {
+	return AudioFrame{
+		AudioFormat: NewPCMFormat(sampleRate, channels),
+		Samples:     samples,
+		Duration:    SamplesDuration(len(samples), sampleRate, channels),
+		Timestamp:   timestamp,
+	}
+}
+
+// NewEncodedFrame 创建编码音频帧
+func NewEncodedFrame(payload []byte, format AudioFormat, duration, timestamp time.Duration) AudioFrame {
+	return AudioFrame{
+		AudioFormat: format,
+		Payload:     payload,
+		Duration:    duration,
+		Timestamp:   timestamp,
+	}
+}
+
+// SamplesPerChannel 返回每个声道的采样点数
+func (f AudioFrame) SamplesPerChannel() int {
+	if f.Channels <= 0 {
+		return 0
+	}
+	return len(f.Samples) / f.Channels
+}
+
+// Len 返回帧中有效数据的长度（PCM 为采样点数，编码帧为字节数）
+func (f AudioFrame) Len() int {
+	if f.Format.IsEncoded() {
+		return len(f.Payload)
+	}
+	return len(f.Samples)
+}
+
+// Validate 检查帧的格式信息是否自洽
+func (f AudioFrame) Validate() error {
+	if f.SampleRate <= 0 {
+		return fmt.Errorf("invalid sample rate: %d", f.SampleRate)
+	}
+	if f.Channels <= 0 {
+		return fmt.Errorf("invalid channels: %d", f.Channels)
+	}
+	switch f.Format {
+	case SampleFormatS16:
+		if len(f.Samples)%f.Channels != 0 {
+			return fmt.Errorf("sample count %d is not a multiple of channels %d", len(f.Samples), f.Channels)
+		}
+	case SampleFormatOpus, SampleFormatMP3:
+	default:
+		return fmt.Errorf("unknown sample format: %d", f.Format)
+	}
+	return nil
+}
+
+// Bytes 返回帧数据的字节表示，PCM 按小端序编码
+func (f AudioFrame) Bytes() []byte {
+	if f.Format.IsEncoded() {
+		return f.Payload
+	}
+	return SamplesToBytes(f.Samples)
+}
+
+// RMS 返回 PCM 采样的均方根能量，范围 0~32768，编码帧与空帧返回 0
+func (f AudioFrame) RMS() float64 {
+	if f.Format.IsEncoded() || len(f.Samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range f.Samples {
+		v := float64(s)
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(len(f.Samples)))
+}
+
+// SamplesDuration 计算给定采样点数对应的时长
+func SamplesDuration(samples, sampleRate, channels int) time.Duration {
+	if sampleRate <= 0 || channels <= 0 {
+		return 0
+	}
+	return time.Duration(samples/channels) * time.Second / time.Duration(sampleRate)
+}
+
+// BytesToSamples 将小端序 PCM 字节转换为 []int16
+func BytesToSamples(data []byte) []int16 {
+	return AppendBytesToSamples(make([]int16, 0, len(data)/2), data)
+}
+
+// SamplesToBytes 将 []int16 转换为小端序 PCM 字节
+func SamplesToBytes(samples []int16) []byte {
+	return AppendSamplesToBytes(make([]byte, 0, len(samples)*2), samples)
+}
+
+// AppendBytesToSamples 将小端序 PCM 字节转换后追加到 dst，dst 容量足够时不分配内存
+func AppendBytesToSamples(dst []int16, data []byte) []int16 {
+	for i := 0; i+1 < len(data); i += 2 {
+		dst = append(dst, int16(data[i])|int16(data[i+1])<<8)
+	}
+	return dst
+}
+
+// AppendSamplesToBytes 将采样按小端序追加到 dst，dst 容量足够时不分配内存
+func AppendSamplesToBytes(dst []byte, samples []int16) []byte {
+	for _, sample := range samples {
+		dst = append(dst, byte(sample), byte(sample>>8))
+	}
+	return dst
+}
@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudioFrame_PCM(t *testing.T) {
+	samples := make([]int16, 320*2)
+	frame := NewPCMFrame(samples, 16000, 2, 40*time.Millisecond)
+
+	assert.NoError(t, frame.Validate())
+	assert.Equal(t, 320, frame.SamplesPerChannel())
+	assert.Equal(t, 20*time.Millisecond, frame.Duration)
+	assert.Equal(t, 40*time.Millisecond, frame.Timestamp)
+	assert.Len(t, frame.Bytes(), len(samples)*2)
+
+	// 采样数不是声道数的整数倍
+	frame.Samples = samples[:3]
+	assert.Error(t, frame.Validate())
+}
+
+func TestAudioFrame_BytesRoundTrip(t *testing.T) {
+	samples := []int16{0, 1, -1, 32767, -32768}
+	assert.Equal(t, samples, BytesToSamples(SamplesToBytes(samples)))
+}
+
+func TestAudioFormat_Matches(t *testing.T) {
+	pcm16k := NewPCMFormat(16000, 1)
+
+	assert.True(t, pcm16k.Matches(NewPCMFormat(16000, 1)))
+	assert.False(t, pcm16k.Matches(NewPCMFormat(48000, 2)))
+	assert.False(t, pcm16k.Matches(NewOpusFormat(16000, 1)))
+	assert.True(t, AudioFormat{Format: SampleFormatS16}.Matches(NewPCMFormat(48000, 2)))
+}
+
+func TestBaseComponent_ExpectAudioFrame(t *testing.T) {
+	b := NewBaseComponent("test", 1)
+	b.SetInputFormat(NewPCMFormat(16000, 1))
+
+	_, ok := b.ExpectAudioFrame(Packet{Data: NewPCMFrame(make([]int16, 960), 48000, 2, 0)})
+	assert.False(t, ok)
+	assert.Error(t, b.GetHealth().LastError)
+	assert.Equal(t, int64(1), b.GetHealth().DroppedCount)
+
+	frame, ok := b.ExpectAudioFrame(Packet{Data: NewPCMFrame(make([]int16, 320), 16000, 1, 0)})
+	assert.True(t, ok)
+	assert.Equal(t, 320, frame.SamplesPerChannel())
+
+	_, ok = b.ExpectAudioFrame(Packet{Data: []int16{1, 2}})
+	assert.False(t, ok)
+}
+
+func TestCheckFormatCompatible(t *testing.T) {
+	from := NewBaseComponent("from", 1)
+	to := NewBaseComponent("to", 1)
+
+	// 未声明格式时视为兼容
+	assert.NoError(t, CheckFormatCompatible(from, to))
+
+	from.SetOutputFormat(NewPCMFormat(16000, 1))
+	to.SetInputFormat(NewPCMFormat(48000, 2))
+	assert.Error(t, CheckFormatCompatible(from, to))
+
+	to.SetInputFormat(NewPCMFormat(16000, 1))
+	assert.NoError(t, CheckFormatCompatible(from, to))
+}
@@ -0,0 +1,230 @@
+package pipeline
+
+import (
+	"time"
+)
+
+// BackpressureMode 定义边上缓冲满时的处理策略
+type BackpressureMode int
+
+const (
+	BackpressureDropNewest BackpressureMode = iota // 丢弃新到达的数据包（默认）
+	BackpressureDropOldest                         // 丢弃缓冲中最旧的数据包，适合实时音频
+	BackpressureBlock                              // 阻塞等待，可设置超时，适合不允许丢失的文本
+	BackpressureCoalesce                           // 合并缓冲中相邻的同类数据包，仍放不下时按 Block 处理
+)
+
+// String 返回策略的字符串表示
+func (m BackpressureMode) String() string {
+	switch m {
+	case BackpressureDropNewest:
+		return "drop_newest"
+	case BackpressureDropOldest:
+		return "drop_oldest"
+	case BackpressureBlock:
+		return "block"
+	case BackpressureCoalesce:
+		return "coalesce"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseBackpressureMode 从字符串解析策略
+func ParseBackpressureMode(s string) (BackpressureMode, bool) {
+	for _, m := range []BackpressureMode{BackpressureDropNewest, BackpressureDropOldest, BackpressureBlock, BackpressureCoalesce} {
+		if m.String() == s {
+			return m, true
+		}
+	}
+	return BackpressureDropNewest, false
+}
+
+// CoalesceFunc 尝试将相邻的两个数据包合并为一个，合并成功时负责释放被合并数据包的池化帧
+type CoalesceFunc func(prev, next Packet) (Packet, bool)
+
+// BackpressurePolicy 描述一条边的背压策略
+type BackpressurePolicy struct {
+	Mode     BackpressureMode
+	Timeout  time.Duration // Block/Coalesce 模式下的最长等待时间，0 表示一直等待直到组件停止
+	Coalesce CoalesceFunc  // Coalesce 模式下的合并函数，为空时使用 DefaultCoalesce
+}
+
+// DropOldestPolicy 返回丢弃最旧数据的策略
+func DropOldestPolicy() BackpressurePolicy {
+	return BackpressurePolicy{Mode: BackpressureDropOldest}
+}
+
+// BlockPolicy 返回阻塞等待的策略
+func BlockPolicy(timeout time.Duration) BackpressurePolicy {
+	return BackpressurePolicy{Mode: BackpressureBlock, Timeout: timeout}
+}
+
+// BackpressureStats 记录各背压策略的触发次数
+type BackpressureStats struct {
+	DroppedNewest int64 `json:"dropped_newest"` // 丢弃的新数据包
+	DroppedOldest int64 `json:"dropped_oldest"` // 丢弃的旧数据包
+	Blocked       int64 `json:"blocked"`        // 发生阻塞等待的次数
+	BlockTimeouts int64 `json:"block_timeouts"` // 阻塞超时后丢弃的次数
+	Coalesced     int64 `json:"coalesced"`      // 被合并掉的数据包
+}
+
+// Dropped 返回因背压丢弃的数据包总数
+func (s BackpressureStats) Dropped() int64 {
+	return s.DroppedNewest + s.DroppedOldest + s.BlockTimeouts
+}
+
+// DefaultCoalesce 合并同一轮次中相邻的文本或同格式 PCM 音频
+func DefaultCoalesce(prev, next Packet) (Packet, bool) {
+	if prev.Command != PacketCommandNone || next.Command != PacketCommandNone || prev.TurnSeq != next.TurnSeq {
+		return prev, false
+	}
+	switch p := prev.Data.(type) {
+	case string:
+		if n, ok := next.Data.(string); ok {
+			prev.Data = p + n
+			return prev, true
+		}
+	case AudioFrame:
+		n, ok := next.Data.(AudioFrame)
+		if ok && p.Format == SampleFormatS16 && p.AudioFormat == n.AudioFormat {
+			buf := NewSampleBuffer(len(p.Samples) + len(n.Samples))
+			copy(buf.Samples[copy(buf.Samples, p.Samples):], n.Samples)
+			p.Release()
+			n.Release()
+			p.Samples = buf.Samples
+			p.buffer = buf
+			p.Duration += n.Duration
+			prev.Data = p
+			return prev, true
+		}
+	}
+	return prev, false
+}
+
+// deliver 按策略将数据包写入 ch，返回是否成功写入
+// 指令包无论策略如何都会等待写入，除非组件停止
+// 数据包的池化音频帧引用随之移交，未能写入或被挤出缓冲的数据包在此释放
+// 调用方需保证对同一 ch 的写入是串行的
+func deliver(ch chan Packet, packet Packet, policy BackpressurePolicy, clock Clock, stopCh <-chan struct{}, stats *BackpressureStats) bool {
+	select {
+	case ch <- packet:
+		return true
+	default:
+	}
+
+	if packet.Command != PacketCommandNone {
+		return blockingSend(ch, packet, 0, clock, stopCh, stats)
+	}
+
+	switch policy.Mode {
+	case BackpressureDropOldest:
+		if dropped, ok := dropOldest(ch); ok {
+			ReleasePacket(dropped)
+			stats.DroppedOldest++
+		}
+		select {
+		case ch <- packet:
+			return true
+		default:
+			ReleasePacket(packet)
+			stats.DroppedNewest++
+			return false
+		}
+	case BackpressureBlock:
+		return blockingSend(ch, packet, policy.Timeout, clock, stopCh, stats)
+	case BackpressureCoalesce:
+		coalesce := policy.Coalesce
+		if coalesce == nil {
+			coalesce = DefaultCoalesce
+		}
+		stats.Coalesced += int64(compact(ch, coalesce))
+		return blockingSend(ch, packet, policy.Timeout, clock, stopCh, stats)
+	default:
+		ReleasePacket(packet)
+		stats.DroppedNewest++
+		return false
+	}
+}
+
+// blockingSend 阻塞写入，timeout 为 0 时一直等待直到 stopCh 关闭
+// 超时按 clock 计时，测试中可由 FakeClock 推进
+func blockingSend(ch chan Packet, packet Packet, timeout time.Duration, clock Clock, stopCh <-chan struct{}, stats *BackpressureStats) bool {
+	stats.Blocked++
+
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timer := clock.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C()
+	}
+
+	select {
+	case ch <- packet:
+		return true
+	case <-timeoutCh:
+		ReleasePacket(packet)
+		stats.BlockTimeouts++
+		return false
+	case <-stopCh:
+		ReleasePacket(packet)
+		return false
+	}
+}
+
+// drain 取出 ch 中当前所有的数据包
+func drain(ch chan Packet) []Packet {
+	var packets []Packet
+	for {
+		select {
+		case packet := <-ch:
+			packets = append(packets, packet)
+		default:
+			return packets
+		}
+	}
+}
+
+// refill 将数据包按原顺序写回 ch
+func refill(ch chan Packet, packets []Packet) {
+	for _, packet := range packets {
+		ch <- packet
+	}
+}
+
+// dropOldest 移除并返回 ch 中最旧的数据包，指令包保留，保持其余数据包的顺序
+func dropOldest(ch chan Packet) (Packet, bool) {
+	packets := drain(ch)
+	var dropped Packet
+	ok := false
+	for i, packet := range packets {
+		if packet.Command == PacketCommandNone {
+			dropped = packet
+			packets = append(packets[:i], packets[i+1:]...)
+			ok = true
+			break
+		}
+	}
+	refill(ch, packets)
+	return dropped, ok
+}
+
+// compact 合并 ch 中相邻可合并的数据包，返回被合并掉的数量
+func compact(ch chan Packet, coalesce CoalesceFunc) int {
+	packets := drain(ch)
+	if len(packets) == 0 {
+		return 0
+	}
+
+	merged := packets[:1]
+	for _, packet := range packets[1:] {
+		last := len(merged) - 1
+		if combined, ok := coalesce(merged[last], packet); ok {
+			merged[last] = combined
+			continue
+		}
+		merged = append(merged, packet)
+	}
+	refill(ch, merged)
+	return len(packets) - len(merged)
+}
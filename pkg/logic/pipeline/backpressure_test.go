@@ -0,0 +1,184 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackpressure_DropNewest(t *testing.T) {
+	b := NewBaseComponent("test", 1)
+
+	b.ForwardPacket(Packet{Data: "a"})
+	b.ForwardPacket(Packet{Data: "b"})
+
+	assert.Equal(t, "a", recv(t, b.GetOutputChan()).Data)
+	health := b.GetHealth()
+	assert.Equal(t, int64(1), health.DroppedCount)
+	assert.Equal(t, int64(1), health.Backpressure.DroppedNewest)
+}
+
+func TestBackpressure_DropOldest(t *testing.T) {
+	b := NewBaseComponent("test", 2)
+	b.SetBackpressure(DropOldestPolicy())
+
+	b.ForwardPacket(*GenInterruptPacket(1))
+	b.ForwardPacket(Packet{Data: "a"})
+	b.ForwardPacket(Packet{Data: "b"})
+
+	// 指令包不会被当作最旧的数据丢弃
+	assert.Equal(t, PacketCommandInterrupt, recv(t, b.GetOutputChan()).Command)
+	assert.Equal(t, "b", recv(t, b.GetOutputChan()).Data)
+	assert.Equal(t, int64(1), b.GetHealth().Backpressure.DroppedOldest)
+}
+
+func TestBackpressure_BlockTimeout(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	b := NewBaseComponent("test", 1)
+	b.SetClock(clock)
+	b.SetBackpressure(BlockPolicy(time.Minute))
+
+	b.ForwardPacket(Packet{Data: "a"})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.ForwardPacket(Packet{Data: "b"})
+	}()
+
+	// 超时按组件的时钟计时
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-done
+
+	health := b.GetHealth()
+	assert.Equal(t, int64(1), health.Backpressure.Blocked)
+	assert.Equal(t, int64(1), health.Backpressure.BlockTimeouts)
+	assert.Equal(t, int64(1), health.DroppedCount)
+}
+
+func TestBackpressure_BlockNeverDrops(t *testing.T) {
+	b := NewBaseComponent("test", 1)
+	b.SetBackpressure(BlockPolicy(0))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, s := range []string{"a", "b", "c"} {
+			b.ForwardPacket(Packet{Data: s})
+		}
+	}()
+
+	for _, s := range []string{"a", "b", "c"} {
+		assert.Equal(t, s, recv(t, b.GetOutputChan()).Data)
+	}
+	<-done
+	assert.Equal(t, int64(0), b.GetHealth().DroppedCount)
+
+	// 组件停止后不再阻塞
+	b.ForwardPacket(Packet{Data: "d"})
+	b.Stop()
+	b.ForwardPacket(Packet{Data: "e"})
+}
+
+func TestBackpressure_Coalesce(t *testing.T) {
+	b := NewBaseComponent("test", 2)
+	b.SetBackpressure(BackpressurePolicy{Mode: BackpressureCoalesce, Timeout: 20 * time.Millisecond})
+
+	b.ForwardPacket(Packet{Data: "hel", TurnSeq: 1})
+	b.ForwardPacket(Packet{Data: "lo", TurnSeq: 1})
+	b.ForwardPacket(Packet{Data: " world", TurnSeq: 1})
+
+	assert.Equal(t, "hello", recv(t, b.GetOutputChan()).Data)
+	assert.Equal(t, " world", recv(t, b.GetOutputChan()).Data)
+	health := b.GetHealth()
+	assert.Equal(t, int64(1), health.Backpressure.Coalesced)
+	assert.Equal(t, int64(0), health.DroppedCount)
+}
+
+func TestBackpressure_ReleasesDropped(t *testing.T) {
+	pooled := func() (Packet, *AudioBuffer) {
+		buf := NewSampleBuffer(160)
+		return Packet{Data: NewPooledPCMFrame(buf, 16000, 1, 0)}, buf
+	}
+
+	// drop_newest 释放新到达的数据包
+	b := NewBaseComponent("test", 1)
+	first, firstBuf := pooled()
+	dropped, droppedBuf := pooled()
+	b.ForwardPacket(first)
+	b.ForwardPacket(dropped)
+	assert.Equal(t, int32(0), droppedBuf.refs.Load())
+	assert.Equal(t, int32(1), firstBuf.refs.Load())
+
+	// drop_oldest 释放被挤出缓冲的数据包
+	b = NewBaseComponent("test", 1)
+	b.SetBackpressure(DropOldestPolicy())
+	oldest, oldestBuf := pooled()
+	newest, newestBuf := pooled()
+	b.ForwardPacket(oldest)
+	b.ForwardPacket(newest)
+	assert.Equal(t, int32(0), oldestBuf.refs.Load())
+	assert.Equal(t, int32(1), newestBuf.refs.Load())
+
+	// block 超时或组件停止后释放未写入的数据包
+	b = NewBaseComponent("test", 1)
+	b.SetBackpressure(BlockPolicy(time.Millisecond))
+	b.ForwardPacket(Packet{Data: "a"})
+	timedOut, timedOutBuf := pooled()
+	b.ForwardPacket(timedOut)
+	assert.Equal(t, int32(0), timedOutBuf.refs.Load())
+
+	b.Stop()
+	stopped, stoppedBuf := pooled()
+	b.ForwardPacket(stopped)
+	assert.Equal(t, int32(0), stoppedBuf.refs.Load())
+}
+
+func TestDefaultCoalesce_PCM(t *testing.T) {
+	a := Packet{Data: NewPCMFrame(make([]int16, 160), 16000, 1, 0)}
+	b := Packet{Data: NewPCMFrame(make([]int16, 160), 16000, 1, 10*time.Millisecond)}
+
+	merged, ok := DefaultCoalesce(a, b)
+	assert.True(t, ok)
+	frame := merged.Data.(AudioFrame)
+	assert.Equal(t, 320, frame.SamplesPerChannel())
+	assert.Equal(t, 20*time.Millisecond, frame.Duration)
+
+	// 不同轮次不合并
+	b.TurnSeq = 1
+	_, ok = DefaultCoalesce(a, b)
+	assert.False(t, ok)
+}
+
+func TestGraph_EdgeBackpressure(t *testing.T) {
+	source := newPassthrough("source")
+	fast := newPassthrough("fast")
+	slow := newPassthrough("slow")
+
+	dropOldest := DropOldestPolicy()
+	g := NewGraph()
+	g.AddEdge(source, fast, EdgeOptions{BufferSize: 1, Backpressure: &dropOldest})
+	g.AddEdge(source, slow, EdgeOptions{BufferSize: 1})
+
+	built, err := g.Build()
+	assert.NoError(t, err)
+
+	var tee *Tee
+	for _, c := range built {
+		if c, ok := c.(*Tee); ok {
+			tee = c
+		}
+	}
+	assert.NotNil(t, tee)
+
+	tee.broadcast(Packet{Data: "a"})
+	tee.broadcast(Packet{Data: "b"})
+
+	branches := tee.Branches()
+	assert.Equal(t, "b", recv(t, branches[0]).Data)
+	assert.Equal(t, "a", recv(t, branches[1]).Data)
+	health := tee.GetHealth()
+	assert.Equal(t, int64(1), health.Backpressure.DroppedOldest)
+	assert.Equal(t, int64(1), health.Backpressure.DroppedNewest)
+}
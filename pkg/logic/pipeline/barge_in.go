@@ -0,0 +1,128 @@
+package pipeline
+
+import (
+	"streamlink/pkg/logger"
+	"sync"
+	"time"
+)
+
+// bargeInGap 说话中允许的短暂停顿，超过后重新累计说话时长
+const bargeInGap = 100 * time.Millisecond
+
+// BargeInConfig 播放期间打断的检测参数
+type BargeInConfig struct {
+	MinDuration     time.Duration // 播放期间用户持续说话达到该时长后打断
+	EnergyThreshold float64       // 输入音频帧的 RMS 能量不低于该值时视为说话
+	Cooldown        time.Duration // 两次打断之间的最短间隔，避免同一段话重复打断
+}
+
+// DefaultBargeInConfig 返回默认参数
+func DefaultBargeInConfig() BargeInConfig {
+	return BargeInConfig{
+		MinDuration:     300 * time.Millisecond,
+		EnergyThreshold: 1000,
+		Cooldown:        2 * time.Second,
+	}
+}
+
+// PlaybackMonitor 提供输出端的播放进度，由 WebRTCSink 等输出组件实现
+type PlaybackMonitor interface {
+	PlaybackPosition() PlaybackPosition
+}
+
+// playbackSource 可在运行中设置的播放进度来源，供需要判断代理是否在说话的组件嵌入
+type playbackSource struct {
+	monitor PlaybackMonitor
+	mu      sync.RWMutex
+}
+
+// SetPlayback 设置输出端的播放进度来源，未设置时视为没有在播放
+func (p *playbackSource) SetPlayback(monitor PlaybackMonitor) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.monitor = monitor
+}
+
+// position 返回当前的播放进度
+func (p *playbackSource) position() PlaybackPosition {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.monitor == nil {
+		return PlaybackPosition{}
+	}
+	return p.monitor.PlaybackPosition()
+}
+
+// BargeInEvent 播放期间检测到用户说话并打断
+type BargeInEvent struct {
+	Speech   time.Duration    `json:"speech"`   // 触发打断时用户已持续说话的时长
+	Playback PlaybackPosition `json:"playback"` // 打断时的播放进度
+}
+
+// BargeIn 在回复播放期间检测用户持续说话，立即打断播放
+// 输入音频原样转发给下游（通常为 ASR），检测到打断时向下游发出打断指令，
+// 由 TurnManager 开始新轮次并经控制通道停止输出端的播放，之后继续识别用户的话
+type BargeIn struct {
+	*Stage[AudioFrame, AudioFrame]
+	playbackSource
+	config BargeInConfig
+
+	speech      time.Duration // 播放期间连续说话的时长
+	gap         time.Duration // 说话中当前停顿的时长
+	lastBargeIn time.Time     // 上一次打断的时间
+}
+
+// NewBargeIn 创建打断检测组件，需通过 SetPlayback 提供输出端的播放进度
+func NewBargeIn(config BargeInConfig) *BargeIn {
+	b := &BargeIn{config: config}
+	b.Stage = NewStage("BargeIn", 100, b.detect)
+	b.SetInputFormat(AudioFormat{Format: SampleFormatS16})
+	b.SetBackpressure(DropOldestPolicy())
+	// 检测作用于整条输入流，不按轮次过滤
+	b.SetIgnoreTurn(true)
+	return b
+}
+
+// detect 转发音频，打断指令先于触发它的音频帧发出
+func (b *BargeIn) detect(frame AudioFrame, packet Packet, emit func(AudioFrame)) error {
+	defer emit(frame)
+	b.observe(frame.RMS() >= b.config.EnergyThreshold, frame.Duration, packet.TurnSeq)
+	return nil
+}
+
+// observe 播放期间累计用户说话的时长，达到阈值且不在冷却期内时打断
+func (b *BargeIn) observe(loud bool, duration time.Duration, turnSeq int) {
+	position := b.position()
+	if !position.Playing {
+		b.speech, b.gap = 0, 0
+		return
+	}
+
+	if loud {
+		b.speech += duration
+		b.gap = 0
+	} else if b.gap += duration; b.gap > bargeInGap {
+		b.speech, b.gap = 0, 0
+	}
+	if b.speech < b.config.MinDuration {
+		return
+	}
+
+	speech := b.speech
+	b.speech, b.gap = 0, 0
+	now := b.Clock().Now()
+	if !b.lastBargeIn.IsZero() && now.Sub(b.lastBargeIn) < b.config.Cooldown {
+		return
+	}
+	b.lastBargeIn = now
+
+	logger.Info("[TurnSeq: %d] **%s** User spoke for %v during playback, interrupting turn %d at %v",
+		turnSeq, b.GetName(), speech, position.TurnSeq, position.TurnPlayed)
+	b.Publish(EventBargeIn, position.TurnSeq, BargeInEvent{Speech: speech, Playback: position})
+	// 与 VoiceAgent.Interrupt 一样使用 0 作为 turnSeq，由 TurnManager 分配新轮次
+	b.ForwardPacket(Packet{
+		Data:    InterruptTypeBargeIn,
+		Src:     b,
+		Command: PacketCommandInterrupt,
+	})
+}
@@ -0,0 +1,79 @@
+package pipeline
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePlayback struct {
+	playing atomic.Bool
+}
+
+func (f *fakePlayback) PlaybackPosition() PlaybackPosition {
+	return PlaybackPosition{TurnSeq: 1, Playing: f.playing.Load()}
+}
+
+// speak 送入 n 个 20ms 的音频帧，返回转发的音频帧数与打断指令数，打断指令先于触发它的音频帧转发
+func speak(t *testing.T, b *BargeIn, n int, amplitude int16) (frames, interrupts int) {
+	samples := make([]int16, 320)
+	for i := range samples {
+		samples[i] = amplitude
+	}
+	for i := 0; i < n; i++ {
+		b.Process(Packet{Data: NewPCMFrame(samples, 16000, 1, 0)})
+	}
+	for frames < n {
+		packet := recv(t, b.GetOutputChan())
+		if packet.Command == PacketCommandInterrupt {
+			assert.Equal(t, InterruptTypeBargeIn, packet.Data)
+			interrupts++
+			continue
+		}
+		frames++
+	}
+	return frames, interrupts
+}
+
+func TestBargeIn(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	bus := NewEventBus()
+	sub := bus.Subscribe(10, EventBargeIn)
+	playback := &fakePlayback{}
+	b := NewBargeIn(DefaultBargeInConfig())
+	b.SetClock(clock)
+	b.SetEventBus(bus)
+	b.SetPlayback(playback)
+	b.SetInputChan(make(chan Packet, 100))
+	assert.NoError(t, b.Start())
+	defer b.Stop()
+
+	// 没有播放时用户说话不打断，音频照常转发
+	frames, interrupts := speak(t, b, 20, 2000)
+	assert.Equal(t, 20, frames)
+	assert.Equal(t, 0, interrupts)
+
+	// 播放期间持续说话达到 300ms 后打断
+	playback.playing.Store(true)
+	_, interrupts = speak(t, b, 15, 2000)
+	assert.Equal(t, 1, interrupts)
+	event := recvEvent(t, sub)
+	assert.Equal(t, 300*time.Millisecond, event.Data.(BargeInEvent).Speech)
+
+	// 冷却期间不重复打断
+	_, interrupts = speak(t, b, 15, 2000)
+	assert.Equal(t, 0, interrupts)
+
+	// 冷却结束后，说话中超过 100ms 的停顿重新累计，低于能量阈值的声音不算说话
+	clock.Advance(2 * time.Second)
+	_, interrupts = speak(t, b, 10, 2000)
+	assert.Equal(t, 0, interrupts)
+	_, interrupts = speak(t, b, 10, 100)
+	assert.Equal(t, 0, interrupts)
+	_, interrupts = speak(t, b, 10, 2000)
+	assert.Equal(t, 0, interrupts)
+	_, interrupts = speak(t, b, 5, 2000)
+	assert.Equal(t, 1, interrupts)
+}
@@ -0,0 +1,150 @@
+package pipeline
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 音频缓冲池按容量分级（2 的整数次幂）复用切片，避免音频链路上每个数据包都分配内存
+//
+// 池化的缓冲区带引用计数，创建时计数为 1：
+//   - 数据包被复制给多个下游（如 Tee 的分支）时，每多一个持有者调用一次 Retain
+//   - 终点组件（编码器、网络输出、ASR 等）用完数据后调用 Release，计数归零时归还缓冲池
+//   - 原样转发数据包的组件不调用 Release，引用随数据包转交给下游
+//
+// 被丢弃而未 Release 的缓冲区只是不再复用，由 GC 回收，不影响正确性
+
+const (
+	minPoolClass = 6  // 最小容量 64
+	maxPoolClass = 16 // 最大容量 65536，更大的缓冲区直接分配且不归还
+)
+
+var (
+	samplePools [maxPoolClass + 1]sync.Pool
+	bytePools   [maxPoolClass + 1]sync.Pool
+)
+
+// AudioBuffer 引用计数的音频缓冲区，Samples 与 Payload 只有一个有效
+type AudioBuffer struct {
+	Samples []int16
+	Payload []byte
+	refs    atomic.Int32
+	class   int // 所属的容量等级，-1 表示不归还缓冲池
+}
+
+// poolClass 返回容纳 n 个元素的最小容量等级，超出范围时返回 -1
+func poolClass(n int) int {
+	if n <= 1<<minPoolClass {
+		return minPoolClass
+	}
+	class := bits.Len(uint(n - 1))
+	if class > maxPoolClass {
+		return -1
+	}
+	return class
+}
+
+// NewSampleBuffer 从缓冲池取出长度为 n 的 PCM 采样缓冲区，内容未清零
+func NewSampleBuffer(n int) *AudioBuffer {
+	class := poolClass(n)
+	if class < 0 {
+		b := &AudioBuffer{Samples: make([]int16, n), class: -1}
+		b.refs.Store(1)
+		return b
+	}
+	b, _ := samplePools[class].Get().(*AudioBuffer)
+	if b == nil {
+		b = &AudioBuffer{Samples: make([]int16, 1<<class), class: class}
+	}
+	b.Samples = b.Samples[:n]
+	b.refs.Store(1)
+	return b
+}
+
+// NewByteBuffer 从缓冲池取出长度为 n 的字节缓冲区，内容未清零
+func NewByteBuffer(n int) *AudioBuffer {
+	class := poolClass(n)
+	if class < 0 {
+		b := &AudioBuffer{Payload: make([]byte, n), class: -1}
+		b.refs.Store(1)
+		return b
+	}
+	b, _ := bytePools[class].Get().(*AudioBuffer)
+	if b == nil {
+		b = &AudioBuffer{Payload: make([]byte, 1<<class), class: class}
+	}
+	b.Payload = b.Payload[:n]
+	b.refs.Store(1)
+	return b
+}
+
+// Retain 增加一个引用，nil 上的调用被忽略
+func (b *AudioBuffer) Retain() {
+	if b == nil {
+		return
+	}
+	b.refs.Add(1)
+}
+
+// Release 释放一个引用，计数归零时归还缓冲池，之后不能再访问其数据
+func (b *AudioBuffer) Release() {
+	if b == nil {
+		return
+	}
+	refs := b.refs.Add(-1)
+	if refs < 0 {
+		panic("pipeline: release of unreferenced audio buffer")
+	}
+	if refs > 0 || b.class < 0 {
+		return
+	}
+	// 容量与等级不符说明切片被替换过，不再归还
+	size := 1 << b.class
+	if b.Samples != nil && cap(b.Samples) == size {
+		b.Samples = b.Samples[:size]
+		samplePools[b.class].Put(b)
+	} else if b.Payload != nil && cap(b.Payload) == size {
+		b.Payload = b.Payload[:size]
+		bytePools[b.class].Put(b)
+	}
+}
+
+// NewPooledPCMFrame 用缓冲区中的采样创建 PCM 帧，帧接管调用方持有的引用
+func NewPooledPCMFrame(buf *AudioBuffer, sampleRate, channels int, timestamp time.Duration) AudioFrame {
+	frame := NewPCMFrame(buf.Samples, sampleRate, channels, timestamp)
+	frame.buffer = buf
+	return frame
+}
+
+// NewPooledEncodedFrame 用缓冲区中的数据创建编码帧，帧接管调用方持有的引用
+func NewPooledEncodedFrame(buf *AudioBuffer, format AudioFormat, duration, timestamp time.Duration) AudioFrame {
+	frame := NewEncodedFrame(buf.Payload, format, duration, timestamp)
+	frame.buffer = buf
+	return frame
+}
+
+// Retain 为帧的缓冲区增加一个引用，非池化的帧上调用无效果
+func (f AudioFrame) Retain() {
+	f.buffer.Retain()
+}
+
+// Release 释放帧对缓冲区的引用，非池化的帧上调用无效果
+func (f AudioFrame) Release() {
+	f.buffer.Release()
+}
+
+// RetainPacket 为数据包中的池化音频帧增加一个引用
+func RetainPacket(packet Packet) {
+	if frame, ok := packet.Data.(AudioFrame); ok {
+		frame.Retain()
+	}
+}
+
+// ReleasePacket 释放数据包中的池化音频帧，用于终点组件或丢弃数据包时
+func ReleasePacket(packet Packet) {
+	if frame, ok := packet.Data.(AudioFrame); ok {
+		frame.Release()
+	}
+}
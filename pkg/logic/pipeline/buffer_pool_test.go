@@ -0,0 +1,73 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudioBuffer_RefCount(t *testing.T) {
+	buf := NewSampleBuffer(100)
+	assert.Len(t, buf.Samples, 100)
+	assert.Equal(t, 128, cap(buf.Samples))
+
+	buf.Retain()
+	buf.Release()
+	assert.Equal(t, int32(1), buf.refs.Load())
+	buf.Release()
+	assert.Panics(t, buf.Release)
+
+	// 超出缓冲池范围的缓冲区直接分配
+	large := NewByteBuffer(1<<maxPoolClass + 1)
+	assert.Equal(t, -1, large.class)
+	large.Release()
+
+	// 非池化的帧上调用 Release 无效果
+	NewPCMFrame(make([]int16, 160), 16000, 1, 0).Release()
+}
+
+func TestConvert_NoAlloc(t *testing.T) {
+	samples := []int16{0, 1, -1, 32767, -32768, 258}
+	data := make([]byte, 0, len(samples)*2)
+	out := make([]int16, 0, len(samples))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		data = AppendSamplesToBytes(data[:0], samples)
+		out = AppendBytesToSamples(out[:0], data)
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, samples, out)
+	assert.Equal(t, SamplesToBytes(samples), data)
+	assert.Equal(t, samples, BytesToSamples(data))
+}
+
+func TestTee_RetainsPerBranch(t *testing.T) {
+	tee := NewTee("tee")
+	left := tee.AddBranch(1)
+	right := tee.AddBranch(1)
+
+	buf := NewSampleBuffer(160)
+	tee.broadcast(Packet{Data: NewPooledPCMFrame(buf, 16000, 1, 0)})
+	assert.Equal(t, int32(2), buf.refs.Load())
+
+	// 每个分支用完后各自释放
+	ReleasePacket(<-left)
+	ReleasePacket(<-right)
+	assert.Equal(t, int32(0), buf.refs.Load())
+}
+
+func TestDefaultCoalesce_ReleasesMerged(t *testing.T) {
+	first := NewSampleBuffer(160)
+	second := NewSampleBuffer(160)
+	a := Packet{Data: NewPooledPCMFrame(first, 16000, 1, 0)}
+	b := Packet{Data: NewPooledPCMFrame(second, 16000, 1, 0)}
+
+	merged, ok := DefaultCoalesce(a, b)
+	assert.True(t, ok)
+	assert.Equal(t, int32(0), first.refs.Load())
+	assert.Equal(t, int32(0), second.refs.Load())
+
+	frame := merged.Data.(AudioFrame)
+	assert.Equal(t, int32(1), frame.buffer.refs.Load())
+	assert.Len(t, frame.Samples, 320)
+}
@@ -0,0 +1,68 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock_Timer(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewFakeClock(start)
+	timer := clock.NewTimer(time.Second)
+
+	clock.Advance(999 * time.Millisecond)
+	assert.Empty(t, timer.C())
+
+	clock.Advance(time.Millisecond)
+	assert.Equal(t, start.Add(time.Second), <-timer.C())
+	assert.Equal(t, time.Second, clock.Since(start))
+	assert.False(t, timer.Stop())
+
+	// 重置后按新的到期时间触发，停止后不再触发
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Stop())
+	clock.Advance(time.Minute)
+	assert.Empty(t, timer.C())
+}
+
+func TestFakeClock_Ticker(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	ticker := clock.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	var ticks int
+	for i := 0; i < 5; i++ {
+		clock.Advance(100 * time.Millisecond)
+		select {
+		case <-ticker.C():
+			ticks++
+		default:
+		}
+	}
+	assert.Equal(t, 5, ticks)
+
+	// 与真实 Ticker 一样，未及时读取的触发被丢弃
+	clock.Advance(time.Second)
+	assert.Len(t, ticker.C(), 1)
+}
+
+func TestFakeClock_Sleep(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	woke := make(chan struct{})
+	go func() {
+		clock.Sleep(20 * time.Millisecond)
+		close(woke)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Millisecond)
+	select {
+	case <-woke:
+		t.Fatal("woke before deadline")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Advance(10 * time.Millisecond)
+	<-woke
+}
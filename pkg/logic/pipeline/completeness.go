@@ -0,0 +1,135 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// CompletenessScorer 评估用户的话是否已经说完，TurnManager 据此调整静音超时
+// 返回 0 到 1 之间的分数：0 表示明显没说完（如停在“我想订一张去……”），1 表示已经说完（如简短的回答）
+type CompletenessScorer interface {
+	Score(ctx context.Context, text string) (float64, error)
+}
+
+// ScorerFactory 根据参数创建完整度评估器
+type ScorerFactory func(params Params) (CompletenessScorer, error)
+
+var (
+	scorersMu sync.RWMutex
+	scorers   = make(map[string]ScorerFactory)
+)
+
+// RegisterScorer 登记完整度评估器类型，重复登记或构造函数为空时 panic
+func RegisterScorer(name string, factory ScorerFactory) {
+	scorersMu.Lock()
+	defer scorersMu.Unlock()
+	if factory == nil {
+		panic("pipeline: RegisterScorer factory is nil for " + name)
+	}
+	if _, dup := scorers[name]; dup {
+		panic("pipeline: RegisterScorer called twice for " + name)
+	}
+	scorers[name] = factory
+}
+
+// NewScorer 创建指定类型的完整度评估器
+func NewScorer(name string, params Params) (CompletenessScorer, error) {
+	scorersMu.RLock()
+	factory, ok := scorers[name]
+	scorersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown completeness scorer %q", name)
+	}
+	if params == nil {
+		params = Params{}
+	}
+	return factory(params)
+}
+
+func init() {
+	RegisterScorer("heuristic", func(Params) (CompletenessScorer, error) {
+		return DefaultHeuristicScorer(), nil
+	})
+}
+
+// HeuristicScorer 按句尾的词与标点在本地评估完整度，不产生额外请求
+type HeuristicScorer struct {
+	Complete     []string // 整句为这些简短回答时视为已说完
+	Incomplete   []string // 以这些词（连词、介词、助词等）结尾时视为没说完
+	Continuation []string // 以这些标点结尾时视为还要继续说
+	Terminal     []string // 以这些标点结尾时视为大概率已说完
+}
+
+// DefaultHeuristicScorer 返回中英文的默认规则
+func DefaultHeuristicScorer() *HeuristicScorer {
+	return &HeuristicScorer{
+		Complete: []string{
+			"好", "好的", "是", "是的", "对", "对的", "不", "不是", "不用", "没有", "可以", "行", "谢谢", "没问题",
+			"yes", "no", "yeah", "nope", "ok", "okay", "sure", "thanks", "thank you", "no thanks",
+		},
+		Incomplete: []string{
+			"和", "跟", "与", "或者", "还有", "然后", "因为", "所以", "但是", "如果", "就是", "那个", "这个", "一个", "到", "把", "给",
+			"to", "and", "or", "but", "the", "a", "an", "of", "for", "with", "from",
+			"because", "if", "my", "your", "um", "uh",
+		},
+		Continuation: []string{"，", ",", "、", "…", "...", "——", "-"},
+		Terminal:     []string{"。", "？", "！", ".", "?", "!"},
+	}
+}
+
+// Score 实现 CompletenessScorer
+func (h *HeuristicScorer) Score(_ context.Context, text string) (float64, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return 0, nil
+	}
+	for _, mark := range h.Continuation {
+		if strings.HasSuffix(text, mark) {
+			return 0.2, nil
+		}
+	}
+
+	words := strings.TrimRightFunc(text, func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSpace(r) })
+	for _, w := range h.Complete {
+		if words == w {
+			return 1, nil
+		}
+	}
+	for _, w := range h.Incomplete {
+		if endsWithWord(words, w) {
+			return 0.1, nil
+		}
+	}
+	for _, mark := range h.Terminal {
+		if strings.HasSuffix(text, mark) {
+			return 0.8, nil
+		}
+	}
+	return 0.5, nil
+}
+
+// endsWithWord 判断 text 是否以 word 结尾，英文单词需在词边界处
+func endsWithWord(text, word string) bool {
+	if !strings.HasSuffix(text, word) {
+		return false
+	}
+	rest := strings.TrimSuffix(text, word)
+	if rest == "" || !isLatinWord(word) {
+		return true
+	}
+	last := []rune(rest)[len([]rune(rest))-1]
+	return !unicode.IsLetter(last) && !unicode.IsDigit(last)
+}
+
+// isLatinWord 判断词是否只由 ASCII 字母组成
+func isLatinWord(word string) bool {
+	for _, r := range word {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeuristicScorer(t *testing.T) {
+	scorer := DefaultHeuristicScorer()
+	cases := map[string]float64{
+		"":                              0,
+		"好的。":                           1,
+		"No thanks.":                    1,
+		"我想订一张机票，":                      0.2,
+		"I want to book a flight to...": 0.2,
+		"I want to book a flight to.":   0.1,
+		"我想订一张机票然后":                     0.1,
+		"帮我订一张去北京的机票。":                  0.8,
+		"Let me think about it":         0.5,
+		"I've been to":                  0.1,
+		"Is it onto":                    0.5,
+	}
+	for text, want := range cases {
+		score, err := scorer.Score(context.Background(), text)
+		assert.NoError(t, err)
+		assert.Equal(t, want, score, text)
+	}
+}
+
+func TestTurnManager_SemanticEndpointing(t *testing.T) {
+	bus := NewEventBus()
+	sub := bus.Subscribe(10, EventUtteranceScored)
+	tm, clock, processed := startClockedTurnManager(t, func(tm *TurnManager) {
+		tm.SetScorer(DefaultHeuristicScorer())
+		tm.SetEventBus(bus)
+	})
+
+	// 停在半句话时等待更久，结束标点不再立即结束句子
+	tm.Process(Packet{Data: "我想订一张机票，"})
+	processed(2)
+	assert.Equal(t, 2460*time.Millisecond, recvEvent(t, sub).Data.(CompletenessEvent).SilenceTimeout)
+	clock.Advance(2 * time.Second)
+
+	tm.Process(Packet{Data: "去北京。"})
+	processed(4)
+	event := recvEvent(t, sub).Data.(CompletenessEvent)
+	assert.Equal(t, "我想订一张机票，去北京。", event.Text)
+	assert.Equal(t, 840*time.Millisecond, event.SilenceTimeout)
+	assert.Empty(t, tm.GetOutputChan())
+
+	clock.Advance(840 * time.Millisecond)
+	assert.Equal(t, "我想订一张机票，去北京。", recv(t, tm.GetOutputChan()).Data)
+
+	// 简短的回答很快结束
+	tm.Process(Packet{Data: "好的"})
+	processed(6)
+	assert.Equal(t, 1.0, recvEvent(t, sub).Data.(CompletenessEvent).Score)
+	clock.Advance(300 * time.Millisecond)
+	assert.Equal(t, "好的", recv(t, tm.GetOutputChan()).Data)
+}
+
+// scorerFunc 将函数适配为 CompletenessScorer
+type scorerFunc func(ctx context.Context, text string) (float64, error)
+
+func (f scorerFunc) Score(ctx context.Context, text string) (float64, error) {
+	return f(ctx, text)
+}
+
+func TestTurnManager_ScoresOffProcessLoop(t *testing.T) {
+	bus := NewEventBus()
+	sub := bus.Subscribe(10, EventUtteranceScored)
+	release := make(chan struct{})
+	tm, clock, processed := startClockedTurnManager(t, func(tm *TurnManager) {
+		tm.SetScorer(scorerFunc(func(ctx context.Context, text string) (float64, error) {
+			if text == "我想订" {
+				// 模拟缓慢的远程评估，被新的识别结果取消
+				select {
+				case <-release:
+				case <-ctx.Done():
+				}
+				return 1, nil
+			}
+			return 0, nil
+		}))
+		tm.SetEventBus(bus)
+	})
+	defer close(release)
+
+	// 评估期间处理循环照常处理后续数据包
+	tm.Process(Packet{Data: "我想订"})
+	processed(2)
+	tm.Process(Packet{Data: "一张机票"})
+	processed(4)
+
+	// 只应用最新缓存的评估结果，过期的结果被丢弃
+	event := recvEvent(t, sub).Data.(CompletenessEvent)
+	assert.Equal(t, "我想订一张机票", event.Text)
+	assert.Equal(t, 3*time.Second, event.SilenceTimeout)
+	assert.Empty(t, sub.Events())
+
+	clock.Advance(3 * time.Second)
+	assert.Equal(t, "我想订一张机票", recv(t, tm.GetOutputChan()).Data)
+}
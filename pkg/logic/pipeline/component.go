@@ -1,9 +1,11 @@
 package pipeline
 
 import (
+	"context"
 	"fmt"
 	"streamlink/pkg/logger"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,21 +16,24 @@ type TurnMetrics struct {
 
 // Packet 定义了通用的数据包结构
 type Packet struct {
-	Data           interface{} // 可以是 []int16, string, *rtp.Packet 等
-	Seq            int
-	Src            interface{}
-	TurnSeq        int
-	TurnMetricStat map[string]TurnMetrics
-	TurnMetricKeys []string
-	Command        PacketCommand // 用于特殊指令，如打断
+	Data    interface{} // 音频为 AudioFrame，文本为 string
+	Seq     int
+	Src     interface{}
+	TurnSeq int
+	Trace   *TurnTrace    // 所属轮次的延迟追踪，由 ASR 创建并随回复传递到输出端
+	Command PacketCommand // 用于特殊指令，如打断
 }
 
 // PacketCommand 定义了数据包的特殊指令
 type PacketCommand int
 
 const (
-	PacketCommandNone      PacketCommand = iota // 普通数据包
-	PacketCommandInterrupt                      // 打断指令
+	PacketCommandNone        PacketCommand = iota // 普通数据包
+	PacketCommandInterrupt                        // 打断指令
+	PacketCommandEndOfStream                      // 流结束：冲刷缓存并完成输出，转发后组件退出
+	PacketCommandFlush                            // 冲刷：输出内部缓存的数据，流继续
+	PacketCommandPause                            // 暂停：数据包暂存，直到收到 Resume
+	PacketCommandResume                           // 恢复：按顺序处理暂停期间暂存的数据包
 )
 
 // GenInterruptPacket 生成一个打断指令包
@@ -79,15 +84,17 @@ func (s ComponentState) String() string {
 
 // ComponentHealth 定义组件的健康信息
 type ComponentHealth struct {
-	State           ComponentState `json:"state"`
-	LastError       error          `json:"last_error,omitempty"`
-	LastErrorTime   time.Time      `json:"last_error_time,omitempty"`
-	ProcessedCount  int64          `json:"processed_count"`
-	DroppedCount    int64          `json:"dropped_count"`
-	InputQueueSize  int            `json:"input_queue_size"`
-	OutputQueueSize int            `json:"output_queue_size"`
-	StartTime       time.Time      `json:"start_time"`
-	LastUpdateTime  time.Time      `json:"last_update_time"`
+	State           ComponentState    `json:"state"`
+	LastError       error             `json:"last_error,omitempty"`
+	LastErrorTime   time.Time         `json:"last_error_time,omitempty"`
+	ErrorCount      int64             `json:"error_count"`
+	ProcessedCount  int64             `json:"processed_count"`
+	DroppedCount    int64             `json:"dropped_count"`
+	Backpressure    BackpressureStats `json:"backpressure"`
+	InputQueueSize  int               `json:"input_queue_size"`
+	OutputQueueSize int               `json:"output_queue_size"`
+	StartTime       time.Time         `json:"start_time"`
+	LastUpdateTime  time.Time         `json:"last_update_time"`
 }
 
 // Component 接口定义了组件的基本行为
@@ -125,21 +132,77 @@ type BaseComponent struct {
 	ignoreTurn   bool
 	useInterrupt bool
 
+	// 组件声明的输入/输出音频格式，零值表示不限制
+	inputFormat  AudioFormat
+	outputFormat AudioFormat
+
+	// 优先传递打断指令的控制通道，未连接时为 nil
+	controlChan    chan Packet
+	controlOutChan chan Packet
+
+	// 输出边的背压策略，sendLock 保证对输出 channel 的写入串行
+	backpressure     BackpressurePolicy
+	backpressureLock sync.RWMutex
+	sendLock         sync.Mutex
+	outputClosed     bool // 输出已关闭，之后的写入直接丢弃，由 sendLock 保护
+
+	// 生命周期相关字段
+	started      atomic.Bool
+	stopOnce     sync.Once
+	finishOnce   sync.Once
+	done         chan struct{}   // 处理循环及其派生协程全部退出后关闭
+	wg           sync.WaitGroup  // 通过 Go 启动的协程
+	ctx          context.Context // 组件停止时取消
+	cancel       context.CancelFunc
+	drainHandler func()      // 输入排空后、关闭输出前调用
+	flushHandler func()      // 收到 Flush 或流结束时调用，输出内部缓存的数据
+	closeOutputs func()      // 关闭额外的输出 channel，如 Tee 的分支
+	keepOutput   atomic.Bool // 停止时保留输出 channel，由热替换的新组件接管
+
+	// 流控制相关字段，只在处理循环中访问
+	paused      bool        // 收到 Pause 后暂存数据包
+	pending     []Packet    // 暂停期间暂存的数据包
+	endOfStream *Packet     // 待转发的流结束指令，在派生协程退出后、关闭输出前转发
+	timer       Timer       // 组件定时器，见 SetTimer
+	timerFunc   func()      // 定时器到期时在处理循环中调用
+	posted      chan func() // 派生协程交回处理循环执行的回调，见 Post
+
 	// 健康监控相关字段
 	health     ComponentHealth
 	healthLock sync.RWMutex
 
+	// 延迟直方图，按指标名索引
+	latencies   map[string]*Histogram
+	latencyLock sync.Mutex
+
+	// 轮次追踪完成后的保存位置
+	traceRecorder *TraceRecorder
+	traceLock     sync.Mutex
+
+	// 时间来源，未设置时使用系统时间
+	clock     Clock
+	clockLock sync.RWMutex
+
+	// 会话事件总线，未设置时不发布事件
+	events atomic.Pointer[EventBus]
+
 	// 指令处理器映射
-	commandHandlers map[PacketCommand]func(Packet)
-	handlersLock    sync.RWMutex
+	commandHandlers       map[PacketCommand]func(Packet)
+	defaultCommandHandler func(Packet) // 未注册处理器的指令交由它处理
+	handlersLock          sync.RWMutex
 }
 
 // NewBaseComponent 创建一个新的基础组件
 func NewBaseComponent(name string, bufferSize int) *BaseComponent {
 	now := time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
 	return &BaseComponent{
 		outputChan: make(chan Packet, bufferSize),
 		stopCh:     make(chan struct{}),
+		done:       make(chan struct{}),
+		posted:     make(chan func(), postedBufferSize),
+		ctx:        ctx,
+		cancel:     cancel,
 		name:       name,
 		health: ComponentHealth{
 			State:          ComponentStateInitial,
@@ -154,6 +217,33 @@ func NewBaseComponent(name string, bufferSize int) *BaseComponent {
 	}
 }
 
+// GetID 实现 Component 接口，默认使用组件名称
+func (b *BaseComponent) GetID() interface{} {
+	return b.name
+}
+
+// Process 实现 Component 接口，将数据包放入输入 channel，channel 已满时丢弃
+func (b *BaseComponent) Process(packet Packet) {
+	select {
+	case b.inputChan <- packet:
+	default:
+		logger.Error("**%s** Input channel full, dropping packet", b.name)
+	}
+}
+
+// SetOutput 实现 Component 接口，新建输出 channel 并将其中的数据包交给回调，用于单独使用组件
+func (b *BaseComponent) SetOutput(output func(Packet)) {
+	outChan := make(chan Packet, 100)
+	b.SetOutputChan(outChan)
+	go func() {
+		for packet := range outChan {
+			if output != nil {
+				output(packet)
+			}
+		}
+	}()
+}
+
 func (b *BaseComponent) GetInputChan() chan Packet {
 	return b.inputChan
 }
@@ -163,12 +253,119 @@ func (b *BaseComponent) SetOutputChan(ch chan Packet) {
 }
 
 func (b *BaseComponent) Start() error {
+	if b.started.Swap(true) {
+		return nil
+	}
 	go b.processLoop()
 	return nil
 }
 
+// Stop 立即停止组件，可重复调用
+// 处理循环退出后会关闭输出 channel，下游组件据此排空并依次退出
 func (b *BaseComponent) Stop() {
-	close(b.stopCh)
+	b.stopOnce.Do(func() {
+		close(b.stopCh)
+		b.cancel()
+		if !b.started.Load() {
+			// 未启动处理循环的组件（如音频源）直接收尾
+			go b.finish()
+		}
+	})
+}
+
+// Wait 等待组件的处理循环及其派生协程退出
+func (b *BaseComponent) Wait(ctx context.Context) error {
+	if !b.started.Load() && !b.isStopped() {
+		return nil
+	}
+	select {
+	case <-b.done:
+		return nil
+	default:
+	}
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done 返回组件完全退出后关闭的 channel
+func (b *BaseComponent) Done() <-chan struct{} {
+	return b.done
+}
+
+// Context 返回组件停止时取消的 context，用于外部请求
+func (b *BaseComponent) Context() context.Context {
+	return b.ctx
+}
+
+// Go 启动一个受组件管理的协程，组件退出前会等待其结束
+// 协程需在处理完剩余工作后自行返回，并在 GetStopCh 关闭时及时返回
+func (b *BaseComponent) Go(fn func()) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		fn()
+	}()
+}
+
+// SetDrainHandler 设置输入排空后的回调，用于冲刷组件内部缓存的数据
+func (b *BaseComponent) SetDrainHandler(handler func()) {
+	b.drainHandler = handler
+}
+
+// CloseOutput 关闭输出 channel，之后的写入都会被丢弃
+func (b *BaseComponent) CloseOutput() {
+	b.sendLock.Lock()
+	defer b.sendLock.Unlock()
+	if b.outputClosed {
+		return
+	}
+	b.outputClosed = true
+	if b.outputChan != nil {
+		close(b.outputChan)
+	}
+	if b.closeOutputs != nil {
+		b.closeOutputs()
+	}
+}
+
+func (b *BaseComponent) isStopped() bool {
+	select {
+	case <-b.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// finish 等待派生协程退出后关闭输出，只执行一次
+func (b *BaseComponent) finish() {
+	b.finishOnce.Do(func() {
+		b.wg.Wait()
+		if b.endOfStream != nil {
+			b.forwardCommand(*b.endOfStream)
+		}
+		if b.keepOutput.Load() {
+			// 输出 channel 已交给新组件，只阻止本组件继续写入
+			b.sendLock.Lock()
+			b.outputClosed = true
+			b.sendLock.Unlock()
+		} else {
+			b.CloseOutput()
+		}
+		b.healthLock.Lock()
+		b.health.State = ComponentStateStopped
+		b.healthLock.Unlock()
+		close(b.done)
+	})
+}
+
+// keepOutputOnStop 标记组件停止时不关闭输出 channel，用于热替换
+func (b *BaseComponent) keepOutputOnStop() {
+	b.keepOutput.Store(true)
 }
 
 // RegisterCommandHandler 注册指令处理函数
@@ -185,48 +382,93 @@ func (b *BaseComponent) UnregisterCommandHandler(cmd PacketCommand) {
 	delete(b.commandHandlers, cmd)
 }
 
+// SetDefaultCommandHandler 设置未注册指令的默认处理函数
+func (b *BaseComponent) SetDefaultCommandHandler(handler func(Packet)) {
+	b.handlersLock.Lock()
+	defer b.handlersLock.Unlock()
+	b.defaultCommandHandler = handler
+}
+
 // processLoop 是组件的主处理循环
 func (b *BaseComponent) processLoop() {
 	// 更新状态为运行中
 	b.healthLock.Lock()
 	b.health.State = ComponentStateRunning
 	b.healthLock.Unlock()
+	defer b.finish()
+	defer b.StopTimer()
 
 	for {
+		// 优先响应停止，停止后不再取出排队的数据包，便于热替换时交给新组件
+		if b.isStopped() {
+			return
+		}
+		// 控制通道中的指令先于排队的数据处理
+		select {
+		case packet := <-b.controlChan:
+			if b.handleControl(packet) {
+				return
+			}
+			continue
+		default:
+		}
 		select {
 		case <-b.stopCh:
-			b.healthLock.Lock()
-			b.health.State = ComponentStateStopped
-			b.healthLock.Unlock()
 			return
-		case packet := <-b.inputChan:
-			b.healthLock.Lock()
-			b.health.ProcessedCount++
-			b.healthLock.Unlock()
-
-			// handle command
-			if packet.Command != PacketCommandNone {
-				b.HandleCommandPacket(packet)
-				continue
+		case packet := <-b.controlChan:
+			if b.handleControl(packet) {
+				return
 			}
-
-			// if b.GetName() != "Resampler_48000Hz_2Ch->16000Hz_1Ch" && b.GetName() != "TencentASR" && b.GetName() != "OpusDecoder" {
-			// 	log.Printf("**%s** Process packet. turn_seq=%d", b.GetName(), packet.TurnSeq)
-			// }
-			// handle data
-			if !b.ignoreTurn && packet.TurnSeq < b.curTurnSeq {
-				logger.Error("**%s** Drop packet. packet turn_seq=%d, cur_turn_seq=%d", b.GetName(), packet.TurnSeq, b.curTurnSeq)
-				// drop current packet
-				b.UpdateDroppedStatus()
-				continue
+		case <-b.timerC():
+			b.fireTimer()
+		case fn := <-b.posted:
+			fn()
+		case packet, ok := <-b.inputChan:
+			if !ok {
+				// 上游已关闭且缓冲中的数据包均已处理
+				b.endStream()
+				return
 			}
-			if b.process != nil {
-				b.process(packet)
+			if b.handleInput(packet) {
+				return
 			}
 		}
 	}
 }
 
+// handleInput 处理从输入 channel 取出的数据包，返回 true 表示流已结束、处理循环应退出
+func (b *BaseComponent) handleInput(packet Packet) bool {
+	b.healthLock.Lock()
+	b.health.ProcessedCount++
+	b.healthLock.Unlock()
+
+	// handle command
+	if packet.Command != PacketCommandNone {
+		return b.dispatchCommand(packet)
+	}
+
+	if b.paused {
+		b.holdPacket(packet)
+		return false
+	}
+	b.processData(packet)
+	return false
+}
+
+// processData 处理数据包，丢弃过期轮次的数据
+func (b *BaseComponent) processData(packet Packet) {
+	// handle data
+	if !b.ignoreTurn && packet.TurnSeq < b.curTurnSeq {
+		logger.Error("**%s** Drop packet. packet turn_seq=%d, cur_turn_seq=%d", b.GetName(), packet.TurnSeq, b.curTurnSeq)
+		// drop current packet
+		b.UpdateDroppedStatus()
+		return
+	}
+	if b.process != nil {
+		b.process(packet)
+	}
+}
+
 func (b *BaseComponent) GetUseInterrupt() bool {
 	return b.useInterrupt
 }
@@ -289,12 +531,13 @@ func (b *BaseComponent) GetHealth() ComponentHealth {
 	b.healthLock.RLock()
 	defer b.healthLock.RUnlock()
 
-	// 更新队列大小
-	b.health.InputQueueSize = len(b.inputChan)
-	b.health.OutputQueueSize = len(b.outputChan)
-	b.health.LastUpdateTime = time.Now()
+	// 在副本上填充队列大小，并发的检查只持有读锁
+	health := b.health
+	health.InputQueueSize = len(b.inputChan)
+	health.OutputQueueSize = len(b.outputChan)
+	health.LastUpdateTime = b.Clock().Now()
 
-	return b.health
+	return health
 }
 
 // UpdateHealth 实现 Component 接口
@@ -304,46 +547,80 @@ func (b *BaseComponent) UpdateHealth(health ComponentHealth) {
 	b.health = health
 }
 
-// ForwardPacket 转发数据包到输出通道
+// ForwardPacket 转发数据包到输出通道，打断指令在连接了控制通道时经由控制通道转发
 func (b *BaseComponent) ForwardPacket(packet Packet) {
-	// if b.GetName() != "Resampler_48000Hz_2Ch->16000Hz_1Ch" {
-	// 	log.Printf("**%s** Forward packet. turn_seq=%d", b.GetName(), packet.TurnSeq)
-	// }
 	outChan := b.GetOutputChan()
+	if isControlCommand(packet.Command) && b.controlOutChan != nil {
+		outChan = b.controlOutChan
+	}
 	if outChan != nil {
-		select {
-		case outChan <- packet:
-		default:
-			logger.Error("%s: output channel full, dropping packet", b.name)
-			b.UpdateDroppedStatus()
-		}
+		b.DeliverPacket(outChan, packet, b.GetBackpressure())
 	}
 }
 
 // SendPacket 发送新的数据包到输出通道
 func (b *BaseComponent) SendPacket(data interface{}, src interface{}) {
-	outChan := b.GetOutputChan()
-	if outChan != nil {
-		select {
-		case outChan <- Packet{
-			Data:    data,
-			Seq:     b.seq,
-			Src:     src,
-			TurnSeq: b.curTurnSeq,
-		}:
-		default:
-			logger.Error("%s: output channel full, dropping packet", b.name)
-			b.UpdateDroppedStatus()
-		}
-	}
+	b.ForwardPacket(Packet{
+		Data:    data,
+		Seq:     b.seq,
+		Src:     src,
+		TurnSeq: b.curTurnSeq,
+	})
 	b.IncrSeq()
 }
 
+// SetBackpressure 设置输出边的背压策略
+func (b *BaseComponent) SetBackpressure(policy BackpressurePolicy) {
+	b.backpressureLock.Lock()
+	defer b.backpressureLock.Unlock()
+	b.backpressure = policy
+}
+
+// GetBackpressure 获取输出边的背压策略
+func (b *BaseComponent) GetBackpressure() BackpressurePolicy {
+	b.backpressureLock.RLock()
+	defer b.backpressureLock.RUnlock()
+	return b.backpressure
+}
+
+// DeliverPacket 按背压策略将数据包写入 ch，并将触发情况计入健康统计
+func (b *BaseComponent) DeliverPacket(ch chan Packet, packet Packet, policy BackpressurePolicy) bool {
+	var stats BackpressureStats
+	b.sendLock.Lock()
+	if b.outputClosed {
+		b.sendLock.Unlock()
+		ReleasePacket(packet)
+		return false
+	}
+	ok := deliver(ch, packet, policy, b.Clock(), b.stopCh, &stats)
+	b.sendLock.Unlock()
+
+	if stats == (BackpressureStats{}) {
+		return ok
+	}
+	if stats.Dropped() > 0 {
+		logger.Error("%s: output channel full, dropping packet (policy=%s)", b.name, policy.Mode)
+	}
+
+	b.healthLock.Lock()
+	defer b.healthLock.Unlock()
+	b.health.DroppedCount += stats.Dropped()
+	b.health.Backpressure.DroppedNewest += stats.DroppedNewest
+	b.health.Backpressure.DroppedOldest += stats.DroppedOldest
+	b.health.Backpressure.Blocked += stats.Blocked
+	b.health.Backpressure.BlockTimeouts += stats.BlockTimeouts
+	b.health.Backpressure.Coalesced += stats.Coalesced
+	return ok
+}
+
 // HandleCommandPacket 处理指令包
 func (b *BaseComponent) HandleCommandPacket(packet Packet) bool {
 	// handle command
 	b.handlersLock.RLock()
 	handler, exists := b.commandHandlers[packet.Command]
+	if !exists && b.defaultCommandHandler != nil {
+		handler, exists = b.defaultCommandHandler, true
+	}
 	b.handlersLock.RUnlock()
 	if exists {
 		handler(packet)
@@ -356,9 +633,36 @@ func (b *BaseComponent) HandleCommandPacket(packet Packet) bool {
 // UpdateErrorStatus 更新错误状态
 func (b *BaseComponent) UpdateErrorStatus(err error) {
 	b.healthLock.Lock()
-	defer b.healthLock.Unlock()
 	b.health.LastError = err
-	b.health.LastErrorTime = time.Now()
+	b.health.LastErrorTime = b.Clock().Now()
+	b.health.ErrorCount++
+	b.healthLock.Unlock()
+	b.Publish(EventError, b.curTurnSeq, ErrorEvent{Error: errorString(err)})
+}
+
+// ReportFailure 记录错误并将组件置为 Error 状态，由 Supervisor 决定是否重启
+func (b *BaseComponent) ReportFailure(err error) {
+	b.healthLock.Lock()
+	b.health.State = ComponentStateError
+	b.health.LastError = err
+	b.health.LastErrorTime = b.Clock().Now()
+	b.health.ErrorCount++
+	b.healthLock.Unlock()
+	b.Publish(EventError, b.curTurnSeq, ErrorEvent{Error: errorString(err), Fatal: true})
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// SetState 设置组件状态
+func (b *BaseComponent) SetState(state ComponentState) {
+	b.healthLock.Lock()
+	defer b.healthLock.Unlock()
+	b.health.State = state
 }
 
 // UpdateDroppedStatus 更新丢包状态
@@ -368,6 +672,52 @@ func (b *BaseComponent) UpdateDroppedStatus() {
 	b.health.DroppedCount++
 }
 
+// SetInputFormat 声明组件接受的音频格式
+func (b *BaseComponent) SetInputFormat(format AudioFormat) {
+	b.inputFormat = format
+}
+
+// GetInputFormat 获取组件接受的音频格式
+func (b *BaseComponent) GetInputFormat() AudioFormat {
+	return b.inputFormat
+}
+
+// SetOutputFormat 声明组件输出的音频格式
+func (b *BaseComponent) SetOutputFormat(format AudioFormat) {
+	b.outputFormat = format
+}
+
+// GetOutputFormat 获取组件输出的音频格式
+func (b *BaseComponent) GetOutputFormat() AudioFormat {
+	return b.outputFormat
+}
+
+// ExpectAudioFrame 从数据包中取出音频帧，并按声明的输入格式进行校验
+// 校验失败时记录错误并返回 false，调用方应丢弃该数据包
+func (b *BaseComponent) ExpectAudioFrame(packet Packet) (AudioFrame, bool) {
+	frame, ok := packet.Data.(AudioFrame)
+	if !ok {
+		b.HandleUnsupportedData(packet.Data)
+		return AudioFrame{}, false
+	}
+	if err := frame.Validate(); err != nil {
+		b.handleInvalidFrame(fmt.Errorf("%s: invalid audio frame: %v", b.name, err))
+		return AudioFrame{}, false
+	}
+	if !b.inputFormat.Matches(frame.AudioFormat) {
+		b.handleInvalidFrame(fmt.Errorf("%s: audio format mismatch: expect %s, got %s",
+			b.name, b.inputFormat, frame.AudioFormat))
+		return AudioFrame{}, false
+	}
+	return frame, true
+}
+
+func (b *BaseComponent) handleInvalidFrame(err error) {
+	logger.Error("%v", err)
+	b.UpdateErrorStatus(err)
+	b.UpdateDroppedStatus()
+}
+
 // HandleUnsupportedData 处理不支持的数据类型
 func (b *BaseComponent) HandleUnsupportedData(data interface{}) {
 	err := fmt.Errorf("%s: unsupported data type: %T", b.name, data)
@@ -394,68 +744,48 @@ func (b *BaseComponent) Connect(next Component) Component {
 		next.(interface{ GetName() string }).GetName(),
 	)
 
+	if err := CheckFormatCompatible(b, next); err != nil {
+		logger.Warn("Connect component %s: %v", b.GetName(), err)
+	}
+
 	next.SetInputChan(b.GetOutputChan())
+	linkControl(b, next)
 	return next
 }
 
-// ComponentAdapter 用于将现有的基于函数调用的组件适配到新的基于 channel 的接口
-type ComponentAdapter struct {
-	*BaseComponent
-	component Component    // 被适配的原始组件
-	output    func(Packet) // 原始组件的输出函数
+// FormatDeclarer 由声明了输入/输出音频格式的组件实现
+type FormatDeclarer interface {
+	GetInputFormat() AudioFormat
+	GetOutputFormat() AudioFormat
 }
 
-// NewComponentAdapter 创建一个新的组件适配器
-func NewComponentAdapter(component Component, bufferSize int) *ComponentAdapter {
-	adapter := &ComponentAdapter{
-		BaseComponent: NewBaseComponent("", bufferSize),
-		component:     component,
+// CheckFormatCompatible 检查 from 的输出格式是否满足 next 的输入要求
+// 任意一方未声明格式时视为兼容
+func CheckFormatCompatible(from, next interface{}) error {
+	out, ok := from.(FormatDeclarer)
+	if !ok {
+		return nil
 	}
-
-	// 设置适配器的处理函数
-	adapter.process = adapter.handlePacket
-
-	// 保存原始组件的输出函数
-	component.SetOutput(func(p Packet) {
-		if adapter.outputChan != nil {
-			select {
-			case adapter.outputChan <- p:
-			default:
-				logger.Error("ComponentAdapter: output channel full, dropping packet")
-			}
-		}
-	})
-
-	return adapter
-}
-
-// handlePacket 处理输入的数据包
-func (a *ComponentAdapter) handlePacket(packet Packet) {
-	// 调用原始组件的处理方法
-	a.component.Process(packet)
-}
-
-// GetID 代理到原始组件的 GetID 方法
-func (a *ComponentAdapter) GetID() interface{} {
-	return a.component.GetID()
-}
-
-// WrapExistingComponent 辅助函数，用于包装现有组件
-func WrapExistingComponent(component Component) Component {
-	return NewComponentAdapter(component, 100)
-}
-
-// Process 实现 Component 接口
-func (a *ComponentAdapter) Process(packet Packet) {
-	// 将数据包发送到输入通道
-	select {
-	case a.inputChan <- packet:
-	default:
-		logger.Error("ComponentAdapter: input channel full, dropping packet")
+	in, ok := next.(FormatDeclarer)
+	if !ok {
+		return nil
 	}
+	outFormat := out.GetOutputFormat()
+	inFormat := in.GetInputFormat()
+	if outFormat.IsZero() || inFormat.IsZero() {
+		return nil
+	}
+	if !inFormat.Matches(outFormat) {
+		return fmt.Errorf("audio format mismatch: %s outputs %s, %s expects %s",
+			componentName(from), outFormat, componentName(next), inFormat)
+	}
+	return nil
 }
 
-// SetOutput 实现 Component 接口
-func (a *ComponentAdapter) SetOutput(output func(Packet)) {
-	a.output = output
+// componentName 获取组件名称，未实现 GetName 时返回类型名
+func componentName(c interface{}) string {
+	if named, ok := c.(interface{ GetName() string }); ok {
+		return named.GetName()
+	}
+	return fmt.Sprintf("%T", c)
 }
@@ -0,0 +1,134 @@
+package pipeline
+
+import "streamlink/pkg/logger"
+
+// 控制通道：打断指令不经过数据 channel，而是沿每条边上独立的优先通道传递
+//
+// 处理循环总是先处理控制通道中的指令，因此打断不必排在大量待播放的音频之后。
+// 收到打断时，按轮次过滤的组件会清除输入队列头部被打断轮次的数据。
+// Flush、EndOfStream 等指令需要与数据保持先后顺序，仍经由数据 channel 传递。
+// 上下游任一方不支持控制通道时，打断指令退回数据 channel 传递
+
+const controlBufferSize = 16
+
+// ControlLane 由支持优先控制通道的组件实现
+type ControlLane interface {
+	GetControlChan() chan Packet      // 输入控制通道
+	SetControlChan(ch chan Packet)    // 设置输入控制通道
+	GetControlOutChan() chan Packet   // 输出控制通道，未连接时为 nil
+	SetControlOutChan(ch chan Packet) // 设置输出控制通道
+}
+
+// isControlCommand 判断指令是否经由控制通道传递
+func isControlCommand(cmd PacketCommand) bool {
+	return cmd == PacketCommandInterrupt
+}
+
+// GetControlChan 获取输入控制通道
+func (b *BaseComponent) GetControlChan() chan Packet {
+	return b.controlChan
+}
+
+// SetControlChan 设置输入控制通道
+func (b *BaseComponent) SetControlChan(ch chan Packet) {
+	b.controlChan = ch
+}
+
+// GetControlOutChan 获取输出控制通道
+func (b *BaseComponent) GetControlOutChan() chan Packet {
+	return b.controlOutChan
+}
+
+// SetControlOutChan 设置输出控制通道
+func (b *BaseComponent) SetControlOutChan(ch chan Packet) {
+	b.controlOutChan = ch
+}
+
+// controlOutput 返回 c 的输出控制通道，首次连接时创建，c 不支持控制通道时返回 nil
+func controlOutput(c interface{}) chan Packet {
+	lane, ok := c.(ControlLane)
+	if !ok {
+		return nil
+	}
+	ch := lane.GetControlOutChan()
+	if ch == nil {
+		ch = make(chan Packet, controlBufferSize)
+		lane.SetControlOutChan(ch)
+	}
+	return ch
+}
+
+// linkControl 将 from 的输出控制通道连接为 to 的输入控制通道，任一方不支持时不连接
+func linkControl(from, to interface{}) {
+	lane, ok := to.(ControlLane)
+	if !ok {
+		return
+	}
+	if ch := controlOutput(from); ch != nil {
+		lane.SetControlChan(ch)
+	}
+}
+
+// handleControl 处理控制通道中的指令，返回 true 表示流已结束、处理循环应退出
+// 指令先交给处理函数，使打断尽快传到下游，再清除输入队列中被打断轮次的数据
+func (b *BaseComponent) handleControl(packet Packet) bool {
+	b.healthLock.Lock()
+	b.health.ProcessedCount++
+	b.healthLock.Unlock()
+
+	if b.dispatchCommand(packet) {
+		return true
+	}
+	return b.purgeStale(packet.TurnSeq)
+}
+
+// purgeStale 丢弃暂存与输入队列头部早于 turnSeq 的数据包，忽略轮次的组件不清除
+// 遇到新轮次的数据或指令包时停止，并照常处理该数据包，返回 true 表示处理循环应退出
+func (b *BaseComponent) purgeStale(turnSeq int) bool {
+	if b.ignoreTurn {
+		return false
+	}
+
+	var dropped int64
+	defer func() {
+		if dropped == 0 {
+			return
+		}
+		b.healthLock.Lock()
+		b.health.DroppedCount += dropped
+		b.healthLock.Unlock()
+		logger.Info("[TurnSeq: %d] **%s** Purged %d queued packets of interrupted turns", turnSeq, b.GetName(), dropped)
+	}()
+
+	pending := b.pending[:0]
+	for _, packet := range b.pending {
+		if packet.TurnSeq < turnSeq {
+			ReleasePacket(packet)
+			dropped++
+			continue
+		}
+		pending = append(pending, packet)
+	}
+	b.pending = pending
+
+	for {
+		select {
+		case packet, ok := <-b.inputChan:
+			if !ok {
+				b.endStream()
+				return true
+			}
+			if packet.Command == PacketCommandNone && packet.TurnSeq < turnSeq {
+				b.healthLock.Lock()
+				b.health.ProcessedCount++
+				b.healthLock.Unlock()
+				ReleasePacket(packet)
+				dropped++
+				continue
+			}
+			return b.handleInput(packet)
+		default:
+			return false
+		}
+	}
+}
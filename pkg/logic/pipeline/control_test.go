@@ -0,0 +1,89 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newGatedSink 创建按轮次过滤的输出组件，处理数据时等待 gate 关闭，收到打断时更新轮次并记录
+func newGatedSink(gate chan struct{}, interrupts chan int) *passthrough {
+	sink := newPassthrough("sink")
+	sink.SetIgnoreTurn(false)
+	sink.SetProcess(func(packet Packet) {
+		<-gate
+		sink.ForwardPacket(packet)
+	})
+	sink.RegisterCommandHandler(PacketCommandInterrupt, func(packet Packet) {
+		sink.SetCurTurnSeq(packet.TurnSeq)
+		interrupts <- packet.TurnSeq
+	})
+	return sink
+}
+
+func TestControlLane_InterruptBypassesQueue(t *testing.T) {
+	gate := make(chan struct{})
+	interrupts := make(chan int, 1)
+	source := newPassthrough("source")
+	sink := newGatedSink(gate, interrupts)
+	p := NewPipelineWithSource(source)
+	assert.NoError(t, p.Connect(sink))
+	assert.NoError(t, p.Start(context.Background()))
+	defer p.Stop(context.Background())
+
+	// 第一个数据包处理中，其余旧轮次的数据在输入队列中排队
+	for i := 0; i < 10; i++ {
+		p.inject(Packet{Data: i})
+	}
+	assert.Eventually(t, func() bool { return len(sink.GetInputChan()) == 9 }, time.Second, time.Millisecond)
+	p.SendInterrupt(1)
+	p.inject(Packet{Data: "reply", TurnSeq: 1})
+	close(gate)
+
+	// 打断先于排队的数据处理，被打断轮次的数据被清除
+	select {
+	case turnSeq := <-interrupts:
+		assert.Equal(t, 1, turnSeq)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for interrupt")
+	}
+	assert.Equal(t, 0, recv(t, sink.GetOutputChan()).Data)
+	assert.Equal(t, "reply", recv(t, sink.GetOutputChan()).Data)
+	assert.Equal(t, int64(9), sink.GetHealth().DroppedCount)
+}
+
+func TestControlLane_FanOut(t *testing.T) {
+	source := newPassthrough("source")
+	llm := newPassthrough("llm")
+	gate := make(chan struct{})
+	left := newPassthrough("left")
+	left.SetProcess(func(packet Packet) {
+		<-gate
+		left.ForwardPacket(packet)
+	})
+	right := newPassthrough("right")
+
+	g := NewGraph().Chain(source, llm, left)
+	g.AddEdge(llm, right)
+	built, err := g.Build()
+	assert.NoError(t, err)
+	startAll(t, built)
+
+	source.GetOutputChan() <- Packet{Data: "a"}
+	source.GetOutputChan() <- Packet{Data: "b"}
+	assert.Eventually(t, func() bool {
+		return len(left.GetInputChan()) == 1 && len(right.GetOutputChan()) == 2
+	}, time.Second, time.Millisecond)
+	source.GetControlOutChan() <- *GenInterruptPacket(1)
+
+	// 每个分支都经由控制通道收到打断，left 的打断排在正在处理的数据之后、排队的数据之前
+	assert.Equal(t, "a", recv(t, right.GetOutputChan()).Data)
+	assert.Equal(t, "b", recv(t, right.GetOutputChan()).Data)
+	assert.Equal(t, PacketCommandInterrupt, recv(t, right.GetOutputChan()).Command)
+	close(gate)
+	assert.Equal(t, "a", recv(t, left.GetOutputChan()).Data)
+	assert.Equal(t, PacketCommandInterrupt, recv(t, left.GetOutputChan()).Command)
+	assert.Equal(t, "b", recv(t, left.GetOutputChan()).Data)
+}
@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"fmt"
+	"time"
+)
+
+// GraphDef 声明式的组件图定义，节点按名称引用
+type GraphDef struct {
+	Nodes []NodeDef `yaml:"nodes"`
+	Chain []string  `yaml:"chain"` // 按顺序串联的节点名，等价于依次添加边
+	Edges []EdgeDef `yaml:"edges"` // 额外的边，用于扇出与扇入
+}
+
+// NodeDef 定义一个组件节点
+type NodeDef struct {
+	Name       string `yaml:"name"`
+	Type       string `yaml:"type"` // 注册的组件类型，为空时由运行时绑定（如连接的 source/sink）
+	Params     Params `yaml:"params"`
+	IgnoreTurn bool   `yaml:"ignore_turn"` // 不按轮次过滤数据包，音频前处理组件通常需要开启
+}
+
+// EdgeDef 定义两个节点之间的连接
+type EdgeDef struct {
+	From         string        `yaml:"from"`
+	To           string        `yaml:"to"`
+	BufferSize   int           `yaml:"buffer_size"`  // 边上 channel 的缓冲大小，0 表示沿用上游组件的输出 channel
+	Backpressure string        `yaml:"backpressure"` // drop_newest/drop_oldest/block/coalesce，为空时沿用上游组件的策略
+	Timeout      time.Duration `yaml:"timeout"`      // block/coalesce 策略的最长等待时间
+}
+
+// BuildOptions 声明式构建 pipeline 的选项
+type BuildOptions struct {
+	Registry *Registry            // 组件注册表，为空时使用全局注册表
+	Bindings map[string]Component // 运行时提供的节点（如连接的 source/sink），按节点名绑定
+	Defaults map[string]Params    // 按组件类型提供的默认参数，节点上的参数优先
+}
+
+// BuildGraph 根据组件图定义创建组件并构建组件图
+// 返回组件图与按节点名索引的组件，便于调用方获取特定组件（如 TurnManager）
+func BuildGraph(def GraphDef, opts BuildOptions) (*Graph, map[string]Component, error) {
+	registry := opts.Registry
+	if registry == nil {
+		registry = defaultRegistry
+	}
+
+	nodes := make(map[string]Component)
+	for _, n := range def.Nodes {
+		if n.Name == "" {
+			return nil, nil, fmt.Errorf("pipeline node of type %q has no name", n.Type)
+		}
+		if _, dup := nodes[n.Name]; dup {
+			return nil, nil, fmt.Errorf("duplicate pipeline node %q", n.Name)
+		}
+
+		var c Component
+		if n.Type == "" {
+			bound, ok := opts.Bindings[n.Name]
+			if !ok {
+				return nil, nil, fmt.Errorf("pipeline node %q has no type and is not bound at runtime", n.Name)
+			}
+			c = bound
+		} else {
+			created, err := registry.Create(n.Type, n.Params.WithDefaults(opts.Defaults[n.Type]))
+			if err != nil {
+				return nil, nil, fmt.Errorf("create pipeline node %q: %w", n.Name, err)
+			}
+			c = created
+		}
+		if n.IgnoreTurn {
+			if c, ok := c.(interface{ SetIgnoreTurn(bool) }); ok {
+				c.SetIgnoreTurn(true)
+			}
+		}
+		nodes[n.Name] = c
+	}
+
+	// 未声明的节点名直接引用运行时绑定
+	resolve := func(name string) (Component, error) {
+		if c, ok := nodes[name]; ok {
+			return c, nil
+		}
+		if c, ok := opts.Bindings[name]; ok {
+			nodes[name] = c
+			return c, nil
+		}
+		return nil, fmt.Errorf("unknown pipeline node %q", name)
+	}
+
+	// 先按声明顺序加入节点，未连接的节点会成为多余的根节点，ConnectGraph 时报错
+	g := NewGraph()
+	for _, n := range def.Nodes {
+		g.AddNode(nodes[n.Name])
+	}
+	for i := 1; i < len(def.Chain); i++ {
+		from, err := resolve(def.Chain[i-1])
+		if err != nil {
+			return nil, nil, err
+		}
+		to, err := resolve(def.Chain[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		g.AddEdge(from, to)
+	}
+	for _, e := range def.Edges {
+		from, err := resolve(e.From)
+		if err != nil {
+			return nil, nil, err
+		}
+		to, err := resolve(e.To)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts := EdgeOptions{BufferSize: e.BufferSize}
+		if e.Backpressure != "" {
+			mode, ok := ParseBackpressureMode(e.Backpressure)
+			if !ok {
+				return nil, nil, fmt.Errorf("edge %s -> %s: unknown backpressure mode %q", e.From, e.To, e.Backpressure)
+			}
+			opts.Backpressure = &BackpressurePolicy{Mode: mode, Timeout: e.Timeout}
+		}
+		g.AddEdge(from, to, opts)
+	}
+
+	return g, nodes, nil
+}
@@ -0,0 +1,238 @@
+package pipeline
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType 会话事件类型
+type EventType string
+
+const (
+	EventTurnStarted         EventType = "turn_started"         // TurnManager 确认用户的一句话并开始新轮次，数据为 TurnEvent
+	EventInterrupt           EventType = "interrupt"            // 发出打断指令，数据为 InterruptEvent
+	EventTranscript          EventType = "transcript"           // ASR 输出识别结果，数据为 TranscriptEvent
+	EventPlaybackInterrupted EventType = "playback_interrupted" // 输出端停止播放被打断的回复，数据为 PlaybackPosition
+	EventError               EventType = "error"                // 组件记录错误，数据为 ErrorEvent
+	EventHealthChanged       EventType = "health_changed"       // 健康检查按阈值切换组件状态，数据为 HealthEvent
+	EventSpeechStarted       EventType = "speech_started"       // VAD 检测到用户开始说话，数据为 VoiceActivity
+	EventSpeechEnded         EventType = "speech_ended"         // VAD 检测到用户停止说话，数据为 VoiceActivity
+	EventBargeIn             EventType = "barge_in"             // 播放期间检测到用户持续说话并打断，数据为 BargeInEvent
+	EventInterruptClassified EventType = "interrupt_classified" // 代理说话期间用户的话被分类，数据为 InterruptClassification
+	EventUtteranceScored     EventType = "utterance_scored"     // 语义断句评估缓存句子的完整度，数据为 CompletenessEvent
+	EventReply               EventType = "reply"                // LLM 结束一轮回复的生成，数据为 ReplyEvent
+)
+
+// Event 组件发布到会话事件总线的事件
+type Event struct {
+	Type      EventType   `json:"type"`
+	Time      time.Time   `json:"time"`
+	Component string      `json:"component"`
+	TurnSeq   int         `json:"turn_seq"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// TurnEvent 新轮次的用户输入
+type TurnEvent struct {
+	Text string `json:"text"`
+}
+
+// InterruptEvent 打断指令的来源
+type InterruptEvent struct {
+	Type InterruptType `json:"type"`
+}
+
+// ReplyEvent 代理一轮回复的文本，生成被打断时为已生成的部分
+type ReplyEvent struct {
+	Text        string `json:"text"`
+	Interrupted bool   `json:"interrupted,omitempty"`
+}
+
+// TranscriptEvent 识别结果，中间结果的 Final 为 false
+type TranscriptEvent struct {
+	Text   string `json:"text"`
+	Final  bool   `json:"final"`
+	Stable bool   `json:"stable,omitempty"` // 中间结果已稳定
+}
+
+// InterimTranscript ASR 在句子结束前输出的中间识别结果，作为独立的数据包类型发往下游
+// 句子结束时的最终结果仍以 string 发出，不关心中间结果的组件忽略即可
+type InterimTranscript struct {
+	Text       string `json:"text"`        // 当前句子目前的识别文本
+	StableText string `json:"stable_text"` // 已稳定、后续结果不会再修改的前缀
+	Stable     bool   `json:"stable"`      // 整句识别文本已稳定
+	Index      int    `json:"index"`       // 句子在识别会话中的序号
+}
+
+// SpeculativeTranscript TurnManager 根据稳定的中间识别结果推测的用户输入，LLM 可据此提前生成回复
+// 推测的回复在收到与之一致的最终文本前只缓存在 LLM 中，不会发往下游
+type SpeculativeTranscript struct {
+	Text string `json:"text"`
+}
+
+// CompletenessEvent 缓存句子的完整度与据此调整后的静音超时
+type CompletenessEvent struct {
+	Text           string        `json:"text"`
+	Score          float64       `json:"score"`
+	SilenceTimeout time.Duration `json:"silence_timeout"`
+}
+
+// VoiceActivity 语音起止，VAD 同时将其作为数据包发往下游，供 TurnManager 等组件使用
+type VoiceActivity struct {
+	Speaking  bool          `json:"speaking"`           // true 为开始说话，false 为停止说话
+	Timestamp time.Duration `json:"timestamp"`          // 语音开始或结束处的媒体时间戳
+	Duration  time.Duration `json:"duration,omitempty"` // 停止说话时为这段语音的时长
+}
+
+// ErrorEvent 组件错误
+type ErrorEvent struct {
+	Error string `json:"error"`
+	Fatal bool   `json:"fatal"` // 组件因错误进入 Error 状态
+}
+
+// EventBus 会话级的事件总线，组件发布事件，订阅者通过各自的 channel 接收
+// 发布不会阻塞：订阅者的缓冲区满时丢弃该订阅者的事件并计数，保证音频链路不受慢订阅者影响
+type EventBus struct {
+	mu        sync.RWMutex
+	subs      map[*Subscription]struct{}
+	observers []observer
+	closed    bool
+}
+
+// observer 同步接收事件的回调，见 Observe
+type observer struct {
+	fn    func(Event)
+	types map[EventType]bool
+}
+
+// NewEventBus 创建事件总线
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscription 事件订阅，types 为空时接收所有类型
+type Subscription struct {
+	bus     *EventBus
+	ch      chan Event
+	types   map[EventType]bool
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// Subscribe 订阅指定类型的事件，types 为空时订阅全部事件
+// 总线关闭后返回的订阅 channel 立即关闭
+func (b *EventBus) Subscribe(bufferSize int, types ...EventType) *Subscription {
+	s := &Subscription{bus: b, ch: make(chan Event, bufferSize)}
+	if len(types) > 0 {
+		s.types = make(map[EventType]bool, len(types))
+		for _, t := range types {
+			s.types[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(s.ch)
+		return s
+	}
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// Observe 注册同步的事件回调，types 为空时接收全部事件，总线关闭后不再调用
+// 回调在发布者的协程中执行，不会丢弃事件，用于会话记录等不允许丢失的场景；回调须快速返回且不能再发布事件
+func (b *EventBus) Observe(fn func(Event), types ...EventType) {
+	o := observer{fn: fn}
+	if len(types) > 0 {
+		o.types = make(map[EventType]bool, len(types))
+		for _, t := range types {
+			o.types[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.observers = append(b.observers, o)
+}
+
+// Publish 发布事件，nil 总线上的调用被忽略
+func (b *EventBus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, o := range b.observers {
+		if o.types == nil || o.types[event.Type] {
+			o.fn(event)
+		}
+	}
+	for s := range b.subs {
+		if s.types != nil && !s.types[event.Type] {
+			continue
+		}
+		select {
+		case s.ch <- event:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Close 关闭总线并关闭所有订阅的 channel，之后发布的事件被忽略
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for s := range b.subs {
+		s.once.Do(func() { close(s.ch) })
+	}
+	b.subs = nil
+	b.observers = nil
+}
+
+// Events 返回接收事件的 channel，取消订阅或总线关闭后被关闭
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Dropped 返回因缓冲区满而丢弃的事件数
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Unsubscribe 取消订阅并关闭 channel
+func (s *Subscription) Unsubscribe() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	delete(s.bus.subs, s)
+	s.once.Do(func() { close(s.ch) })
+}
+
+// SetEventBus 设置组件发布事件的总线
+func (b *BaseComponent) SetEventBus(bus *EventBus) {
+	b.events.Store(bus)
+}
+
+// Publish 向会话事件总线发布事件，未设置总线时忽略
+func (b *BaseComponent) Publish(eventType EventType, turnSeq int, data interface{}) {
+	bus := b.events.Load()
+	if bus == nil {
+		return
+	}
+	bus.Publish(Event{
+		Type:      eventType,
+		Time:      b.Clock().Now(),
+		Component: b.name,
+		TurnSeq:   turnSeq,
+		Data:      data,
+	})
+}
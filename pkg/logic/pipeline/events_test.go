@@ -0,0 +1,115 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recvEvent 在超时前读取一个事件
+func recvEvent(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event := <-sub.Events():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for event")
+		return Event{}
+	}
+}
+
+func TestEventBus_Subscribe(t *testing.T) {
+	bus := NewEventBus()
+	all := bus.Subscribe(10)
+	errs := bus.Subscribe(10, EventError)
+
+	bus.Publish(Event{Type: EventTranscript, Data: TranscriptEvent{Text: "你好", Final: true}})
+	bus.Publish(Event{Type: EventError, Data: ErrorEvent{Error: "boom"}})
+
+	assert.Equal(t, EventTranscript, recvEvent(t, all).Type)
+	assert.Equal(t, EventError, recvEvent(t, all).Type)
+	assert.Equal(t, ErrorEvent{Error: "boom"}, recvEvent(t, errs).Data)
+	assert.Empty(t, errs.Events())
+
+	// 取消订阅后 channel 关闭，不再收到事件
+	errs.Unsubscribe()
+	bus.Publish(Event{Type: EventError})
+	_, ok := <-errs.Events()
+	assert.False(t, ok)
+
+	bus.Close()
+	assert.Equal(t, EventError, recvEvent(t, all).Type)
+	_, ok = <-all.Events()
+	assert.False(t, ok)
+	_, ok = <-bus.Subscribe(1).Events()
+	assert.False(t, ok)
+}
+
+func TestEventBus_SlowSubscriber(t *testing.T) {
+	bus := NewEventBus()
+	sub := bus.Subscribe(1)
+
+	// 缓冲区满时发布不阻塞，丢弃的事件计数
+	for i := 0; i < 3; i++ {
+		bus.Publish(Event{Type: EventInterrupt, TurnSeq: i})
+	}
+	assert.Equal(t, 0, recvEvent(t, sub).TurnSeq)
+	assert.Equal(t, int64(2), sub.Dropped())
+}
+
+func TestEventBus_Observe(t *testing.T) {
+	bus := NewEventBus()
+	sub := bus.Subscribe(1)
+	var observed []int
+	bus.Observe(func(event Event) { observed = append(observed, event.TurnSeq) }, EventInterrupt)
+
+	// 订阅者缓冲区满时丢弃事件，同步回调仍按发布顺序收到全部事件
+	for i := 0; i < 3; i++ {
+		bus.Publish(Event{Type: EventInterrupt, TurnSeq: i})
+	}
+	bus.Publish(Event{Type: EventError})
+	assert.Equal(t, []int{0, 1, 2}, observed)
+	assert.Equal(t, int64(3), sub.Dropped())
+
+	// 总线关闭后不再调用
+	bus.Close()
+	bus.Publish(Event{Type: EventInterrupt, TurnSeq: 3})
+	bus.Observe(func(Event) { t.Fatal("observer registered after close") })
+	bus.Publish(Event{Type: EventInterrupt, TurnSeq: 4})
+	assert.Equal(t, []int{0, 1, 2}, observed)
+}
+
+func TestEventBus_ComponentEvents(t *testing.T) {
+	bus := NewEventBus()
+	sub := bus.Subscribe(10)
+	source := newPassthrough("source")
+	tm := NewTurnManager(DefaultTurnManagerConfig())
+	tm.SetIgnoreTurn(true)
+	tm.SetUseInterrupt(true)
+	sink := newPassthrough("sink")
+	p := NewPipelineWithSource(source)
+	p.SetEventBus(bus)
+	assert.NoError(t, p.Connect(tm, sink))
+	assert.NoError(t, p.Start(context.Background()))
+	defer p.Stop(context.Background())
+
+	p.Process("你好。")
+	event := recvEvent(t, sub)
+	assert.Equal(t, EventInterrupt, event.Type)
+	assert.Equal(t, InterruptEvent{Type: InterruptTypeSemantic}, event.Data)
+	event = recvEvent(t, sub)
+	assert.Equal(t, EventTurnStarted, event.Type)
+	assert.Equal(t, "TurnManager", event.Component)
+	assert.Equal(t, 1, event.TurnSeq)
+	assert.Equal(t, TurnEvent{Text: "你好。"}, event.Data)
+
+	// 组件记录的错误作为事件发布
+	sink.UpdateErrorStatus(errors.New("write failed"))
+	event = recvEvent(t, sub)
+	assert.Equal(t, EventError, event.Type)
+	assert.Equal(t, "sink", event.Component)
+	assert.Equal(t, ErrorEvent{Error: "write failed"}, event.Data)
+}
@@ -0,0 +1,189 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock 手动推进的 Clock，用于测试超时与定时逻辑
+// 时间只在调用 Advance 时前进，到期的定时器、Ticker 与 Sleep 按到期顺序触发
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter // 未到期的定时器、Ticker 与 Sleep
+}
+
+// NewFakeClock 创建从 start 开始计时的 FakeClock
+func NewFakeClock(start time.Time) *FakeClock {
+	c := &FakeClock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now 返回当前的虚拟时间
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Since 返回虚拟时间下自 t 起经过的时长
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Advance 将时间推进 d，期间到期的定时器依次触发
+// 与真实 Ticker 一样，接收方来不及读取时多余的触发被丢弃
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	target := c.now.Add(d)
+	for {
+		w := c.nextLocked(target)
+		if w == nil {
+			break
+		}
+		c.now = w.deadline
+		select {
+		case w.ch <- w.deadline:
+		default:
+		}
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+		} else {
+			c.removeLocked(w)
+		}
+	}
+	c.now = target
+}
+
+// BlockUntil 阻塞直到至少有 n 个未到期的定时器、Ticker 或 Sleep
+// 用于确认被测协程已进入等待，再推进时间
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+// NewTicker 创建按虚拟时间触发的 Ticker
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	w := &fakeWaiter{clock: c, period: d, ch: make(chan time.Time, 1)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w.deadline = c.now.Add(d)
+	c.addLocked(w)
+	return fakeTicker{w}
+}
+
+// NewTimer 创建按虚拟时间触发的定时器，d 不大于零时立即触发
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{clock: c, ch: make(chan time.Time, 1)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scheduleLocked(w, d)
+	return fakeTimer{w}
+}
+
+// After 等价于 NewTimer(d).C()
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Sleep 阻塞直到虚拟时间推进 d
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// scheduleLocked 设置单次定时器的到期时间，已到期时立即触发
+func (c *FakeClock) scheduleLocked(w *fakeWaiter, d time.Duration) {
+	w.deadline = c.now.Add(d)
+	if d <= 0 {
+		select {
+		case w.ch <- w.deadline:
+		default:
+		}
+		return
+	}
+	c.addLocked(w)
+}
+
+// nextLocked 返回不晚于 target 且最早到期的等待者
+func (c *FakeClock) nextLocked(target time.Time) *fakeWaiter {
+	var next *fakeWaiter
+	for _, w := range c.waiters {
+		if w.deadline.After(target) {
+			continue
+		}
+		if next == nil || w.deadline.Before(next.deadline) {
+			next = w
+		}
+	}
+	return next
+}
+
+func (c *FakeClock) addLocked(w *fakeWaiter) {
+	c.waiters = append(c.waiters, w)
+	c.cond.Broadcast()
+}
+
+// removeLocked 移除等待者，返回它是否仍未到期
+func (c *FakeClock) removeLocked(w *fakeWaiter) bool {
+	for i, x := range c.waiters {
+		if x == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fakeWaiter 实现 FakeClock 的 Timer 与 Ticker，period 非零时为 Ticker
+type fakeWaiter struct {
+	clock    *FakeClock
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+func (w *fakeWaiter) stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	return w.clock.removeLocked(w)
+}
+
+func (w *fakeWaiter) reset(d time.Duration) bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	active := w.clock.removeLocked(w)
+	if w.period > 0 {
+		w.period = d
+	}
+	w.clock.scheduleLocked(w, d)
+	return active
+}
+
+type fakeTimer struct{ *fakeWaiter }
+
+func (t fakeTimer) Stop() bool                 { return t.stop() }
+func (t fakeTimer) Reset(d time.Duration) bool { return t.reset(d) }
+
+type fakeTicker struct{ *fakeWaiter }
+
+func (t fakeTicker) Stop() { t.stop() }
+
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock ticker Reset")
+	}
+	t.reset(d)
+}
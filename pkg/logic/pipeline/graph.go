@@ -0,0 +1,232 @@
+package pipeline
+
+import (
+	"fmt"
+	"streamlink/pkg/logger"
+)
+
+// EdgeOptions 定义一条边的连接参数
+type EdgeOptions struct {
+	BufferSize   int                 // 边上 channel 的缓冲大小，0 表示沿用上游组件的输出 channel
+	Backpressure *BackpressurePolicy // 边上的背压策略，为空时沿用上游组件的策略
+}
+
+// BackpressureConfigurable 由支持配置输出背压策略的组件实现
+type BackpressureConfigurable interface {
+	SetBackpressure(policy BackpressurePolicy)
+	GetBackpressure() BackpressurePolicy
+}
+
+// graphEdge 描述两个组件之间的连接
+type graphEdge struct {
+	from Component
+	to   Component
+	opts EdgeOptions
+}
+
+// Graph 用于构建有向无环的组件图，支持扇出(Tee)与扇入(Merge)
+//
+//	g := NewGraph()
+//	g.Chain(source, decoder, resampler, asr)
+//	g.AddEdge(asr, turnManager)
+//	g.AddEdge(asr, transcriptLogger, EdgeOptions{BufferSize: 1000})
+type Graph struct {
+	nodes []Component
+	index map[Component]int
+	edges []graphEdge
+}
+
+// NewGraph 创建一个空的组件图
+func NewGraph() *Graph {
+	return &Graph{
+		index: make(map[Component]int),
+	}
+}
+
+// AddNode 添加组件节点，重复添加会被忽略
+func (g *Graph) AddNode(c Component) *Graph {
+	if _, exists := g.index[c]; !exists {
+		g.index[c] = len(g.nodes)
+		g.nodes = append(g.nodes, c)
+	}
+	return g
+}
+
+// AddEdge 添加一条 from -> to 的边，未添加的节点会被自动加入
+func (g *Graph) AddEdge(from, to Component, opts ...EdgeOptions) *Graph {
+	g.AddNode(from)
+	g.AddNode(to)
+	edge := graphEdge{from: from, to: to}
+	if len(opts) > 0 {
+		edge.opts = opts[0]
+	}
+	g.edges = append(g.edges, edge)
+	return g
+}
+
+// Chain 将组件按顺序串联
+func (g *Graph) Chain(components ...Component) *Graph {
+	for i, c := range components {
+		g.AddNode(c)
+		if i > 0 {
+			g.AddEdge(components[i-1], c)
+		}
+	}
+	return g
+}
+
+// Roots 返回没有输入边的节点（即音频源）
+func (g *Graph) Roots() []Component {
+	hasInput := make(map[Component]bool)
+	for _, e := range g.edges {
+		hasInput[e.to] = true
+	}
+	var roots []Component
+	for _, n := range g.nodes {
+		if !hasInput[n] {
+			roots = append(roots, n)
+		}
+	}
+	return roots
+}
+
+// sort 返回拓扑排序后的节点，存在环时返回错误
+func (g *Graph) sort() ([]Component, error) {
+	inDegree := make(map[Component]int)
+	for _, e := range g.edges {
+		inDegree[e.to]++
+	}
+
+	var queue, sorted []Component
+	for _, n := range g.nodes {
+		if inDegree[n] == 0 {
+			queue = append(queue, n)
+		}
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		sorted = append(sorted, n)
+		for _, e := range g.edges {
+			if e.from != n {
+				continue
+			}
+			inDegree[e.to]--
+			if inDegree[e.to] == 0 {
+				queue = append(queue, e.to)
+			}
+		}
+	}
+
+	if len(sorted) != len(g.nodes) {
+		return nil, fmt.Errorf("pipeline graph contains a cycle")
+	}
+	return sorted, nil
+}
+
+// Build 校验图并连接所有 channel，返回按拓扑顺序排列的组件（包含自动插入的 Tee/Merge）
+func (g *Graph) Build() ([]Component, error) {
+	if len(g.nodes) == 0 {
+		return nil, fmt.Errorf("no components to connect")
+	}
+
+	sorted, err := g.sort()
+	if err != nil {
+		return nil, err
+	}
+
+	outEdges := make(map[Component][]graphEdge)
+	inEdges := make(map[Component][]graphEdge)
+	for _, e := range g.edges {
+		if err := CheckFormatCompatible(e.from, e.to); err != nil {
+			return nil, err
+		}
+		outEdges[e.from] = append(outEdges[e.from], e)
+		inEdges[e.to] = append(inEdges[e.to], e)
+	}
+
+	// 扇入节点先插入 Merge，各条入边连接到 Merge 的独立输入
+	merges := make(map[Component]*Merge)
+	for _, n := range sorted {
+		if len(inEdges[n]) > 1 {
+			m := NewMerge(fmt.Sprintf("Merge(%s)", componentName(n)), cap(n.GetInputChan()))
+			m.Connect(n)
+			merges[n] = m
+		}
+	}
+
+	var built []Component
+	for _, n := range sorted {
+		if m, ok := merges[n]; ok {
+			built = append(built, m)
+		}
+		built = append(built, n)
+
+		edges := outEdges[n]
+		switch {
+		case len(edges) == 1:
+			connectEdge(n, edges[0], merges)
+		case len(edges) > 1:
+			// 扇出节点插入 Tee，每个分支拥有独立缓冲
+			tee := NewTee(fmt.Sprintf("Tee(%s)", componentName(n)))
+			n.Connect(tee)
+			for _, e := range edges {
+				bufferSize := e.opts.BufferSize
+				if bufferSize <= 0 {
+					bufferSize = cap(n.GetOutputChan())
+				}
+				branch := tee.AddBranchWithPolicy(bufferSize, edgePolicy(n, e))
+				linkInput(e.to, branch, func() chan Packet { return tee.branchControl(branch) }, merges)
+			}
+			built = append(built, tee)
+		}
+	}
+
+	return built, nil
+}
+
+// edgePolicy 返回边上生效的背压策略
+func edgePolicy(from Component, e graphEdge) BackpressurePolicy {
+	if e.opts.Backpressure != nil {
+		return *e.opts.Backpressure
+	}
+	if c, ok := from.(BackpressureConfigurable); ok {
+		return c.GetBackpressure()
+	}
+	return BackpressurePolicy{}
+}
+
+// connectEdge 连接一条普通边，必要时为该边创建独立缓冲
+func connectEdge(from Component, e graphEdge, merges map[Component]*Merge) {
+	if c, ok := from.(BackpressureConfigurable); ok && e.opts.Backpressure != nil {
+		c.SetBackpressure(*e.opts.Backpressure)
+	}
+	if _, fanIn := merges[e.to]; !fanIn && e.opts.BufferSize <= 0 {
+		from.Connect(e.to)
+		return
+	}
+
+	ch := from.GetOutputChan()
+	if e.opts.BufferSize > 0 {
+		ch = make(chan Packet, e.opts.BufferSize)
+		from.SetOutputChan(ch)
+	}
+	logger.Info("Connect component %s[out cap: %d] to %s", componentName(from), cap(ch), componentName(e.to))
+	linkInput(e.to, ch, func() chan Packet { return controlOutput(from) }, merges)
+}
+
+// linkInput 将 ch 作为 to 的输入，扇入节点则挂到对应的 Merge 上
+// to 支持控制通道时，同时连接由 control 返回的输出控制通道
+func linkInput(to Component, ch chan Packet, control func() chan Packet, merges map[Component]*Merge) {
+	if m, ok := merges[to]; ok {
+		m.AddInput(ch)
+		m.AddControlInput(control())
+		return
+	}
+	to.SetInputChan(ch)
+	if lane, ok := to.(ControlLane); ok {
+		if c := control(); c != nil {
+			lane.SetControlChan(c)
+		}
+	}
+}
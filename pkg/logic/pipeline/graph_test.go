@@ -0,0 +1,130 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// passthrough 是测试用的直通组件，收到的数据包会原样转发
+type passthrough struct {
+	*BaseComponent
+}
+
+func newPassthrough(name string) *passthrough {
+	p := &passthrough{BaseComponent: NewBaseComponent(name, 100)}
+	p.SetIgnoreTurn(true)
+	p.SetProcess(p.ForwardPacket)
+	p.SetDefaultCommandHandler(p.ForwardPacket)
+	return p
+}
+
+func (p *passthrough) GetID() interface{}     { return p.GetName() }
+func (p *passthrough) Process(packet Packet)  { p.GetInputChan() <- packet }
+func (p *passthrough) SetOutput(func(Packet)) {}
+
+// recv 在超时时间内从 ch 读取一个数据包
+func recv(t *testing.T, ch chan Packet) Packet {
+	t.Helper()
+	select {
+	case packet := <-ch:
+		return packet
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for packet")
+		return Packet{}
+	}
+}
+
+func startAll(t *testing.T, components []Component) {
+	t.Helper()
+	for _, c := range components {
+		assert.NoError(t, c.Start())
+	}
+	t.Cleanup(func() {
+		for _, c := range components {
+			c.Stop()
+		}
+	})
+}
+
+func TestGraph_FanOut(t *testing.T) {
+	source := newPassthrough("source")
+	asr := newPassthrough("asr")
+	turnManager := newPassthrough("turnManager")
+	transcript := newPassthrough("transcript")
+
+	g := NewGraph().Chain(source, asr, turnManager)
+	g.AddEdge(asr, transcript, EdgeOptions{BufferSize: 10})
+
+	built, err := g.Build()
+	assert.NoError(t, err)
+	// source, asr, tee, turnManager, transcript
+	assert.Len(t, built, 5)
+	startAll(t, built)
+
+	source.GetOutputChan() <- Packet{Data: "hello"}
+	source.GetOutputChan() <- Packet{Command: PacketCommandFlush, TurnSeq: 1}
+
+	for _, sink := range []*passthrough{turnManager, transcript} {
+		assert.Equal(t, "hello", recv(t, sink.GetOutputChan()).Data)
+		packet := recv(t, sink.GetOutputChan())
+		assert.Equal(t, PacketCommandFlush, packet.Command)
+		assert.Equal(t, 1, packet.TurnSeq)
+	}
+}
+
+func TestGraph_FanIn(t *testing.T) {
+	source := newPassthrough("source")
+	left := newPassthrough("left")
+	right := newPassthrough("right")
+	sink := newPassthrough("sink")
+
+	g := NewGraph()
+	g.AddEdge(source, left).AddEdge(source, right)
+	g.AddEdge(left, sink).AddEdge(right, sink)
+
+	built, err := g.Build()
+	assert.NoError(t, err)
+	startAll(t, built)
+
+	source.GetOutputChan() <- Packet{Data: "a"}
+	source.GetOutputChan() <- *GenInterruptPacket(2)
+
+	// 数据经两个分支各到达一次，指令只转发一次
+	var data, commands int
+	for i := 0; i < 3; i++ {
+		packet := recv(t, sink.GetOutputChan())
+		if packet.Command == PacketCommandInterrupt {
+			commands++
+		} else {
+			data++
+		}
+	}
+	assert.Equal(t, 2, data)
+	assert.Equal(t, 1, commands)
+
+	select {
+	case packet := <-sink.GetOutputChan():
+		t.Fatalf("unexpected packet: %+v", packet)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestGraph_Cycle(t *testing.T) {
+	a := newPassthrough("a")
+	b := newPassthrough("b")
+
+	_, err := NewGraph().AddEdge(a, b).AddEdge(b, a).Build()
+	assert.Error(t, err)
+}
+
+func TestGraph_FormatMismatch(t *testing.T) {
+	a := newPassthrough("a")
+	b := newPassthrough("b")
+	a.SetOutputFormat(NewPCMFormat(48000, 2))
+	b.SetInputFormat(NewPCMFormat(16000, 1))
+
+	_, err := NewGraph().Chain(a, b).Build()
+	assert.Error(t, err)
+}
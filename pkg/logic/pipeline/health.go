@@ -0,0 +1,167 @@
+package pipeline
+
+import (
+	"fmt"
+	"streamlink/pkg/logger"
+	"time"
+)
+
+// HealthThresholds 定义健康检查将组件置为 Warning 或 Error 的阈值，零值字段表示不检查该项
+// 只有停滞会将组件置为 Error，并且仅限支持重启的组件，由 Supervisor 先尝试重启；
+// 队列增长、错误率以及不支持重启的组件停滞只置为 Warning 并发布事件，不会结束会话。
+// 异常消失后组件恢复为 Running
+type HealthThresholds struct {
+	QueueGrowthWarning int           // 输入队列连续增长的检查次数，达到后置为 Warning
+	StallWarning       time.Duration // 输入队列非空而处理计数不再增加的时长，超过后置为 Warning
+	StallError         time.Duration // 同上，超过后置为 Error，如卡住的 ASR websocket
+	ErrorRateWarning   float64       // 两次检查之间新增错误数与处理数之比，超过后置为 Warning
+	MinErrors          int64         // 两次检查之间新增错误数少于该值时不按错误率判定，避免偶发错误误判
+}
+
+// DefaultHealthThresholds 返回默认的健康检查阈值
+func DefaultHealthThresholds() HealthThresholds {
+	return HealthThresholds{
+		QueueGrowthWarning: 3,
+		StallWarning:       10 * time.Second,
+		StallError:         30 * time.Second,
+		ErrorRateWarning:   0.1,
+		MinErrors:          3,
+	}
+}
+
+// HealthEvent 健康检查引起的组件状态变化
+type HealthEvent struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason,omitempty"` // 恢复为 Running 时为空
+}
+
+// healthTracker 记录单个组件在多次健康检查之间的变化趋势
+type healthTracker struct {
+	last         ComponentHealth
+	growth       int            // 输入队列连续增长的次数
+	lastProgress time.Time      // 最近一次处理计数增加或输入队列为空的时间
+	detected     ComponentState // 健康检查设置的状态，Running 表示未检测到异常
+	failure      error          // 健康检查置为 Error 时报告的错误，用于区分组件自行报告的失败
+}
+
+// evaluate 根据阈值判定组件应处的状态，多项异常时取最严重的一项，返回状态与原因
+func (t HealthThresholds) evaluate(tr *healthTracker, health ComponentHealth, now time.Time) (ComponentState, string) {
+	state, reason := ComponentStateRunning, ""
+	raise := func(target ComponentState, format string, args ...interface{}) {
+		if severity(target) > severity(state) {
+			state, reason = target, fmt.Sprintf(format, args...)
+		}
+	}
+
+	raise(thresholdLevel(float64(tr.growth), float64(t.QueueGrowthWarning), 0),
+		"input queue grew for %d checks to %d", tr.growth, health.InputQueueSize)
+
+	if health.InputQueueSize > 0 {
+		stalled := now.Sub(tr.lastProgress)
+		raise(thresholdLevel(float64(stalled), float64(t.StallWarning), float64(t.StallError)),
+			"no progress for %v with %d packets pending", stalled, health.InputQueueSize)
+	}
+
+	if errs := health.ErrorCount - tr.last.ErrorCount; errs > 0 && errs >= t.MinErrors {
+		rate := float64(errs)
+		if processed := health.ProcessedCount - tr.last.ProcessedCount; processed > 0 {
+			rate /= float64(processed)
+		}
+		raise(thresholdLevel(rate, t.ErrorRateWarning, 0),
+			"%d errors since last check (rate %.2f)", errs, rate)
+	}
+	return state, reason
+}
+
+// thresholdLevel 返回 value 达到的阈值对应的状态，阈值为 0 表示不检查
+func thresholdLevel(value, warning, fail float64) ComponentState {
+	switch {
+	case fail > 0 && value >= fail:
+		return ComponentStateError
+	case warning > 0 && value >= warning:
+		return ComponentStateWarning
+	default:
+		return ComponentStateRunning
+	}
+}
+
+// severity 返回健康检查判定状态的严重程度
+func severity(s ComponentState) int {
+	switch s {
+	case ComponentStateWarning:
+		return 1
+	case ComponentStateError:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// observe 用本次检查的健康信息更新变化趋势
+func (tr *healthTracker) observe(health ComponentHealth, now time.Time) {
+	if health.InputQueueSize > tr.last.InputQueueSize {
+		tr.growth++
+	} else {
+		tr.growth = 0
+	}
+	if health.ProcessedCount > tr.last.ProcessedCount || health.InputQueueSize == 0 {
+		tr.lastProgress = now
+	}
+}
+
+// applyHealthThresholds 判定组件状态并在变化时切换状态、发布事件，返回切换后的健康信息
+// 只处理 Running、Warning 与健康检查自身设置的 Error，组件自行报告的错误由 Supervisor 处理
+func (p *Pipeline) applyHealthThresholds(comp Component, tr *healthTracker, health ComponentHealth, now time.Time) ComponentHealth {
+	target, reason := p.healthThresholds.evaluate(tr, health, now)
+	if _, ok := comp.(Restartable); !ok && target == ComponentStateError {
+		// 无法重启的组件置为 Error 只会结束整个会话，停滞过久时仍只告警
+		target = ComponentStateWarning
+	}
+
+	current := health.State
+	switch current {
+	case ComponentStateRunning, ComponentStateWarning:
+	case ComponentStateError:
+		if tr.detected != ComponentStateError || health.LastError != tr.failure {
+			return health
+		}
+	default:
+		return health
+	}
+	if target == current {
+		tr.detected = target
+		return health
+	}
+
+	name := componentName(comp)
+	if target == ComponentStateError {
+		f, ok := comp.(interface{ ReportFailure(error) })
+		if !ok {
+			return health
+		}
+		tr.failure = fmt.Errorf("%s unhealthy: %s", name, reason)
+		f.ReportFailure(tr.failure)
+	} else {
+		s, ok := comp.(interface{ SetState(ComponentState) })
+		if !ok {
+			return health
+		}
+		s.SetState(target)
+	}
+	tr.detected = target
+	health.State = target
+
+	if reason != "" {
+		logger.Warn("Component %s health %s->%s: %s", name, current, target, reason)
+	} else {
+		logger.Info("Component %s health %s->%s: recovered", name, current, target)
+	}
+	p.events.Publish(Event{
+		Type:      EventHealthChanged,
+		Time:      now,
+		Component: name,
+		Data:      HealthEvent{From: current.String(), To: target.String(), Reason: reason},
+	})
+	return health
+}
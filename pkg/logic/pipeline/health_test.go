@@ -0,0 +1,155 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheck_Stall(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	bus := NewEventBus()
+	sub := bus.Subscribe(10, EventHealthChanged)
+
+	// 处理第一个数据包时卡住，模拟无响应的 ASR websocket
+	gate := make(chan struct{})
+	tts := &flaky{passthrough: newPassthrough("tts")}
+	tts.SetProcess(func(packet Packet) {
+		<-gate
+		tts.ForwardPacket(packet)
+	})
+	source := newPassthrough("source")
+	sink := newPassthrough("sink")
+	p := NewPipelineWithSource(source)
+	p.SetClock(clock)
+	p.SetHealthCheckInterval(time.Hour)
+	p.SetEventBus(bus)
+	p.SetHealthThresholds(HealthThresholds{StallWarning: 10 * time.Second, StallError: 30 * time.Second})
+	assert.NoError(t, p.Connect(tts, sink))
+	assert.NoError(t, p.Start(context.Background()))
+	defer p.Stop(context.Background())
+
+	for i := 0; i < 3; i++ {
+		p.Process(i)
+	}
+	assert.Eventually(t, func() bool { return tts.GetHealth().InputQueueSize == 2 }, time.Second, time.Millisecond)
+	p.checkComponentsHealth()
+
+	clock.Advance(10 * time.Second)
+	p.checkComponentsHealth()
+	event := recvEvent(t, sub)
+	assert.Equal(t, "tts", event.Component)
+	assert.Equal(t, "Running", event.Data.(HealthEvent).From)
+	assert.Equal(t, "Warning", event.Data.(HealthEvent).To)
+	assert.Equal(t, ComponentStateWarning, tts.GetHealth().State)
+
+	clock.Advance(20 * time.Second)
+	p.checkComponentsHealth()
+	assert.Equal(t, "Error", recvEvent(t, sub).Data.(HealthEvent).To)
+	assert.Equal(t, ComponentStateError, tts.GetHealth().State)
+	assert.Error(t, tts.GetHealth().LastError)
+
+	// 恢复处理后回到 Running
+	close(gate)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, i, recv(t, sink.GetOutputChan()).Data)
+	}
+	clock.Advance(10 * time.Second)
+	p.checkComponentsHealth()
+	event = recvEvent(t, sub)
+	assert.Equal(t, HealthEvent{From: "Error", To: "Running"}, event.Data)
+	assert.Equal(t, ComponentStateRunning, tts.GetHealth().State)
+}
+
+func TestHealthCheck_ErrorRate(t *testing.T) {
+	bus := NewEventBus()
+	sub := bus.Subscribe(10, EventHealthChanged)
+	source := newPassthrough("source")
+	llm := newPassthrough("llm")
+	p := NewPipelineWithSource(source)
+	p.SetEventBus(bus)
+	p.SetHealthThresholds(HealthThresholds{ErrorRateWarning: 0.2, MinErrors: 2})
+	assert.NoError(t, p.Connect(llm))
+	assert.NoError(t, p.Start(context.Background()))
+	defer p.Stop(context.Background())
+	assert.Eventually(t, func() bool { return llm.GetHealth().State == ComponentStateRunning }, time.Second, time.Millisecond)
+	p.checkComponentsHealth()
+
+	// 少于 MinErrors 的偶发错误不影响状态
+	llm.UpdateErrorStatus(errors.New("timeout"))
+	p.checkComponentsHealth()
+	assert.Equal(t, ComponentStateRunning, llm.GetHealth().State)
+
+	// 错误率过高只告警，不交由 Supervisor 结束会话
+	llm.UpdateErrorStatus(errors.New("timeout"))
+	llm.UpdateErrorStatus(errors.New("timeout"))
+	p.checkComponentsHealth()
+	assert.Equal(t, ComponentStateWarning, llm.GetHealth().State)
+	assert.Equal(t, HealthEvent{From: "Running", To: "Warning", Reason: "2 errors since last check (rate 2.00)"},
+		recvEvent(t, sub).Data)
+
+	// 组件自行报告的失败由 Supervisor 处理，健康检查不会将其恢复
+	llm.ReportFailure(errors.New("disconnected"))
+	p.checkComponentsHealth()
+	p.checkComponentsHealth()
+	assert.Equal(t, ComponentStateError, llm.GetHealth().State)
+}
+
+func TestHealthThresholds_QueueGrowth(t *testing.T) {
+	thresholds := HealthThresholds{QueueGrowthWarning: 2}
+	now := time.Unix(0, 0)
+	tracker := &healthTracker{lastProgress: now}
+
+	var states []ComponentState
+	for _, size := range []int{1, 2, 3, 4, 4, 5} {
+		health := ComponentHealth{InputQueueSize: size, ProcessedCount: int64(size)}
+		tracker.observe(health, now)
+		state, _ := thresholds.evaluate(tracker, health, now)
+		tracker.last = health
+		states = append(states, state)
+	}
+	assert.Equal(t, []ComponentState{
+		ComponentStateRunning, ComponentStateWarning, ComponentStateWarning,
+		ComponentStateWarning, ComponentStateRunning, ComponentStateRunning,
+	}, states)
+}
+
+func TestHealthCheck_StallWithoutRestart(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	bus := NewEventBus()
+	sub := bus.Subscribe(10, EventHealthChanged)
+
+	// 不支持重启的组件停滞过久时只告警，不会被 Supervisor 升级为会话失败
+	gate := make(chan struct{})
+	tts := newPassthrough("tts")
+	tts.SetProcess(func(packet Packet) {
+		<-gate
+		tts.ForwardPacket(packet)
+	})
+	p := NewPipelineWithSource(newPassthrough("source"))
+	p.SetClock(clock)
+	p.SetHealthCheckInterval(time.Hour)
+	p.SetEventBus(bus)
+	p.SetHealthThresholds(HealthThresholds{StallWarning: 10 * time.Second, StallError: 30 * time.Second})
+	assert.NoError(t, p.Connect(tts, newPassthrough("sink")))
+	assert.NoError(t, p.Start(context.Background()))
+	defer p.Stop(context.Background())
+	defer close(gate)
+
+	for i := 0; i < 3; i++ {
+		p.Process(i)
+	}
+	assert.Eventually(t, func() bool { return tts.GetHealth().InputQueueSize == 2 }, time.Second, time.Millisecond)
+	p.checkComponentsHealth()
+
+	clock.Advance(30 * time.Second)
+	p.checkComponentsHealth()
+	event := recvEvent(t, sub)
+	assert.Equal(t, "Warning", event.Data.(HealthEvent).To)
+	assert.Contains(t, event.Data.(HealthEvent).Reason, "no progress for 30s")
+	assert.Equal(t, ComponentStateWarning, tts.GetHealth().State)
+	assert.NoError(t, tts.GetHealth().LastError)
+}
@@ -0,0 +1,107 @@
+package pipeline
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// 组件上报的延迟指标名
+const (
+	LatencyASR           = "asr"             // 句子开始到最终识别结果
+	LatencyLLMFirstToken = "llm_first_token" // 收到文本到首个 token
+	LatencyTTSFirstAudio = "tts_first_audio" // 收到文本到首个音频数据
+	LatencyEndToEnd      = "end_to_end"      // 用户说完话到首个回复音频包发出
+)
+
+// LatencyBuckets 延迟直方图的默认桶上界（秒）
+var LatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 0.75, 1, 1.5, 2, 3, 5, 10}
+
+// Histogram 固定桶的累积直方图，可并发使用
+type Histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []uint64 // 每个桶（不含更小的桶）的样本数，最后一个为 +Inf
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram 创建直方图，bounds 为升序的桶上界
+func NewHistogram(bounds []float64) *Histogram {
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// Observe 记录一个样本
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// Snapshot 返回当前数据的副本
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return HistogramSnapshot{
+		Bounds: h.bounds,
+		Counts: append([]uint64(nil), h.counts...),
+		Sum:    h.sum,
+		Count:  h.count,
+	}
+}
+
+// HistogramSnapshot 直方图的只读副本，用于导出与跨会话聚合
+type HistogramSnapshot struct {
+	Bounds []float64 `json:"bounds"`
+	Counts []uint64  `json:"counts"` // 非累积计数，最后一个为 +Inf 桶
+	Sum    float64   `json:"sum"`
+	Count  uint64    `json:"count"`
+}
+
+// Merge 将 other 累加到当前快照，桶边界不同时忽略 other
+func (s *HistogramSnapshot) Merge(other HistogramSnapshot) {
+	if s.Counts == nil {
+		s.Bounds = other.Bounds
+		s.Counts = make([]uint64, len(other.Counts))
+	}
+	if len(s.Counts) != len(other.Counts) {
+		return
+	}
+	for i, c := range other.Counts {
+		s.Counts[i] += c
+	}
+	s.Sum += other.Sum
+	s.Count += other.Count
+}
+
+// ObserveLatency 记录组件的一次延迟样本，name 为 Latency* 常量之一
+func (b *BaseComponent) ObserveLatency(name string, d time.Duration) {
+	b.latencyLock.Lock()
+	h, ok := b.latencies[name]
+	if !ok {
+		h = NewHistogram(LatencyBuckets)
+		if b.latencies == nil {
+			b.latencies = make(map[string]*Histogram)
+		}
+		b.latencies[name] = h
+	}
+	b.latencyLock.Unlock()
+	h.Observe(d.Seconds())
+}
+
+// GetLatencies 返回组件记录的各项延迟直方图
+func (b *BaseComponent) GetLatencies() map[string]HistogramSnapshot {
+	b.latencyLock.Lock()
+	defer b.latencyLock.Unlock()
+	result := make(map[string]HistogramSnapshot, len(b.latencies))
+	for name, h := range b.latencies {
+		result[name] = h.Snapshot()
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram([]float64{0.1, 0.5, 1})
+	for _, v := range []float64{0.05, 0.1, 0.3, 2} {
+		h.Observe(v)
+	}
+
+	s := h.Snapshot()
+	// 等于上界的样本计入该桶
+	assert.Equal(t, []uint64{2, 1, 0, 1}, s.Counts)
+	assert.Equal(t, uint64(4), s.Count)
+	assert.InDelta(t, 2.45, s.Sum, 1e-9)
+
+	var merged HistogramSnapshot
+	merged.Merge(s)
+	merged.Merge(s)
+	assert.Equal(t, []uint64{4, 2, 0, 2}, merged.Counts)
+	assert.Equal(t, uint64(8), merged.Count)
+}
+
+func TestBaseComponent_ObserveLatency(t *testing.T) {
+	b := NewBaseComponent("llm", 1)
+	b.ObserveLatency(LatencyLLMFirstToken, 300*time.Millisecond)
+	b.ObserveLatency(LatencyLLMFirstToken, 2*time.Second)
+
+	latencies := b.GetLatencies()
+	assert.Len(t, latencies, 1)
+	assert.Equal(t, uint64(2), latencies[LatencyLLMFirstToken].Count)
+	assert.InDelta(t, 2.3, latencies[LatencyLLMFirstToken].Sum, 1e-9)
+
+	b.ReportFailure(assert.AnError)
+	b.UpdateErrorStatus(assert.AnError)
+	assert.Equal(t, int64(2), b.GetHealth().ErrorCount)
+}
@@ -0,0 +1,116 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+)
+
+// pacerTolerance 相邻帧时间戳允许的误差，超过时视为不连续并重新对齐媒体时钟
+const pacerTolerance = 5 * time.Millisecond
+
+// PlaybackPosition 输出端的播放进度，用于确定打断时用户实际听到的音频
+type PlaybackPosition struct {
+	TurnSeq    int           `json:"turn_seq"`    // 最近发送的音频所属的轮次
+	MediaTs    time.Duration `json:"media_ts"`    // 已播放到的媒体时间戳
+	TurnPlayed time.Duration `json:"turn_played"` // 该轮次已播放的音频时长
+	Buffered   time.Duration `json:"buffered"`    // 已发送但尚未播放的音频时长
+	Playing    bool          `json:"playing"`
+}
+
+// Pacer 将音频帧的媒体时间戳（AudioFrame.Timestamp）映射到媒体时钟，按实时速度输出
+// 媒体时钟在首帧、播放中断（发送跟不上）以及时间戳不连续时重新对齐，
+// 对齐点不早于已发送音频播放完的时刻，保证相邻轮次的音频不会重叠
+type Pacer struct {
+	clock Clock
+	lead  time.Duration // 提前发送的时长，为接收端的抖动缓冲留出余量
+
+	mu          sync.Mutex
+	anchored    bool
+	anchorWall  time.Time     // 对齐点的时钟时间
+	anchorMedia time.Duration // 对齐点的媒体时间戳
+	sentEnd     time.Duration // 已发送音频的结束时间戳
+	turnSeq     int
+	turnStart   time.Duration // 当前轮次首帧的时间戳
+}
+
+// NewPacer 创建 Pacer，音频帧最多比播放时刻提前 lead 发送
+func NewPacer(clock Clock, lead time.Duration) *Pacer {
+	if clock == nil {
+		clock = RealClock
+	}
+	return &Pacer{clock: clock, lead: lead}
+}
+
+// Schedule 返回时间戳为 ts 的帧应当发送的时刻
+func (p *Pacer) Schedule(ts time.Duration) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.clock.Now()
+	if !p.anchored {
+		p.anchor(now, ts)
+	} else if gap := ts - p.sentEnd; gap > pacerTolerance || gap < -pacerTolerance {
+		// 时间戳不连续（如打断后丢弃了部分音频），从已发送音频的结尾接着播放
+		start := p.wallTime(p.sentEnd)
+		if start.Before(now) {
+			start = now
+		}
+		p.anchor(start, ts)
+	} else if p.wallTime(ts).Before(now) {
+		// 发送跟不上播放，接收端已播完缓冲的音频，从当前时刻重新开始
+		p.anchor(now, ts)
+	}
+	return p.wallTime(ts).Add(-p.lead)
+}
+
+// Sent 记录已发送的帧，turnSeq 变化时开始统计新轮次的播放时长
+func (p *Pacer) Sent(turnSeq int, ts, duration time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if turnSeq != p.turnSeq {
+		p.turnSeq = turnSeq
+		p.turnStart = ts
+	}
+	p.sentEnd = ts + duration
+}
+
+// Position 返回当前的播放进度
+func (p *Pacer) Position() PlaybackPosition {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pos := PlaybackPosition{TurnSeq: p.turnSeq}
+	if !p.anchored {
+		return pos
+	}
+	// 对齐点在未来时，之前发送的音频还在播放，新的片段尚未开始
+	now := p.clock.Now()
+	played := p.anchorMedia
+	if elapsed := now.Sub(p.anchorWall); elapsed > 0 {
+		played += elapsed
+	}
+	if played > p.sentEnd {
+		played = p.sentEnd
+	}
+	pos.MediaTs = played
+	if played > p.turnStart {
+		pos.TurnPlayed = played - p.turnStart
+	}
+	if remaining := p.wallTime(p.sentEnd).Sub(now); remaining > 0 {
+		pos.Buffered = remaining
+		pos.Playing = true
+	}
+	return pos
+}
+
+func (p *Pacer) anchor(wall time.Time, ts time.Duration) {
+	p.anchored = true
+	p.anchorWall = wall
+	p.anchorMedia = ts
+	p.sentEnd = ts
+}
+
+// wallTime 返回时间戳 ts 对应的播放时刻
+func (p *Pacer) wallTime(ts time.Duration) time.Time {
+	return p.anchorWall.Add(ts - p.anchorMedia)
+}
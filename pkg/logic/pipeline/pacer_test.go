@@ -0,0 +1,82 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const frame20ms = 20 * time.Millisecond
+
+// sendFrames 按 Pacer 的调度连续发送 n 帧，返回下一帧的时间戳
+func sendFrames(p *Pacer, clock *FakeClock, turnSeq int, ts time.Duration, n int) time.Duration {
+	for i := 0; i < n; i++ {
+		if d := p.Schedule(ts).Sub(clock.Now()); d > 0 {
+			clock.Advance(d)
+		}
+		p.Sent(turnSeq, ts, frame20ms)
+		ts += frame20ms
+	}
+	return ts
+}
+
+func TestPacer_Schedule(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewFakeClock(start)
+	p := NewPacer(clock, 40*time.Millisecond)
+
+	// 首帧立即发送，之后的帧按时间戳实时发送，提前量为 lead
+	assert.Equal(t, start.Add(-40*time.Millisecond), p.Schedule(0))
+	p.Sent(1, 0, frame20ms)
+	assert.Equal(t, start.Add(-20*time.Millisecond), p.Schedule(frame20ms))
+	p.Sent(1, frame20ms, frame20ms)
+	assert.Equal(t, start, p.Schedule(2*frame20ms))
+	p.Sent(1, 2*frame20ms, frame20ms)
+	assert.Equal(t, start.Add(20*time.Millisecond), p.Schedule(3*frame20ms))
+
+	// 一次突发到达的 1 秒音频需要约 1 秒才能发完
+	ts := sendFrames(p, clock, 1, 3*frame20ms, 50)
+	assert.Equal(t, 1060*time.Millisecond, ts)
+	assert.Equal(t, time.Second, clock.Since(start))
+}
+
+func TestPacer_Resync(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewFakeClock(start)
+	p := NewPacer(clock, 0)
+	ts := sendFrames(p, clock, 1, 0, 5)
+
+	// 播放空闲后的连续时间戳从当前时刻重新开始
+	clock.Advance(time.Second)
+	now := clock.Now()
+	assert.Equal(t, now, p.Schedule(ts))
+	p.Sent(1, ts, frame20ms)
+
+	// 时间戳跳变时接在已发送音频的结尾播放
+	assert.Equal(t, now.Add(frame20ms), p.Schedule(10*time.Second))
+}
+
+func TestPacer_Position(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	p := NewPacer(clock, 60*time.Millisecond)
+	assert.Equal(t, PlaybackPosition{}, p.Position())
+
+	ts := sendFrames(p, clock, 1, 0, 10)
+	sendFrames(p, clock, 2, ts, 5)
+
+	// 第 2 轮已发送 100ms 音频，最后一帧提前 60ms 发出，用户实际只听到 20ms
+	pos := p.Position()
+	assert.Equal(t, 2, pos.TurnSeq)
+	assert.Equal(t, 220*time.Millisecond, pos.MediaTs)
+	assert.Equal(t, 20*time.Millisecond, pos.TurnPlayed)
+	assert.Equal(t, 80*time.Millisecond, pos.Buffered)
+	assert.True(t, pos.Playing)
+
+	// 已发送的音频播放完毕后停止
+	clock.Advance(time.Second)
+	pos = p.Position()
+	assert.Equal(t, 300*time.Millisecond, pos.MediaTs)
+	assert.Equal(t, 100*time.Millisecond, pos.TurnPlayed)
+	assert.False(t, pos.Playing)
+}
@@ -1,6 +1,8 @@
 package pipeline
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"streamlink/pkg/logger"
 	"strings"
@@ -11,12 +13,22 @@ import (
 // Pipeline 处理数据的管道
 type Pipeline struct {
 	components []Component
+	replaced   []Component // 被热替换下来的组件，保留其累计计数
+	mu         sync.Mutex  // 保护 components 的替换，热替换与停止互斥
 	source     Component
 	stopCh     chan struct{}
+	stopOnce   sync.Once
+	stopErr    error
+	supervisor *Supervisor
+	traces     *TraceRecorder
+	clock      Clock
+	events     *EventBus
 	// 新增健康监控相关字段
 	healthCheckInterval time.Duration
-	healthCheckTicker   *time.Ticker
+	healthCheckTicker   Ticker
 	lastHealthCheck     map[interface{}]ComponentHealth
+	healthThresholds    HealthThresholds
+	healthTrackers      map[Component]*healthTracker
 	healthLock          sync.RWMutex
 }
 
@@ -26,6 +38,7 @@ func NewPipeline() *Pipeline {
 		stopCh:              make(chan struct{}),
 		healthCheckInterval: 30 * time.Second, // 默认每30秒检查一次
 		lastHealthCheck:     make(map[interface{}]ComponentHealth),
+		healthThresholds:    DefaultHealthThresholds(),
 	}
 }
 
@@ -51,10 +64,8 @@ func (p *Pipeline) Process(data interface{}) {
 		Command: PacketCommandNone,
 	}
 
-	// 非阻塞地发送到第一个组件
-	select {
-	case p.components[0].GetInputChan() <- packet:
-	default:
+	// 按入口边的背压策略发送到第一个组件
+	if !p.inject(packet) {
 		logger.Error("Pipeline: first component's input channel full, dropping packet")
 	}
 }
@@ -74,12 +85,79 @@ func (p *Pipeline) SendInterrupt(turnSeq int) {
 		Command: PacketCommandInterrupt,
 	}
 
-	// 非阻塞地发送到第一个组件
+	// 指令包会等待入口有空位，不会因缓冲满被丢弃
+	if !p.inject(packet) {
+		logger.Error("Pipeline: failed to send interrupt packet, source stopped")
+	}
+}
+
+// SendCommand 从入口发送流控制指令，如 Flush、EndOfStream、Pause、Resume
+func (p *Pipeline) SendCommand(cmd PacketCommand) {
+	if len(p.components) == 0 {
+		return
+	}
+	if !p.inject(Packet{Command: cmd}) {
+		logger.Error("Pipeline: failed to send command %d, source stopped", cmd)
+	}
+}
+
+// Wait 等待所有组件自行退出，用于离线处理时等待流结束指令传递到输出端
+// 与 Stop 不同，Wait 不会停止音频源与组件
+func (p *Pipeline) Wait(ctx context.Context) error {
+	for _, c := range p.Components() {
+		if err := waitComponent(ctx, c); err != nil {
+			return fmt.Errorf("wait %s: %w", componentName(c), err)
+		}
+	}
+	return nil
+}
+
+// inject 将数据包写入入口，音频源为 BaseComponent 时沿用其输出边的背压策略与统计
+func (p *Pipeline) inject(packet Packet) bool {
 	select {
-	case p.components[0].GetInputChan() <- packet:
+	case <-p.stopCh:
+		return false
 	default:
-		logger.Error("Pipeline: first component's input channel full, dropping interrupt packet")
 	}
+
+	ch := p.entryChan()
+	fromSource := ch == p.source.GetOutputChan()
+	if lane, ok := p.source.(ControlLane); ok && isControlCommand(packet.Command) && lane.GetControlOutChan() != nil {
+		// 打断指令经由入口的控制通道，不必排在已注入的数据之后
+		ch, fromSource = lane.GetControlOutChan(), true
+	}
+	if src, ok := p.source.(interface {
+		DeliverPacket(chan Packet, Packet, BackpressurePolicy) bool
+		GetBackpressure() BackpressurePolicy
+	}); ok && fromSource {
+		return src.DeliverPacket(ch, packet, src.GetBackpressure())
+	}
+
+	var stats BackpressureStats
+	return deliver(ch, packet, BackpressurePolicy{}, p.Clock(), p.stopCh, &stats)
+}
+
+// entryChan 返回外部注入数据的入口，即音频源的输出 channel
+func (p *Pipeline) entryChan() chan Packet {
+	if p.source != nil && p.source.GetOutputChan() != nil {
+		return p.source.GetOutputChan()
+	}
+	return p.Components()[0].GetInputChan()
+}
+
+// Components 返回 pipeline 中除音频源外的组件，按拓扑顺序排列
+func (p *Pipeline) Components() []Component {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Component(nil), p.components...)
+}
+
+// ComponentsWithReplaced 同时返回当前组件与被热替换下来的组件，两者取自同一时刻，
+// 汇总累计计数时每个组件恰好出现一次
+func (p *Pipeline) ComponentsWithReplaced() (current, replaced []Component) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Component(nil), p.components...), append([]Component(nil), p.replaced...)
 }
 
 // SetSource 设置音频源组件
@@ -87,8 +165,9 @@ func (p *Pipeline) SetSource(source Component) {
 	p.source = source
 }
 
-// Start 启动 pipeline，连接音频源和其他组件
-func (p *Pipeline) Start() error {
+// Start 启动 pipeline 中除音频源外的所有组件
+// ctx 用于取消启动过程，启动失败时已启动的组件会被停止
+func (p *Pipeline) Start(ctx context.Context) error {
 	if p.source == nil {
 		return fmt.Errorf("no source component set")
 	}
@@ -98,72 +177,200 @@ func (p *Pipeline) Start() error {
 	}
 
 	// 启动所有组件
-	for _, comp := range p.components {
-		if err := comp.Start(); err != nil {
+	p.attach(p.source)
+	for i, comp := range p.components {
+		p.attach(comp)
+		err := ctx.Err()
+		if err == nil {
+			err = comp.Start()
+		}
+		if err != nil {
 			// 如果启动失败，停止已经启动的组件
 			p.source.Stop()
-			for _, c := range p.components {
-				if c != comp {
-					c.Stop()
-				}
+			for _, c := range p.components[:i] {
+				c.Stop()
 			}
-			return fmt.Errorf("failed to start component %s: %v",
-				comp.(interface{ GetName() string }).GetName(), err)
+			return fmt.Errorf("failed to start component %s: %w", componentName(comp), err)
 		}
-		logger.Info("Start component: %s", comp.(interface{ GetName() string }).GetName())
+		logger.Info("Start component: %s", componentName(comp))
 	}
 
 	// 启动健康检查
 	p.StartHealthCheck()
 
+	if p.supervisor != nil {
+		if p.clock != nil {
+			p.supervisor.SetClock(p.clock)
+		}
+		p.supervisor.Add(p.components...)
+		p.supervisor.Start()
+	}
+
 	return nil
 }
 
-// Connect 连接组件（不包括音频源）
+// SetSupervisor 设置组件监督器，需在 Start 之前调用
+// Start 时监督器会接管所有组件，Stop 时先于排空停止
+func (p *Pipeline) SetSupervisor(s *Supervisor) {
+	p.supervisor = s
+}
+
+// SetTraceRecorder 设置保存轮次追踪的 recorder，需在 Start 之前调用
+// Start 与热替换时会将其设置到所有组件
+func (p *Pipeline) SetTraceRecorder(r *TraceRecorder) {
+	p.traces = r
+}
+
+// SetClock 设置 pipeline 及其组件使用的时间来源，需在 Start 之前调用
+// Start 与热替换时会将其设置到所有组件与监督器，未设置时组件使用系统时间
+func (p *Pipeline) SetClock(clock Clock) {
+	p.clock = clock
+}
+
+// Clock 返回 pipeline 使用的时间来源
+func (p *Pipeline) Clock() Clock {
+	if p.clock == nil {
+		return RealClock
+	}
+	return p.clock
+}
+
+// SetEventBus 设置组件发布事件的会话事件总线，需在 Start 之前调用
+// Start 与热替换时会将其设置到所有组件
+func (p *Pipeline) SetEventBus(bus *EventBus) {
+	p.events = bus
+}
+
+// attach 将 pipeline 的 recorder、时间来源与事件总线设置到组件
+func (p *Pipeline) attach(c Component) {
+	if p.traces != nil {
+		if t, ok := c.(interface{ SetTraceRecorder(*TraceRecorder) }); ok {
+			t.SetTraceRecorder(p.traces)
+		}
+	}
+	if p.clock != nil {
+		if t, ok := c.(interface{ SetClock(Clock) }); ok {
+			t.SetClock(p.clock)
+		}
+	}
+	if p.events != nil {
+		if t, ok := c.(interface{ SetEventBus(*EventBus) }); ok {
+			t.SetEventBus(p.events)
+		}
+	}
+}
+
+// Connect 连接组件（不包括音频源），组件按顺序串联在音频源之后
 func (p *Pipeline) Connect(components ...Component) error {
 	if len(components) == 0 {
 		return fmt.Errorf("no components to connect")
 	}
+	if p.source == nil {
+		return fmt.Errorf("no source component set")
+	}
 
-	logger.Info("Initializing pipeline with %d components", len(components))
-	p.components = components
+	return p.ConnectGraph(NewGraph().Chain(append([]Component{p.source}, components...)...))
+}
 
-	// 连接音频源到第一个组件
-	p.source.Connect(p.components[0])
+// ConnectGraph 按组件图连接组件，支持扇出与扇入
+// 未设置音频源时，图中唯一的根节点会被作为音频源
+func (p *Pipeline) ConnectGraph(g *Graph) error {
+	if p.source == nil {
+		roots := g.Roots()
+		if len(roots) != 1 {
+			return fmt.Errorf("pipeline graph must have exactly one source, got %d", len(roots))
+		}
+		p.source = roots[0]
+	}
 
-	// 连接组件：将前一个组件的输出连接到后一个组件的输入
-	for i := 0; i < len(p.components)-1; i++ {
-		current := p.components[i]
-		next := p.components[i+1]
-		current.Connect(next)
+	built, err := g.Build()
+	if err != nil {
+		return err
 	}
 
+	// 音频源由外部单独启动，不纳入组件列表
+	components := make([]Component, 0, len(built))
+	for _, c := range built {
+		if c != p.source {
+			components = append(components, c)
+		}
+	}
+	if len(components) == 0 {
+		return fmt.Errorf("no components to connect")
+	}
+
+	logger.Info("Initializing pipeline with %d components", len(components))
+	p.components = components
 	return nil
 }
 
-// Stop 停止所有组件
-func (p *Pipeline) Stop() {
+// Stop 优雅停止 pipeline，可重复调用
+// 先停止音频源，其输出关闭后数据沿拓扑顺序从源到汇逐级排空，
+// 每个组件处理完已排队的数据包并等待其协程退出后再停止下一个。
+// ctx 到期时强制停止剩余组件，返回各组件未能正常退出的聚合错误
+func (p *Pipeline) Stop(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		p.stopErr = p.drain(ctx)
+	})
+	return p.stopErr
+}
+
+// drain 按拓扑顺序排空并停止所有组件
+func (p *Pipeline) drain(ctx context.Context) error {
+	// 等待进行中的热替换完成
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	if p.healthCheckTicker != nil {
 		p.healthCheckTicker.Stop()
 	}
+	if p.supervisor != nil {
+		// 排空过程中不再重启组件
+		p.supervisor.Stop()
+	}
 	close(p.stopCh)
 	if p.source != nil {
 		p.source.Stop()
 	}
-	for _, component := range p.components {
+
+	var errs []error
+	for i, component := range p.components {
+		if err := waitComponent(ctx, component); err != nil {
+			errs = append(errs, fmt.Errorf("drain %s: %w", componentName(component), err))
+			// 超时后不再等待，强制停止剩余组件
+			for _, c := range p.components[i:] {
+				c.Stop()
+			}
+			break
+		}
 		component.Stop()
 	}
+
+	if err := errors.Join(errs...); err != nil {
+		logger.Error("Pipeline stopped with errors: %v", err)
+		return err
+	}
+	logger.Info("Pipeline drained and stopped")
+	return nil
+}
+
+// waitComponent 等待组件退出，未实现 Wait 的组件视为立即退出
+func waitComponent(ctx context.Context, c Component) error {
+	if w, ok := c.(interface{ Wait(context.Context) error }); ok {
+		return w.Wait(ctx)
+	}
+	return nil
 }
 
 // StartHealthCheck 启动健康检查
 func (p *Pipeline) StartHealthCheck() {
-	p.healthCheckTicker = time.NewTicker(p.healthCheckInterval)
+	p.healthCheckTicker = p.Clock().NewTicker(p.healthCheckInterval)
 	go func() {
 		for {
 			select {
 			case <-p.stopCh:
 				return
-			case <-p.healthCheckTicker.C:
+			case <-p.healthCheckTicker.C():
 				p.checkComponentsHealth()
 			}
 		}
@@ -179,10 +386,23 @@ func (p *Pipeline) checkComponentsHealth() {
 	var stateChanges []string
 	var droppedInfo []string
 
-	for _, comp := range p.components {
+	now := p.Clock().Now()
+	trackers := make(map[Component]*healthTracker, len(p.healthTrackers))
+	for _, comp := range p.Components() {
 		health := comp.GetHealth()
 		lastHealth, exists := p.lastHealthCheck[comp.GetID()]
 
+		// 按阈值判定队列堆积、停滞与错误率，必要时切换组件状态
+		tracker := p.healthTrackers[comp]
+		if tracker == nil {
+			tracker = &healthTracker{last: health, lastProgress: now, detected: ComponentStateRunning}
+		} else {
+			tracker.observe(health, now)
+		}
+		health = p.applyHealthThresholds(comp, tracker, health, now)
+		tracker.last = health
+		trackers[comp] = tracker
+
 		// 检查组件状态变化
 		if !exists || lastHealth.State != health.State {
 			stateChanges = append(stateChanges, fmt.Sprintf("%s:%s->%s",
@@ -196,18 +416,21 @@ func (p *Pipeline) checkComponentsHealth() {
 		}
 
 		// 收集组件健康信息
-		healthInfo = append(healthInfo, fmt.Sprintf("[%s]: state=%s in=%d out=%d proc=%d drop=%d err=%v",
+		healthInfo = append(healthInfo, fmt.Sprintf("[%s]: state=%s in=%d out=%d proc=%d drop=%d blocked=%d coalesced=%d err=%v",
 			comp.(interface{ GetName() string }).GetName(),
 			health.State,
 			health.InputQueueSize,
 			health.OutputQueueSize,
 			health.ProcessedCount,
 			health.DroppedCount,
+			health.Backpressure.Blocked,
+			health.Backpressure.Coalesced,
 			health.LastError != nil))
 
 		// 更新最后检查的状态
 		p.lastHealthCheck[comp.GetID()] = health
 	}
+	p.healthTrackers = trackers
 
 	// 构建完整的健康状态日志
 	var logParts []string
@@ -244,6 +467,13 @@ func (p *Pipeline) GetAllComponentsHealth() map[interface{}]ComponentHealth {
 	return result
 }
 
+// SetHealthThresholds 设置健康检查将组件置为 Warning 或 Error 的阈值
+func (p *Pipeline) SetHealthThresholds(thresholds HealthThresholds) {
+	p.healthLock.Lock()
+	defer p.healthLock.Unlock()
+	p.healthThresholds = thresholds
+}
+
 // SetHealthCheckInterval 设置健康检查间隔
 func (p *Pipeline) SetHealthCheckInterval(interval time.Duration) {
 	p.healthCheckInterval = interval
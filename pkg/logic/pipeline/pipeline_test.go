@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"streamlink/internal/config"
+	"streamlink/pkg/logger"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger(&config.LogConfig{Level: "error"})
+	os.Exit(m.Run())
+}
+
+func TestPipeline_StopDrainsInFlightPackets(t *testing.T) {
+	source := newPassthrough("source")
+	slow := newPassthrough("slow")
+	slow.SetProcess(func(packet Packet) {
+		time.Sleep(5 * time.Millisecond)
+		slow.ForwardPacket(packet)
+	})
+	sink := newPassthrough("sink")
+
+	p := NewPipelineWithSource(source)
+	assert.NoError(t, p.Connect(slow, sink))
+	assert.NoError(t, p.Start(context.Background()))
+
+	var received []interface{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// 输出关闭后循环结束，不会泄漏
+		for packet := range sink.GetOutputChan() {
+			received = append(received, packet.Data)
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		p.Process(i)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, p.Stop(ctx))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sink output channel not closed")
+	}
+	assert.Len(t, received, 20)
+	assert.Equal(t, ComponentStateStopped, slow.GetHealth().State)
+
+	// 停止后再注入数据不会 panic
+	p.Process(99)
+	assert.NoError(t, p.Stop(ctx))
+}
+
+func TestPipeline_StopTimeout(t *testing.T) {
+	source := newPassthrough("source")
+	stuck := newPassthrough("stuck")
+	release := make(chan struct{})
+	stuck.SetProcess(func(packet Packet) { <-release })
+	defer close(release)
+
+	p := NewPipelineWithSource(source)
+	assert.NoError(t, p.Connect(stuck))
+	assert.NoError(t, p.Start(context.Background()))
+	p.Process("x")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := p.Stop(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "stuck")
+}
@@ -0,0 +1,310 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"streamlink/pkg/logger"
+	"sync"
+	"time"
+)
+
+// Params 组件构造参数，通常来自 config.yaml 中节点的 params
+type Params map[string]interface{}
+
+// lookup 返回参数值，字符串以 $ 开头时从环境变量读取
+func (p Params) lookup(key string) (interface{}, bool) {
+	v, ok := p[key]
+	if !ok || v == nil {
+		return nil, false
+	}
+	if s, ok := v.(string); ok && s != "" && s[0] == '$' {
+		return os.Getenv(s[1:]), true
+	}
+	return v, true
+}
+
+// String 返回字符串参数，不存在时返回 def
+func (p Params) String(key, def string) string {
+	v, ok := p.lookup(key)
+	if !ok {
+		return def
+	}
+	return fmt.Sprint(v)
+}
+
+// Int64 返回整数参数，支持数字与字符串形式，不存在或无法解析时返回 def
+func (p Params) Int64(key string, def int64) int64 {
+	v, ok := p.lookup(key)
+	if !ok {
+		return def
+	}
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	case string:
+		if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+			return i
+		}
+	}
+	logger.Warn("Params: invalid integer %s=%v, using default %d", key, v, def)
+	return def
+}
+
+// Int 返回整数参数，不存在或无法解析时返回 def
+func (p Params) Int(key string, def int) int {
+	return int(p.Int64(key, int64(def)))
+}
+
+// Float64 返回浮点数参数，支持数字与字符串形式，不存在或无法解析时返回 def
+func (p Params) Float64(key string, def float64) float64 {
+	v, ok := p.lookup(key)
+	if !ok {
+		return def
+	}
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	case string:
+		if f, err := strconv.ParseFloat(n, 64); err == nil {
+			return f
+		}
+	}
+	logger.Warn("Params: invalid number %s=%v, using default %v", key, v, def)
+	return def
+}
+
+// Bool 返回布尔参数，不存在或无法解析时返回 def
+func (p Params) Bool(key string, def bool) bool {
+	v, ok := p.lookup(key)
+	if !ok {
+		return def
+	}
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		if parsed, err := strconv.ParseBool(b); err == nil {
+			return parsed
+		}
+	}
+	logger.Warn("Params: invalid bool %s=%v, using default %v", key, v, def)
+	return def
+}
+
+// Duration 返回时长参数，支持 "500ms"、"2s" 等字符串形式，不存在或无法解析时返回 def
+func (p Params) Duration(key string, def time.Duration) time.Duration {
+	v, ok := p.lookup(key)
+	if !ok {
+		return def
+	}
+	switch d := v.(type) {
+	case time.Duration:
+		return d
+	case string:
+		if parsed, err := time.ParseDuration(d); err == nil {
+			return parsed
+		}
+	}
+	logger.Warn("Params: invalid duration %s=%v, using default %v", key, v, def)
+	return def
+}
+
+// WithDefaults 返回合并默认值后的参数，已有的参数优先
+func (p Params) WithDefaults(defaults Params) Params {
+	merged := make(Params, len(p)+len(defaults))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range p {
+		merged[k] = v
+	}
+	return merged
+}
+
+// ComponentFactory 根据参数创建组件
+type ComponentFactory func(params Params) (Component, error)
+
+// Registry 按类型名登记组件构造函数
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]ComponentFactory
+}
+
+// NewRegistry 创建空的组件注册表
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]ComponentFactory)}
+}
+
+// Register 登记组件类型，重复登记或构造函数为空时 panic
+func (r *Registry) Register(typeName string, factory ComponentFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if factory == nil {
+		panic("pipeline: Register factory is nil for " + typeName)
+	}
+	if _, dup := r.factories[typeName]; dup {
+		panic("pipeline: Register called twice for " + typeName)
+	}
+	r.factories[typeName] = factory
+}
+
+// Create 创建指定类型的组件
+func (r *Registry) Create(typeName string, params Params) (Component, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[typeName]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown component type %q", typeName)
+	}
+	if params == nil {
+		params = Params{}
+	}
+	return factory(params)
+}
+
+// Types 返回已登记的组件类型，按名称排序
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// defaultRegistry 全局注册表，各组件包在 init 中登记
+var defaultRegistry = NewRegistry()
+
+// Register 在全局注册表中登记组件类型
+func Register(typeName string, factory ComponentFactory) {
+	defaultRegistry.Register(typeName, factory)
+}
+
+// NewComponent 使用全局注册表创建组件
+func NewComponent(typeName string, params Params) (Component, error) {
+	return defaultRegistry.Create(typeName, params)
+}
+
+// RegisteredTypes 返回全局注册表中已登记的组件类型
+func RegisteredTypes() []string {
+	return defaultRegistry.Types()
+}
+
+func init() {
+	Register("turn_manager", func(params Params) (Component, error) {
+		cfg := DefaultTurnManagerConfig()
+		cfg.SilenceTimeout = params.Duration("silence_timeout", cfg.SilenceTimeout)
+		cfg.MaxTurnDuration = params.Duration("max_turn_duration", cfg.MaxTurnDuration)
+		cfg.SpeechThreshold = params.Float64("speech_threshold", cfg.SpeechThreshold)
+		cfg.Language = params.String("language", cfg.Language)
+		cfg.MinSilenceTimeout = params.Duration("min_silence_timeout", cfg.MinSilenceTimeout)
+		cfg.MaxSilenceTimeout = params.Duration("max_silence_timeout", cfg.MaxSilenceTimeout)
+		cfg.ScoreTimeout = params.Duration("score_timeout", cfg.ScoreTimeout)
+		// 按语言的规则覆盖或新增默认规则，可由全局配置传入，也可在节点的 params 中声明
+		if v, ok := params["languages"]; ok && v != nil {
+			languages, err := endpointRulesParam(v)
+			if err != nil {
+				return nil, err
+			}
+			for lang, rules := range languages {
+				cfg.SetRules(lang, rules)
+			}
+		}
+		rules := cfg.Rules()
+		if n := params.Int("min_sentence_length", rules.MinSentenceLength); n != rules.MinSentenceLength {
+			rules.MinSentenceLength = n
+			cfg.SetRules(cfg.Language, rules)
+		}
+		tm := NewTurnManager(cfg)
+		// scorer 为空时按标点与固定的静音超时断句
+		if name := params.String("scorer", ""); name != "" {
+			scorer, err := NewScorer(name, params)
+			if err != nil {
+				return nil, err
+			}
+			tm.SetScorer(scorer)
+		}
+		tm.SetIgnoreTurn(true)
+		tm.SetUseInterrupt(params.Bool("interrupt", false))
+		tm.SetSpeculative(params.Bool("speculative", false))
+		return tm, nil
+	})
+	Register("barge_in", func(params Params) (Component, error) {
+		cfg := DefaultBargeInConfig()
+		cfg.MinDuration = params.Duration("min_duration", cfg.MinDuration)
+		cfg.EnergyThreshold = params.Float64("energy_threshold", cfg.EnergyThreshold)
+		cfg.Cooldown = params.Duration("cooldown", cfg.Cooldown)
+		return NewBargeIn(cfg), nil
+	})
+}
+
+// endpointRulesParam 解析 turn_manager 的 languages 参数
+// 支持调用方传入的 map[string]EndpointRules，以及节点 YAML 解析得到的
+// {lang: {punctuation_marks: [...], min_sentence_length: n}} 形式的通用 map
+func endpointRulesParam(v interface{}) (map[string]EndpointRules, error) {
+	if languages, ok := v.(map[string]EndpointRules); ok {
+		return languages, nil
+	}
+	languages, ok := asMapping(v)
+	if !ok {
+		return nil, fmt.Errorf("languages: expected a mapping of language to rules, got %T", v)
+	}
+	parsed := make(map[string]EndpointRules, len(languages))
+	for lang, raw := range languages {
+		fields, ok := asMapping(raw)
+		if !ok {
+			return nil, fmt.Errorf("languages.%s: expected a mapping, got %T", lang, raw)
+		}
+		var rules EndpointRules
+		for key, value := range fields {
+			switch key {
+			case "punctuation_marks":
+				marks, ok := value.([]interface{})
+				if !ok {
+					return nil, fmt.Errorf("languages.%s.punctuation_marks: expected a list, got %T", lang, value)
+				}
+				for _, mark := range marks {
+					m, ok := mark.(string)
+					if !ok {
+						return nil, fmt.Errorf("languages.%s.punctuation_marks: expected strings, got %T", lang, mark)
+					}
+					rules.PunctuationMarks = append(rules.PunctuationMarks, m)
+				}
+			case "min_sentence_length":
+				n, ok := value.(int)
+				if !ok {
+					return nil, fmt.Errorf("languages.%s.min_sentence_length: expected an integer, got %T", lang, value)
+				}
+				rules.MinSentenceLength = n
+			default:
+				return nil, fmt.Errorf("languages.%s: unknown field %q", lang, key)
+			}
+		}
+		parsed[lang] = rules
+	}
+	return parsed, nil
+}
+
+// asMapping 将 YAML 解析得到的映射转换为 map，解析到 Params 中时嵌套的映射同为 Params
+func asMapping(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case Params:
+		return m, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,143 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParams(t *testing.T) {
+	t.Setenv("PARAMS_TEST_APP_ID", "1300000000")
+	p := Params{
+		"rate":    16000,
+		"app_id":  "$PARAMS_TEST_APP_ID",
+		"stream":  true,
+		"timeout": "1500ms",
+		"ratio":   0.4,
+		"bad":     "x",
+	}
+
+	assert.Equal(t, 16000, p.Int("rate", 0))
+	assert.Equal(t, int64(1300000000), p.Int64("app_id", 0))
+	assert.Equal(t, "1300000000", p.String("app_id", ""))
+	assert.True(t, p.Bool("stream", false))
+	assert.Equal(t, 1500*time.Millisecond, p.Duration("timeout", 0))
+	assert.Equal(t, 7, p.Int("bad", 7))
+	assert.Equal(t, 0.4, p.Float64("ratio", 0))
+	assert.Equal(t, 16000.0, p.Float64("rate", 0))
+	assert.Equal(t, "def", p.String("missing", "def"))
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Register("passthrough", func(params Params) (Component, error) {
+		return newPassthrough(params.String("name", "passthrough")), nil
+	})
+
+	c, err := r.Create("passthrough", Params{"name": "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, "a", c.(*passthrough).GetName())
+
+	_, err = r.Create("missing", nil)
+	assert.Error(t, err)
+	assert.Panics(t, func() { r.Register("passthrough", func(Params) (Component, error) { return nil, nil }) })
+	assert.Equal(t, []string{"passthrough"}, r.Types())
+	assert.Contains(t, RegisteredTypes(), "turn_manager")
+}
+
+const testPipelineYAML = `
+nodes:
+  - name: source
+  - name: upper
+    type: passthrough
+    params: {name: upper}
+  - name: transcript
+    type: passthrough
+    params: {name: transcript}
+chain: [source, upper, sink]
+edges:
+  - from: upper
+    to: transcript
+    buffer_size: 10
+    backpressure: drop_oldest
+`
+
+func TestBuildGraph(t *testing.T) {
+	var def GraphDef
+	assert.NoError(t, yaml.Unmarshal([]byte(testPipelineYAML), &def))
+
+	r := NewRegistry()
+	var created []Params
+	r.Register("passthrough", func(params Params) (Component, error) {
+		created = append(created, params)
+		return newPassthrough(params.String("name", "")), nil
+	})
+
+	source := newPassthrough("source")
+	sink := newPassthrough("sink")
+	g, nodes, err := BuildGraph(def, BuildOptions{
+		Registry: r,
+		Bindings: map[string]Component{"source": source, "sink": sink},
+		Defaults: map[string]Params{"passthrough": {"name": "default", "rate": 16000}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 4)
+	assert.Same(t, sink, nodes["sink"])
+	// 节点参数优先于默认参数
+	assert.Equal(t, "upper", created[0].String("name", ""))
+	assert.Equal(t, 16000, created[0].Int("rate", 0))
+
+	p := NewPipeline()
+	assert.NoError(t, p.ConnectGraph(g))
+	assert.NoError(t, p.Start(context.Background()))
+	defer p.Stop(context.Background())
+
+	p.Process("hello")
+	assert.Equal(t, "hello", recv(t, sink.GetOutputChan()).Data)
+	assert.Equal(t, "hello", recv(t, nodes["transcript"].GetOutputChan()).Data)
+}
+
+func TestBuildGraph_Errors(t *testing.T) {
+	r := NewRegistry()
+	r.Register("passthrough", func(params Params) (Component, error) {
+		return newPassthrough("p"), nil
+	})
+	opts := BuildOptions{Registry: r, Bindings: map[string]Component{"source": newPassthrough("source")}}
+
+	cases := map[string]GraphDef{
+		"unknown type":  {Nodes: []NodeDef{{Name: "a", Type: "missing"}}},
+		"unbound node":  {Nodes: []NodeDef{{Name: "sink"}}},
+		"unknown node":  {Chain: []string{"source", "nowhere"}},
+		"duplicate":     {Nodes: []NodeDef{{Name: "a", Type: "passthrough"}, {Name: "a", Type: "passthrough"}}},
+		"bad edge mode": {Nodes: []NodeDef{{Name: "a", Type: "passthrough"}}, Edges: []EdgeDef{{From: "source", To: "a", Backpressure: "lossy"}}},
+	}
+	for name, def := range cases {
+		_, _, err := BuildGraph(def, opts)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestTurnManagerFactory_Languages(t *testing.T) {
+	var params Params
+	assert.NoError(t, yaml.Unmarshal([]byte(`
+language: ja
+languages:
+  ja: {punctuation_marks: ["。", "？"], min_sentence_length: 4}
+`), &params))
+
+	c, err := NewComponent("turn_manager", params)
+	assert.NoError(t, err)
+	tm := c.(*TurnManager)
+	assert.Equal(t, EndpointRules{PunctuationMarks: []string{"。", "？"}, MinSentenceLength: 4}, tm.rules)
+	// 未声明的语言保留默认规则
+	assert.Equal(t, DefaultTurnManagerConfig().Languages["en"], tm.config.Languages["en"])
+
+	// 无法识别的规则返回错误，而不是被忽略
+	_, err = NewComponent("turn_manager", Params{"languages": map[string]interface{}{"ja": "。"}})
+	assert.Error(t, err)
+	_, err = NewComponent("turn_manager", Params{"languages": []string{"ja"}})
+	assert.Error(t, err)
+}
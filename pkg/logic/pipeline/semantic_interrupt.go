@@ -0,0 +1,296 @@
+package pipeline
+
+import (
+	"context"
+	"streamlink/pkg/logger"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// InterruptDecision 代理说话期间用户的话的分类结果
+type InterruptDecision int
+
+const (
+	InterruptDecisionInterrupt   InterruptDecision = iota // 真正的打断，如提问、纠正、要求停下
+	InterruptDecisionBackchannel                          // 附和，如“嗯”“对”“okay”，代理继续说
+	InterruptDecisionNoise                                // 噪声或无意义的识别结果
+)
+
+// String 返回分类结果的字符串表示
+func (d InterruptDecision) String() string {
+	switch d {
+	case InterruptDecisionBackchannel:
+		return "backchannel"
+	case InterruptDecisionNoise:
+		return "noise"
+	default:
+		return "interrupt"
+	}
+}
+
+// MarshalText 事件输出 JSON 时使用字符串表示
+func (d InterruptDecision) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// ParseInterruptDecision 解析分类结果的字符串表示，无法识别时返回 false
+func ParseInterruptDecision(s string) (InterruptDecision, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "interrupt":
+		return InterruptDecisionInterrupt, true
+	case "backchannel":
+		return InterruptDecisionBackchannel, true
+	case "noise":
+		return InterruptDecisionNoise, true
+	}
+	return InterruptDecisionInterrupt, false
+}
+
+// InterruptClassifier 判断代理说话期间用户的话是否为真正的打断
+type InterruptClassifier interface {
+	Classify(ctx context.Context, text string) (InterruptDecision, error)
+}
+
+// RuleClassifier 按附和词与语气词的词表分类
+// 去掉标点与空白后，整句只由语气词组成时为噪声，由附和词（可夹杂语气词）组成时为附和，其余均为打断
+type RuleClassifier struct {
+	Backchannels []string
+	Fillers      []string
+}
+
+// DefaultRuleClassifier 返回中英文的默认词表
+func DefaultRuleClassifier() *RuleClassifier {
+	return &RuleClassifier{
+		Backchannels: []string{
+			"嗯", "对", "是", "好", "好的", "行", "是的", "对的", "没错", "明白", "了解", "哦", "噢", "喔",
+			"ok", "okay", "yeah", "yes", "yep", "right", "sure", "mhm", "uh-huh", "i see",
+		},
+		Fillers: []string{"呃", "额", "啊", "唔", "uh", "um", "hmm", "er", "ah"},
+	}
+}
+
+// Classify 实现 InterruptClassifier
+func (r *RuleClassifier) Classify(_ context.Context, text string) (InterruptDecision, error) {
+	normalized := normalizeUtterance(text)
+	if normalized == "" || composedOf(normalized, r.Fillers, nil) {
+		return InterruptDecisionNoise, nil
+	}
+	if composedOf(normalized, r.Backchannels, r.Fillers) {
+		return InterruptDecisionBackchannel, nil
+	}
+	return InterruptDecisionInterrupt, nil
+}
+
+// normalizeUtterance 转为小写并去掉标点与空白
+func normalizeUtterance(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) || unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, text)
+}
+
+// composedOf 判断 text 是否完全由 words 与 extra 中的词拼接而成，且至少包含一个 words 中的词
+func composedOf(text string, words, extra []string) bool {
+	// reachable[i] 记录 text[:i] 能否拼出，以及其中是否已包含 words 中的词
+	type state struct{ ok, matched bool }
+	reachable := make([]state, len(text)+1)
+	reachable[0].ok = true
+	step := func(i int, group []string, isWord bool) {
+		for _, w := range group {
+			w = normalizeUtterance(w)
+			if w == "" || !strings.HasPrefix(text[i:], w) {
+				continue
+			}
+			next := &reachable[i+len(w)]
+			next.ok = true
+			next.matched = next.matched || reachable[i].matched || isWord
+		}
+	}
+	for i := 0; i < len(text); i++ {
+		if reachable[i].ok {
+			step(i, words, true)
+			step(i, extra, false)
+		}
+	}
+	return reachable[len(text)].matched
+}
+
+// InterruptClassification 语义打断的分类结果
+type InterruptClassification struct {
+	Text     string            `json:"text"`
+	Decision InterruptDecision `json:"decision"`
+}
+
+// SemanticInterrupt 代理说话期间对用户的话分类，只让真正的打断通过
+// 通常接在 ASR 与 TurnManager 之间：
+//   - 代理未在说话时识别结果原样转发
+//   - 代理说话期间的附和与噪声被丢弃，不会开始新轮次，也就不会打断代理
+//   - BargeIn 发出的打断指令先暂缓，等到下一句识别结果分类后再决定转发或丢弃，超时未收到识别结果视为噪声
+//
+// 分类在派生协程中进行，结果经 Post 回到处理循环，分类期间打断指令照常处理；
+// 分类期间到达的识别结果排队，保证转发顺序与到达顺序一致
+type SemanticInterrupt struct {
+	*BaseComponent
+	playbackSource
+	classifier      InterruptClassifier
+	holdTimeout     time.Duration // 暂缓的打断指令等待识别结果的最长时间
+	classifyTimeout time.Duration // 单次分类的最长时间，超时按打断处理
+	held            *Packet       // 暂缓的打断指令，只在处理循环中访问
+	classifying     *Packet       // 正在分类的识别结果，只在处理循环中访问
+	classifyingHeld *Packet       // 分类开始时暂缓的打断指令，由该次分类决定去留
+	cancelClassify  context.CancelFunc
+	classifySeq     int      // 每次分类或取消分类时递增，用于丢弃过期的分类结果
+	queued          []Packet // 分类期间到达的数据包
+}
+
+// NewSemanticInterrupt 创建语义打断组件，需通过 SetPlayback 提供输出端的播放进度
+func NewSemanticInterrupt(classifier InterruptClassifier, holdTimeout time.Duration) *SemanticInterrupt {
+	s := &SemanticInterrupt{
+		BaseComponent:   NewBaseComponent("SemanticInterrupt", 100),
+		classifier:      classifier,
+		holdTimeout:     holdTimeout,
+		classifyTimeout: time.Second,
+	}
+	s.SetProcess(s.processPacket)
+	s.SetFlushHandler(s.flush)
+	// 送往 TurnManager 的文本不允许丢弃
+	s.SetBackpressure(BlockPolicy(0))
+	// 与 TurnManager 一样不按轮次过滤识别结果
+	s.SetIgnoreTurn(true)
+	s.RegisterCommandHandler(PacketCommandInterrupt, s.handleInterrupt)
+	return s
+}
+
+// speaking 判断代理是否在说话，有暂缓的打断指令时视为仍在说话
+func (s *SemanticInterrupt) speaking() bool {
+	return s.held != nil || s.position().Playing
+}
+
+// processPacket 分类进行中时排队，否则处理识别结果
+func (s *SemanticInterrupt) processPacket(packet Packet) {
+	if s.classifying != nil {
+		s.queued = append(s.queued, packet)
+		return
+	}
+	s.handleTranscript(packet)
+}
+
+// handleTranscript 代理说话期间对识别结果发起分类，其余数据包直接转发
+func (s *SemanticInterrupt) handleTranscript(packet Packet) {
+	text, ok := packet.Data.(string)
+	if !ok || !s.speaking() {
+		s.ForwardPacket(packet)
+		return
+	}
+
+	// 暂缓的打断指令已等到识别结果，分类期间不再按超时丢弃
+	s.StopTimer()
+	s.classifySeq++
+	seq := s.classifySeq
+	ctx, cancel := context.WithTimeout(s.Context(), s.classifyTimeout)
+	s.classifying, s.classifyingHeld, s.cancelClassify = &packet, s.held, cancel
+
+	s.Go(func() {
+		defer cancel()
+		decision, err := s.classifier.Classify(ctx, text)
+		s.Post(func() { s.applyDecision(seq, decision, err) })
+	})
+}
+
+// applyDecision 在处理循环中应用分类结果，再依次处理排队的数据包，分类已取消时丢弃结果
+func (s *SemanticInterrupt) applyDecision(seq int, decision InterruptDecision, err error) {
+	if seq != s.classifySeq || s.classifying == nil {
+		return
+	}
+	packet, held := *s.classifying, s.classifyingHeld
+	s.classifying, s.classifyingHeld, s.cancelClassify = nil, nil, nil
+	s.resolve(packet, held, s.checkDecision(packet, decision, err))
+
+	for len(s.queued) > 0 && s.classifying == nil {
+		next := s.queued[0]
+		s.queued = s.queued[1:]
+		s.handleTranscript(next)
+	}
+}
+
+// checkDecision 分类失败或超时时按打断处理，宁可误打断也不让用户无法打断代理
+func (s *SemanticInterrupt) checkDecision(packet Packet, decision InterruptDecision, err error) InterruptDecision {
+	if err != nil {
+		logger.Error("**%s** Failed to classify %q: %v", s.GetName(), packet.Data, err)
+		s.UpdateErrorStatus(err)
+		return InterruptDecisionInterrupt
+	}
+	return decision
+}
+
+// resolve 按分类结果转发或丢弃识别结果与分类开始时暂缓的打断指令
+// 分类期间新到达的打断指令继续暂缓，等待之后的识别结果
+func (s *SemanticInterrupt) resolve(packet Packet, held *Packet, decision InterruptDecision) {
+	text := packet.Data.(string)
+	s.Publish(EventInterruptClassified, packet.TurnSeq, InterruptClassification{Text: text, Decision: decision})
+	resolvesHeld := held != nil && s.held == held
+	if decision != InterruptDecisionInterrupt {
+		logger.Info("[TurnSeq: %d] **%s** Ignored %s while agent is speaking: %s", packet.TurnSeq, s.GetName(), decision, text)
+		if resolvesHeld {
+			s.dropHeld()
+		}
+		return
+	}
+
+	if resolvesHeld {
+		s.ForwardPacket(*held)
+		s.dropHeld()
+	}
+	s.ForwardPacket(packet)
+}
+
+// flush 冲刷或流结束时同步完成进行中与排队的分类，保证随后转发的指令位于这些识别结果之后
+func (s *SemanticInterrupt) flush() {
+	pending := s.queued
+	if s.classifying != nil {
+		pending = append([]Packet{*s.classifying}, pending...)
+		s.cancelClassify()
+		s.classifySeq++
+	}
+	s.classifying, s.classifyingHeld, s.cancelClassify, s.queued = nil, nil, nil, nil
+
+	for _, packet := range pending {
+		text, ok := packet.Data.(string)
+		if !ok || !s.speaking() {
+			s.ForwardPacket(packet)
+			continue
+		}
+		held := s.held
+		ctx, cancel := context.WithTimeout(s.Context(), s.classifyTimeout)
+		decision, err := s.classifier.Classify(ctx, text)
+		cancel()
+		s.resolve(packet, held, s.checkDecision(packet, decision, err))
+	}
+}
+
+// handleInterrupt 代理说话期间暂缓 BargeIn 的打断指令，其余打断指令直接转发
+func (s *SemanticInterrupt) handleInterrupt(packet Packet) {
+	if t, _ := packet.Data.(InterruptType); t != InterruptTypeBargeIn || !s.position().Playing {
+		s.ForwardPacket(packet)
+		return
+	}
+	logger.Info("**%s** Holding barge-in until the transcript is classified", s.GetName())
+	s.held = &packet
+	s.SetTimer(s.holdTimeout, s.expireHeld)
+}
+
+// expireHeld 暂缓的打断指令超时仍未收到识别结果，视为噪声
+func (s *SemanticInterrupt) expireHeld() {
+	logger.Info("**%s** No transcript within %v, dropping barge-in as noise", s.GetName(), s.holdTimeout)
+	s.Publish(EventInterruptClassified, 0, InterruptClassification{Decision: InterruptDecisionNoise})
+	s.dropHeld()
+}
+
+// dropHeld 丢弃暂缓的打断指令
+func (s *SemanticInterrupt) dropHeld() {
+	s.held = nil
+	s.StopTimer()
+}
@@ -0,0 +1,117 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleClassifier(t *testing.T) {
+	classifier := DefaultRuleClassifier()
+	cases := map[string]InterruptDecision{
+		"嗯。":            InterruptDecisionBackchannel,
+		"对对对，":          InterruptDecisionBackchannel,
+		"呃，好的":          InterruptDecisionBackchannel,
+		"Uh-huh, okay.": InterruptDecisionBackchannel,
+		"呃……":           InterruptDecisionNoise,
+		"":              InterruptDecisionNoise,
+		"等一下":           InterruptDecisionInterrupt,
+		"不对。":           InterruptDecisionInterrupt,
+		"好了别说了":         InterruptDecisionInterrupt,
+		"yesterday":     InterruptDecisionInterrupt,
+	}
+	for text, want := range cases {
+		decision, err := classifier.Classify(context.Background(), text)
+		assert.NoError(t, err)
+		assert.Equal(t, want, decision, text)
+	}
+}
+
+func TestSemanticInterrupt(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	bus := NewEventBus()
+	sub := bus.Subscribe(10, EventInterruptClassified)
+	playback := &fakePlayback{}
+	s := NewSemanticInterrupt(DefaultRuleClassifier(), 3*time.Second)
+	s.SetClock(clock)
+	s.SetEventBus(bus)
+	s.SetPlayback(playback)
+	s.SetInputChan(make(chan Packet, 10))
+	assert.NoError(t, s.Start())
+	defer s.Stop()
+
+	// 代理未在说话时原样转发
+	s.Process(Packet{Data: "嗯。"})
+	assert.Equal(t, "嗯。", recv(t, s.GetOutputChan()).Data)
+
+	// 代理说话期间丢弃附和，真正的打断照常转发
+	playback.playing.Store(true)
+	s.Process(Packet{Data: "对对。"})
+	s.Process(Packet{Data: "等一下，我想问别的"})
+	assert.Equal(t, "等一下，我想问别的", recv(t, s.GetOutputChan()).Data)
+	assert.Equal(t, InterruptClassification{Text: "对对。", Decision: InterruptDecisionBackchannel}, recvEvent(t, sub).Data)
+	assert.Equal(t, InterruptDecisionInterrupt, recvEvent(t, sub).Data.(InterruptClassification).Decision)
+
+	// BargeIn 的打断指令暂缓到识别结果分类之后
+	bargeIn := Packet{Command: PacketCommandInterrupt, Data: InterruptTypeBargeIn}
+	s.Process(bargeIn)
+	s.Process(Packet{Data: "好的。"})
+	s.Process(bargeIn)
+	s.Process(Packet{Data: "停一下"})
+	assert.Equal(t, PacketCommandInterrupt, recv(t, s.GetOutputChan()).Command)
+	assert.Equal(t, "停一下", recv(t, s.GetOutputChan()).Data)
+	recvEvent(t, sub)
+	recvEvent(t, sub)
+
+	// 超时未收到识别结果时视为噪声，暂缓的打断指令被丢弃
+	s.Process(bargeIn)
+	clock.BlockUntil(1)
+	clock.Advance(3 * time.Second)
+	assert.Equal(t, InterruptDecisionNoise, recvEvent(t, sub).Data.(InterruptClassification).Decision)
+	playback.playing.Store(false)
+	s.Process(Packet{Data: "嗯"})
+	assert.Equal(t, "嗯", recv(t, s.GetOutputChan()).Data)
+}
+
+// blockingClassifier 在 release 关闭前阻塞分类
+type blockingClassifier struct {
+	release chan struct{}
+}
+
+func (c *blockingClassifier) Classify(ctx context.Context, text string) (InterruptDecision, error) {
+	select {
+	case <-c.release:
+	case <-ctx.Done():
+		return InterruptDecisionInterrupt, ctx.Err()
+	}
+	return DefaultRuleClassifier().Classify(ctx, text)
+}
+
+func TestSemanticInterrupt_ClassifiesOffLoop(t *testing.T) {
+	classifier := &blockingClassifier{release: make(chan struct{})}
+	playback := &fakePlayback{}
+	playback.playing.Store(true)
+	bus := NewEventBus()
+	sub := bus.Subscribe(10, EventInterruptClassified)
+	s := NewSemanticInterrupt(classifier, 3*time.Second)
+	s.SetEventBus(bus)
+	s.SetPlayback(playback)
+	s.SetInputChan(make(chan Packet, 10))
+	assert.NoError(t, s.Start())
+	defer s.Stop()
+
+	// 分类进行中时打断指令照常转发，之后到达的识别结果排队
+	s.Process(Packet{Data: "等一下"})
+	s.Process(Packet{Data: "嗯。"})
+	s.Process(*GenInterruptPacket(1))
+	assert.Equal(t, PacketCommandInterrupt, recv(t, s.GetOutputChan()).Command)
+
+	// 分类完成后按到达顺序处理排队的识别结果
+	close(classifier.release)
+	assert.Equal(t, "等一下", recv(t, s.GetOutputChan()).Data)
+	assert.Equal(t, InterruptDecisionInterrupt, recvEvent(t, sub).Data.(InterruptClassification).Decision)
+	assert.Equal(t, InterruptDecisionBackchannel, recvEvent(t, sub).Data.(InterruptClassification).Decision)
+	assert.Empty(t, s.GetOutputChan())
+}
@@ -0,0 +1,90 @@
+package pipeline
+
+import "streamlink/pkg/logger"
+
+// StageFunc 处理一个输入，通过 emit 输出任意个结果
+// packet 为输入所在的数据包，用于读取轮次与追踪；返回的错误会被记录并计入组件健康状态
+type StageFunc[In, Out any] func(in In, packet Packet, emit func(Out)) error
+
+// Stage 类型化的处理阶段，组件只需提供转换函数，必要时注册指令处理函数
+// 生命周期、健康统计、channel 连接、背压与流控制指令沿用 BaseComponent，Stage 额外完成：
+//   - 输入的类型断言，In 为 AudioFrame 时按 SetInputFormat 声明的格式校验
+//   - 输出封装为数据包，继承输入的轮次与追踪
+//   - 打断时更新轮次并向下游转发，组件可通过 OnInterrupt 清理内部状态
+//
+// 例如将文本转为大写的组件：
+//
+//	upper := NewStage("Upper", 100, func(in string, _ Packet, emit func(string)) error {
+//		emit(strings.ToUpper(in))
+//		return nil
+//	})
+type Stage[In, Out any] struct {
+	*BaseComponent
+	transform   StageFunc[In, Out]
+	onInterrupt func(turnSeq int)
+}
+
+// NewStage 创建处理阶段，bufferSize 为输出 channel 的缓冲大小
+func NewStage[In, Out any](name string, bufferSize int, transform StageFunc[In, Out]) *Stage[In, Out] {
+	s := &Stage[In, Out]{
+		BaseComponent: NewBaseComponent(name, bufferSize),
+		transform:     transform,
+	}
+	s.SetProcess(s.processPacket)
+	s.RegisterCommandHandler(PacketCommandInterrupt, s.handleInterrupt)
+	return s
+}
+
+// OnInterrupt 设置收到打断时的回调，在更新轮次并转发打断指令之后调用，需在 Start 之前设置
+func (s *Stage[In, Out]) OnInterrupt(fn func(turnSeq int)) {
+	s.onInterrupt = fn
+}
+
+// processPacket 取出类型化的输入并调用转换函数
+func (s *Stage[In, Out]) processPacket(packet Packet) {
+	in, ok := s.expect(packet)
+	if !ok {
+		return
+	}
+	emit := func(out Out) {
+		s.ForwardPacket(Packet{
+			Data:    out,
+			Seq:     s.GetSeq(),
+			Src:     s,
+			TurnSeq: packet.TurnSeq,
+			Trace:   packet.Trace,
+		})
+		s.IncrSeq()
+	}
+	if err := s.transform(in, packet, emit); err != nil {
+		logger.Error("**%s** %v", s.GetName(), err)
+		s.UpdateErrorStatus(err)
+	}
+}
+
+// expect 将数据包中的数据断言为 In，失败时记录错误
+func (s *Stage[In, Out]) expect(packet Packet) (In, bool) {
+	var in In
+	if _, audio := any(in).(AudioFrame); audio {
+		frame, ok := s.ExpectAudioFrame(packet)
+		if !ok {
+			return in, false
+		}
+		return any(frame).(In), true
+	}
+	in, ok := packet.Data.(In)
+	if !ok {
+		s.HandleUnsupportedData(packet.Data)
+	}
+	return in, ok
+}
+
+// handleInterrupt 更新轮次并转发打断指令
+func (s *Stage[In, Out]) handleInterrupt(packet Packet) {
+	logger.Info("**%s** Received interrupt command for turn %d", s.GetName(), packet.TurnSeq)
+	s.SetCurTurnSeq(packet.TurnSeq)
+	s.ForwardPacket(packet)
+	if s.onInterrupt != nil {
+		s.onInterrupt(packet.TurnSeq)
+	}
+}
@@ -0,0 +1,76 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStage_Transform(t *testing.T) {
+	words := NewStage("Words", 10, func(in string, _ Packet, emit func(string)) error {
+		if in == "" {
+			return errors.New("empty text")
+		}
+		for _, w := range strings.Fields(in) {
+			emit(strings.ToUpper(w))
+		}
+		return nil
+	})
+	var interrupted []int
+	words.OnInterrupt(func(turnSeq int) { interrupted = append(interrupted, turnSeq) })
+
+	source := newPassthrough("source")
+	p := NewPipelineWithSource(source)
+	assert.NoError(t, p.Connect(words))
+	assert.NoError(t, p.Start(context.Background()))
+	defer p.Stop(context.Background())
+
+	trace := NewTurnTrace(2, nil)
+	p.inject(Packet{Data: "hello world", TurnSeq: 2, Trace: trace})
+	for _, want := range []string{"HELLO", "WORLD"} {
+		packet := recv(t, words.GetOutputChan())
+		assert.Equal(t, want, packet.Data)
+		assert.Equal(t, 2, packet.TurnSeq)
+		assert.Same(t, trace, packet.Trace)
+	}
+
+	// 类型不符与转换失败计入错误
+	p.inject(Packet{Data: 42, TurnSeq: 2})
+	p.inject(Packet{Data: "", TurnSeq: 2})
+	assert.Eventually(t, func() bool { return words.GetHealth().ErrorCount == 2 }, time.Second, time.Millisecond)
+
+	// 打断更新轮次并转发，旧轮次的数据被丢弃
+	p.SendInterrupt(3)
+	assert.Equal(t, PacketCommandInterrupt, recv(t, words.GetOutputChan()).Command)
+	p.inject(Packet{Data: "stale", TurnSeq: 2})
+	p.inject(Packet{Data: "fresh", TurnSeq: 3})
+	assert.Equal(t, "FRESH", recv(t, words.GetOutputChan()).Data)
+
+	assert.Equal(t, []int{3}, interrupted)
+	assert.Equal(t, int64(1), words.GetHealth().DroppedCount)
+}
+
+func TestStage_AudioFormat(t *testing.T) {
+	gain := NewStage("Gain", 10, func(in AudioFrame, _ Packet, emit func(AudioFrame)) error {
+		for i := range in.Samples {
+			in.Samples[i] *= 2
+		}
+		emit(in)
+		return nil
+	})
+	gain.SetIgnoreTurn(true)
+	gain.SetInputFormat(AudioFormat{SampleRate: 16000, Channels: 1, Format: SampleFormatS16})
+	gain.SetInputChan(make(chan Packet, 10))
+	assert.NoError(t, gain.Start())
+	defer gain.Stop()
+
+	gain.Process(Packet{Data: NewPCMFrame([]int16{1, 2}, 48000, 1, 0)})
+	gain.Process(Packet{Data: NewPCMFrame([]int16{1, 2}, 16000, 1, 0)})
+	frame := recv(t, gain.GetOutputChan()).Data.(AudioFrame)
+	assert.Equal(t, []int16{2, 4}, frame.Samples)
+	assert.Equal(t, int64(1), gain.GetHealth().ErrorCount)
+}
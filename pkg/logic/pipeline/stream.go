@@ -0,0 +1,113 @@
+package pipeline
+
+// 流控制指令的默认语义，组件通过 RegisterCommandHandler 注册同名指令后由组件自行处理
+//
+//   - Flush：调用 flush 回调输出内部缓存的数据，再向下游转发，流继续
+//   - EndOfStream：先处理暂存的数据包，再依次调用 flush 与 drain 回调，
+//     处理循环退出，等待派生协程结束后向下游转发并关闭输出
+//   - Pause：向下游转发，之后到达的数据包暂存，指令照常处理；
+//     暂存数超过 maxPendingPackets 时按组件输出边的背压策略丢弃：drop_oldest 丢弃最早暂存的数据包，
+//     其余策略丢弃新到达的数据包（暂停期间无法阻塞等待），被丢弃的池化音频帧随即释放
+//   - Resume：向下游转发，再按到达顺序处理暂存的数据包
+
+// maxPendingPackets 暂停期间最多暂存的数据包数，约为 10 秒的 20ms 音频帧
+const maxPendingPackets = 500
+
+// SetFlushHandler 设置冲刷回调，收到 Flush 与流结束时在处理循环中调用
+// 回调返回前需将缓存的数据全部输出，保证转发的指令位于这些数据之后
+func (b *BaseComponent) SetFlushHandler(handler func()) {
+	b.flushHandler = handler
+}
+
+// dispatchCommand 处理指令包，返回 true 表示流已结束、处理循环应退出
+func (b *BaseComponent) dispatchCommand(packet Packet) bool {
+	b.handlersLock.RLock()
+	handler, registered := b.commandHandlers[packet.Command]
+	b.handlersLock.RUnlock()
+	if registered {
+		handler(packet)
+		return false
+	}
+
+	switch packet.Command {
+	case PacketCommandFlush:
+		if b.flushHandler != nil {
+			b.flushHandler()
+		}
+		b.forwardCommand(packet)
+	case PacketCommandEndOfStream:
+		b.endOfStream = &packet
+		b.endStream()
+		return true
+	case PacketCommandPause:
+		b.paused = true
+		b.forwardCommand(packet)
+	case PacketCommandResume:
+		b.forwardCommand(packet)
+		b.resume()
+	default:
+		b.HandleCommandPacket(packet)
+	}
+	return false
+}
+
+// forwardCommand 向下游转发指令，设置了默认指令处理函数的组件（如 Tee）由其负责分发
+func (b *BaseComponent) forwardCommand(packet Packet) {
+	b.handlersLock.RLock()
+	handler := b.defaultCommandHandler
+	b.handlersLock.RUnlock()
+	if handler != nil {
+		handler(packet)
+		return
+	}
+	b.ForwardPacket(packet)
+}
+
+// holdPacket 暂停期间暂存数据包，超出上限时按背压策略丢弃
+func (b *BaseComponent) holdPacket(packet Packet) {
+	if len(b.pending) < maxPendingPackets {
+		b.pending = append(b.pending, packet)
+		return
+	}
+
+	dropOldest := b.GetBackpressure().Mode == BackpressureDropOldest
+	if dropOldest {
+		ReleasePacket(b.pending[0])
+		b.pending = append(b.pending[1:], packet)
+	} else {
+		ReleasePacket(packet)
+	}
+
+	b.healthLock.Lock()
+	defer b.healthLock.Unlock()
+	b.health.DroppedCount++
+	if dropOldest {
+		b.health.Backpressure.DroppedOldest++
+	} else {
+		b.health.Backpressure.DroppedNewest++
+	}
+}
+
+// resume 结束暂停并处理暂存的数据包
+func (b *BaseComponent) resume() {
+	b.paused = false
+	pending := b.pending
+	b.pending = nil
+	for _, packet := range pending {
+		b.processData(packet)
+	}
+}
+
+// endStream 处理暂存的数据包并冲刷缓存，用于流结束与上游关闭
+func (b *BaseComponent) endStream() {
+	b.healthLock.Lock()
+	b.health.State = ComponentStateStopping
+	b.healthLock.Unlock()
+	b.resume()
+	if b.flushHandler != nil {
+		b.flushHandler()
+	}
+	if b.drainHandler != nil {
+		b.drainHandler()
+	}
+}
@@ -0,0 +1,160 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newBuffering 返回缓存数据直到冲刷的组件，冲刷时在派生协程中输出拼接结果
+func newBuffering(name string) *passthrough {
+	p := newPassthrough(name)
+	var buffered string
+	p.SetProcess(func(packet Packet) {
+		buffered += packet.Data.(string)
+	})
+	p.SetFlushHandler(func() {
+		if buffered == "" {
+			return
+		}
+		data := buffered
+		buffered = ""
+		done := make(chan struct{})
+		p.Go(func() {
+			defer close(done)
+			p.ForwardPacket(Packet{Data: data})
+		})
+		<-done
+	})
+	return p
+}
+
+func TestStream_Flush(t *testing.T) {
+	p, sink := startSwapPipeline(t, newBuffering("buffer"))
+
+	p.Process("a")
+	p.Process("b")
+	p.SendCommand(PacketCommandFlush)
+	p.Process("c")
+	p.SendCommand(PacketCommandFlush)
+
+	// 缓存的数据先于冲刷指令输出，冲刷后流继续
+	assert.Equal(t, "ab", recv(t, sink.GetOutputChan()).Data)
+	assert.Equal(t, PacketCommandFlush, recv(t, sink.GetOutputChan()).Command)
+	assert.Equal(t, "c", recv(t, sink.GetOutputChan()).Data)
+	assert.Equal(t, PacketCommandFlush, recv(t, sink.GetOutputChan()).Command)
+}
+
+func TestStream_EndOfStream(t *testing.T) {
+	buffer := newBuffering("buffer")
+	p, sink := startSwapPipeline(t, buffer)
+
+	p.Process("a")
+	p.SendCommand(PacketCommandEndOfStream)
+
+	assert.Equal(t, "a", recv(t, sink.GetOutputChan()).Data)
+	assert.Equal(t, PacketCommandEndOfStream, recv(t, sink.GetOutputChan()).Command)
+
+	// 流结束后组件依次退出，输出端的 channel 被关闭
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, p.Wait(ctx))
+	assert.Equal(t, ComponentStateStopped, buffer.GetHealth().State)
+	_, ok := <-sink.GetOutputChan()
+	assert.False(t, ok)
+}
+
+func TestStream_PauseResume(t *testing.T) {
+	middle := newPassthrough("middle")
+	p, sink := startSwapPipeline(t, middle)
+
+	p.Process("a")
+	assert.Equal(t, "a", recv(t, sink.GetOutputChan()).Data)
+
+	p.SendCommand(PacketCommandPause)
+	assert.Equal(t, PacketCommandPause, recv(t, sink.GetOutputChan()).Command)
+	p.Process("b")
+	p.Process("c")
+	p.SendInterrupt(0)
+
+	// 暂停期间数据被暂存，指令照常通过
+	assert.Equal(t, PacketCommandInterrupt, recv(t, sink.GetOutputChan()).Command)
+	select {
+	case packet := <-sink.GetOutputChan():
+		t.Fatalf("unexpected packet while paused: %+v", packet)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	p.SendCommand(PacketCommandResume)
+	assert.Equal(t, PacketCommandResume, recv(t, sink.GetOutputChan()).Command)
+	assert.Equal(t, "b", recv(t, sink.GetOutputChan()).Data)
+	assert.Equal(t, "c", recv(t, sink.GetOutputChan()).Data)
+}
+
+func TestStream_PauseBounded(t *testing.T) {
+	hold := func(b *BaseComponent) []*AudioBuffer {
+		b.paused = true
+		bufs := make([]*AudioBuffer, maxPendingPackets+1)
+		for i := range bufs {
+			bufs[i] = NewSampleBuffer(160)
+			b.handleInput(Packet{Data: NewPooledPCMFrame(bufs[i], 16000, 1, 0)})
+		}
+		return bufs
+	}
+
+	// 默认丢弃新到达的数据包
+	b := NewBaseComponent("test", 1)
+	bufs := hold(b)
+	assert.Len(t, b.pending, maxPendingPackets)
+	assert.Equal(t, int32(0), bufs[maxPendingPackets].refs.Load())
+	assert.Equal(t, int32(1), bufs[0].refs.Load())
+	assert.Equal(t, int64(1), b.GetHealth().Backpressure.DroppedNewest)
+
+	// drop_oldest 丢弃最早暂存的数据包
+	b = NewBaseComponent("test", 1)
+	b.SetBackpressure(DropOldestPolicy())
+	bufs = hold(b)
+	assert.Len(t, b.pending, maxPendingPackets)
+	assert.Equal(t, int32(0), bufs[0].refs.Load())
+	assert.Equal(t, int32(1), bufs[maxPendingPackets].refs.Load())
+	assert.Equal(t, int64(1), b.GetHealth().DroppedCount)
+}
+
+func TestStream_EndOfStreamFanIn(t *testing.T) {
+	source := newPassthrough("source")
+	left := newPassthrough("left")
+	right := newPassthrough("right")
+	sink := newPassthrough("sink")
+
+	g := NewGraph()
+	g.AddEdge(source, left).AddEdge(source, right)
+	g.AddEdge(left, sink).AddEdge(right, sink)
+
+	built, err := g.Build()
+	assert.NoError(t, err)
+	startAll(t, built)
+
+	source.GetOutputChan() <- Packet{Command: PacketCommandEndOfStream}
+
+	// 两个分支都结束后只转发一次流结束指令，随后汇聚组件退出
+	assert.Equal(t, PacketCommandEndOfStream, recv(t, sink.GetOutputChan()).Command)
+	_, ok := <-sink.GetOutputChan()
+	assert.False(t, ok)
+}
+
+func TestTurnManager_FlushesSentence(t *testing.T) {
+	tm := NewTurnManager(DefaultTurnManagerConfig())
+	tm.SetIgnoreTurn(true)
+	p, sink := startSwapPipeline(t, tm)
+
+	// 没有结束标点的句子在流结束时作为新轮次发出
+	p.Process("你好")
+	p.SendCommand(PacketCommandEndOfStream)
+
+	packet := recv(t, sink.GetOutputChan())
+	assert.Equal(t, "你好", packet.Data)
+	assert.Equal(t, 1, packet.TurnSeq)
+	assert.Equal(t, PacketCommandEndOfStream, recv(t, sink.GetOutputChan()).Command)
+}
@@ -0,0 +1,232 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"streamlink/pkg/logger"
+	"sync"
+	"time"
+)
+
+// Restartable 由支持原地重启的组件实现，重启时保留输入输出 channel 与处理循环
+// 例如 ASR 组件在 websocket 断开后重新建立识别会话
+type Restartable interface {
+	Restart() error
+}
+
+// RestartPolicy 定义组件失败后的重启策略
+type RestartPolicy struct {
+	InitialBackoff time.Duration // 首次重启后再次重启前的等待时间
+	MaxBackoff     time.Duration // 退避时间上限
+	Multiplier     float64       // 每次重启后退避时间的增长倍数
+	MaxRestarts    int           // Window 内允许的最大重启次数，超过后升级处理
+	Window         time.Duration // 统计重启次数的时间窗口，0 表示整个会话
+}
+
+// DefaultRestartPolicy 返回默认的重启策略
+func DefaultRestartPolicy() RestartPolicy {
+	return RestartPolicy{
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		MaxRestarts:    5,
+		Window:         5 * time.Minute,
+	}
+}
+
+// backoff 返回第 n 次（从 1 开始）重启前的等待时间
+func (p RestartPolicy) backoff(n int) time.Duration {
+	if n <= 1 || p.Multiplier <= 1 {
+		return p.InitialBackoff
+	}
+	d := time.Duration(float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(n-1)))
+	if p.MaxBackoff > 0 && (d > p.MaxBackoff || d <= 0) {
+		return p.MaxBackoff
+	}
+	return d
+}
+
+// supervisedComponent 记录单个组件的重启情况
+type supervisedComponent struct {
+	component   Component
+	restarts    []time.Time // 窗口内的重启时间
+	nextAttempt time.Time   // 下一次允许重启的时间
+	escalated   bool
+}
+
+// Supervisor 监控组件健康状态，对进入 Error 状态的组件按策略退避重启
+// 超过最大重启次数或组件不支持重启时升级处理，通常由上层结束整个会话
+type Supervisor struct {
+	policy     RestartPolicy
+	interval   time.Duration
+	clock      Clock
+	components []*supervisedComponent
+	onEscalate func(Component, error)
+	mu         sync.Mutex
+	stopCh     chan struct{}
+	doneCh     chan struct{}
+	startOnce  sync.Once
+	stopOnce   sync.Once
+}
+
+// NewSupervisor 创建组件监督器
+func NewSupervisor(policy RestartPolicy) *Supervisor {
+	return &Supervisor{
+		policy:   policy,
+		interval: 200 * time.Millisecond,
+		clock:    RealClock,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// SetCheckInterval 设置健康检查间隔，需在 Start 之前调用
+func (s *Supervisor) SetCheckInterval(interval time.Duration) {
+	s.interval = interval
+}
+
+// SetClock 设置检查使用的时间来源，需在 Start 之前调用
+func (s *Supervisor) SetClock(clock Clock) {
+	s.clock = clock
+}
+
+// OnEscalate 设置升级处理回调，回调在监督协程中执行，不应阻塞
+func (s *Supervisor) OnEscalate(fn func(Component, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onEscalate = fn
+}
+
+// Add 添加被监督的组件
+func (s *Supervisor) Add(components ...Component) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range components {
+		s.components = append(s.components, &supervisedComponent{component: c})
+	}
+}
+
+// Replace 将被监督的组件替换为 next，重启记录随之清空
+func (s *Supervisor) Replace(old, next Component) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, sc := range s.components {
+		if sc.component == old {
+			s.components[i] = &supervisedComponent{component: next}
+			return
+		}
+	}
+}
+
+// Start 启动监督协程
+func (s *Supervisor) Start() {
+	s.startOnce.Do(func() {
+		go s.loop()
+	})
+}
+
+// Stop 停止监督，等待监督协程退出
+func (s *Supervisor) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.startOnce.Do(func() {
+		close(s.doneCh)
+	})
+	<-s.doneCh
+}
+
+func (s *Supervisor) loop() {
+	defer close(s.doneCh)
+
+	ticker := s.clock.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case now := <-ticker.C():
+			s.check(now)
+		}
+	}
+}
+
+// check 检查所有组件，对失败的组件执行重启或升级
+func (s *Supervisor) check(now time.Time) {
+	s.mu.Lock()
+	components := append([]*supervisedComponent(nil), s.components...)
+	s.mu.Unlock()
+
+	for _, sc := range components {
+		health := sc.component.GetHealth()
+		if sc.escalated || health.State != ComponentStateError {
+			continue
+		}
+		if now.Before(sc.nextAttempt) {
+			continue
+		}
+
+		sc.pruneRestarts(now, s.policy.Window)
+		name := componentName(sc.component)
+		lastErr := health.LastError
+		if lastErr == nil {
+			lastErr = errors.New("component entered error state")
+		}
+
+		restartable, ok := sc.component.(Restartable)
+		if !ok {
+			s.escalate(sc, fmt.Errorf("component %s failed and does not support restart: %w", name, lastErr))
+			continue
+		}
+		if len(sc.restarts) >= s.policy.MaxRestarts {
+			s.escalate(sc, fmt.Errorf("component %s exceeded %d restarts: %w", name, s.policy.MaxRestarts, lastErr))
+			continue
+		}
+
+		sc.restarts = append(sc.restarts, now)
+		attempt := len(sc.restarts)
+		sc.nextAttempt = now.Add(s.policy.backoff(attempt))
+
+		logger.Warn("Supervisor: restarting %s (attempt %d/%d), last error: %v", name, attempt, s.policy.MaxRestarts, lastErr)
+		if err := restartable.Restart(); err != nil {
+			logger.Error("Supervisor: failed to restart %s: %v", name, err)
+			if c, ok := sc.component.(interface{ ReportFailure(error) }); ok {
+				c.ReportFailure(err)
+			}
+			continue
+		}
+		if c, ok := sc.component.(interface{ SetState(ComponentState) }); ok {
+			c.SetState(ComponentStateRunning)
+		}
+		logger.Info("Supervisor: restarted %s", name)
+	}
+}
+
+// pruneRestarts 移除窗口之外的重启记录
+func (sc *supervisedComponent) pruneRestarts(now time.Time, window time.Duration) {
+	if window <= 0 {
+		return
+	}
+	kept := sc.restarts[:0]
+	for _, t := range sc.restarts {
+		if now.Sub(t) < window {
+			kept = append(kept, t)
+		}
+	}
+	sc.restarts = kept
+}
+
+// escalate 标记组件已升级并通知上层
+func (s *Supervisor) escalate(sc *supervisedComponent, err error) {
+	sc.escalated = true
+	logger.Error("Supervisor: escalating failure: %v", err)
+
+	s.mu.Lock()
+	fn := s.onEscalate
+	s.mu.Unlock()
+	if fn != nil {
+		fn(sc.component, err)
+	}
+}
@@ -0,0 +1,90 @@
+package pipeline
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flaky 是测试用的可重启组件，restartErr 非空时重启失败
+type flaky struct {
+	*passthrough
+	restarts   atomic.Int32
+	restartErr error
+}
+
+func (f *flaky) Restart() error {
+	f.restarts.Add(1)
+	return f.restartErr
+}
+
+func newSupervisor(policy RestartPolicy) (*Supervisor, chan error) {
+	s := NewSupervisor(policy)
+	s.SetCheckInterval(5 * time.Millisecond)
+	escalated := make(chan error, 1)
+	s.OnEscalate(func(c Component, err error) { escalated <- err })
+	return s, escalated
+}
+
+func TestSupervisor_RestartsFailedComponent(t *testing.T) {
+	c := &flaky{passthrough: newPassthrough("asr")}
+	s, escalated := newSupervisor(RestartPolicy{MaxRestarts: 3})
+	s.Add(c)
+	s.Start()
+	defer s.Stop()
+
+	c.ReportFailure(errors.New("websocket closed"))
+	assert.Eventually(t, func() bool { return c.restarts.Load() == 1 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return c.GetHealth().State == ComponentStateRunning }, time.Second, time.Millisecond)
+	assert.Empty(t, escalated)
+}
+
+func TestSupervisor_EscalatesAfterMaxRestarts(t *testing.T) {
+	c := &flaky{passthrough: newPassthrough("asr"), restartErr: errors.New("dial failed")}
+	s, escalated := newSupervisor(RestartPolicy{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     4 * time.Millisecond,
+		Multiplier:     2,
+		MaxRestarts:    3,
+	})
+	s.Add(c)
+	s.Start()
+	defer s.Stop()
+
+	c.ReportFailure(errors.New("websocket closed"))
+	select {
+	case err := <-escalated:
+		assert.Contains(t, err.Error(), "exceeded 3 restarts")
+	case <-time.After(time.Second):
+		t.Fatal("supervisor did not escalate")
+	}
+	assert.Equal(t, int32(3), c.restarts.Load())
+}
+
+func TestSupervisor_EscalatesNonRestartable(t *testing.T) {
+	c := newPassthrough("llm")
+	s, escalated := newSupervisor(DefaultRestartPolicy())
+	s.Add(c)
+	s.Start()
+	defer s.Stop()
+
+	cause := errors.New("boom")
+	c.ReportFailure(cause)
+	select {
+	case err := <-escalated:
+		assert.ErrorIs(t, err, cause)
+	case <-time.After(time.Second):
+		t.Fatal("supervisor did not escalate")
+	}
+}
+
+func TestRestartPolicy_Backoff(t *testing.T) {
+	p := RestartPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	assert.Equal(t, 100*time.Millisecond, p.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, p.backoff(2))
+	assert.Equal(t, 800*time.Millisecond, p.backoff(4))
+	assert.Equal(t, time.Second, p.backoff(10))
+}
@@ -0,0 +1,238 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"streamlink/pkg/logger"
+	"time"
+)
+
+// SwapQueuePolicy 定义热替换时旧组件输入队列中数据包的处理方式
+type SwapQueuePolicy int
+
+const (
+	SwapMoveQueued  SwapQueuePolicy = iota // 排队的数据包交由新组件处理（默认）
+	SwapDrainQueued                        // 旧组件处理完当前排队的数据包后再切换
+	SwapDropQueued                         // 丢弃排队的数据包，指令包保留
+)
+
+func (p SwapQueuePolicy) String() string {
+	switch p {
+	case SwapMoveQueued:
+		return "move"
+	case SwapDrainQueued:
+		return "drain"
+	case SwapDropQueued:
+		return "drop"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSwapQueuePolicy 解析配置或请求中的队列处理方式，空字符串返回默认值
+func ParseSwapQueuePolicy(s string) (SwapQueuePolicy, bool) {
+	if s == "" {
+		return SwapMoveQueued, true
+	}
+	for _, p := range []SwapQueuePolicy{SwapMoveQueued, SwapDrainQueued, SwapDropQueued} {
+		if p.String() == s {
+			return p, true
+		}
+	}
+	return SwapMoveQueued, false
+}
+
+// SwapOptions 热替换选项
+type SwapOptions struct {
+	Queue SwapQueuePolicy
+}
+
+// StateHandover 由需要在热替换时迁移内部状态的组件实现，如 LLM 的对话历史
+// 在旧组件停止后、新组件启动前调用
+type StateHandover interface {
+	HandOver(next Component)
+}
+
+// outputKeeper 由 BaseComponent 实现，停止时保留输出 channel
+type outputKeeper interface {
+	keepOutputOnStop()
+}
+
+// FindComponent 按名称查找组件，不存在时返回 nil
+func (p *Pipeline) FindComponent(name string) Component {
+	for _, c := range p.Components() {
+		if componentName(c) == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Replace 在运行中的 pipeline 里用 next 替换 old，不影响上下游组件
+// next 接管 old 的输入与输出 channel，并继承其轮次序号、背压策略与轮次过滤设置。
+// 切换期间上游的数据在输入 channel 中排队，排队的数据包按 opts.Queue 处理。
+// next 启动失败时会被标记为 Error，由 Supervisor 按重启策略处理
+func (p *Pipeline) Replace(ctx context.Context, old, next Component, opts SwapOptions) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.stopCh:
+		return fmt.Errorf("pipeline stopped")
+	default:
+	}
+
+	index := -1
+	for i, c := range p.components {
+		if c == old {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("component %s is not part of the pipeline", componentName(old))
+	}
+	switch old.(type) {
+	case *Tee, *Merge:
+		return fmt.Errorf("component %s is created by the graph and cannot be replaced", componentName(old))
+	}
+	keeper, ok := old.(outputKeeper)
+	if !ok {
+		return fmt.Errorf("component %s does not support hot swap", componentName(old))
+	}
+	if err := checkSwapFormats(old, next); err != nil {
+		return err
+	}
+
+	in, out := old.GetInputChan(), old.GetOutputChan()
+
+	if opts.Queue == SwapDrainQueued {
+		// 旧组件仍在运行，等待失败时保持原状
+		if err := waitQueueEmpty(ctx, in); err != nil {
+			return fmt.Errorf("drain %s before swap: %w", componentName(old), err)
+		}
+	}
+
+	// 先让监督器改为监督新组件，避免重启正在停止的旧组件
+	if p.supervisor != nil {
+		p.supervisor.Replace(old, next)
+	}
+
+	keeper.keepOutputOnStop()
+	old.Stop()
+	if err := waitComponent(ctx, old); err != nil {
+		logger.Warn("Pipeline: %s did not exit before swap: %v", componentName(old), err)
+	}
+
+	if opts.Queue == SwapDropQueued {
+		if dropped := dropQueuedData(in); dropped > 0 {
+			logger.Info("Pipeline: dropped %d queued packets of %s", dropped, componentName(old))
+		}
+	}
+
+	if h, ok := old.(StateHandover); ok {
+		h.HandOver(next)
+	}
+	inheritTurnState(old, next)
+	p.attach(next)
+
+	next.SetInputChan(in)
+	next.SetOutputChan(out)
+	inheritControlLane(old, next)
+	p.components[index] = next
+	p.replaced = append(p.replaced, old)
+
+	if err := next.Start(); err != nil {
+		if c, ok := next.(interface{ ReportFailure(error) }); ok {
+			c.ReportFailure(err)
+		}
+		return fmt.Errorf("failed to start component %s: %w", componentName(next), err)
+	}
+	logger.Info("Pipeline: replaced %s with %s (queue=%s)", componentName(old), componentName(next), opts.Queue)
+	return nil
+}
+
+// checkSwapFormats 校验新组件声明的输入输出格式与旧组件一致
+func checkSwapFormats(old, next Component) error {
+	o, ok := old.(FormatDeclarer)
+	if !ok {
+		return nil
+	}
+	n, ok := next.(FormatDeclarer)
+	if !ok {
+		return nil
+	}
+	if a, b := o.GetInputFormat(), n.GetInputFormat(); !a.IsZero() && !b.IsZero() && !a.Matches(b) {
+		return fmt.Errorf("audio format mismatch: %s expects %s, %s expects %s",
+			componentName(old), a, componentName(next), b)
+	}
+	if a, b := o.GetOutputFormat(), n.GetOutputFormat(); !a.IsZero() && !b.IsZero() && !a.Matches(b) {
+		return fmt.Errorf("audio format mismatch: %s outputs %s, %s outputs %s",
+			componentName(old), a, componentName(next), b)
+	}
+	return nil
+}
+
+// inheritTurnState 将旧组件的轮次序号与连接相关的设置复制到新组件
+func inheritTurnState(old, next Component) {
+	type turnState interface {
+		GetCurTurnSeq() int
+		SetCurTurnSeq(int)
+		GetIgnoreTurn() bool
+		SetIgnoreTurn(bool)
+		GetUseInterrupt() bool
+		SetUseInterrupt(bool)
+	}
+	if o, ok := old.(turnState); ok {
+		if n, ok := next.(turnState); ok {
+			n.SetCurTurnSeq(o.GetCurTurnSeq())
+			n.SetIgnoreTurn(o.GetIgnoreTurn())
+			n.SetUseInterrupt(o.GetUseInterrupt())
+		}
+	}
+	if o, ok := old.(BackpressureConfigurable); ok {
+		if n, ok := next.(BackpressureConfigurable); ok {
+			n.SetBackpressure(o.GetBackpressure())
+		}
+	}
+}
+
+// inheritControlLane 新组件接管旧组件的输入与输出控制通道
+func inheritControlLane(old, next Component) {
+	o, ok := old.(ControlLane)
+	if !ok {
+		return
+	}
+	if n, ok := next.(ControlLane); ok {
+		n.SetControlChan(o.GetControlChan())
+		n.SetControlOutChan(o.GetControlOutChan())
+	}
+}
+
+// waitQueueEmpty 等待 ch 中排队的数据包被取走
+func waitQueueEmpty(ctx context.Context, ch chan Packet) error {
+	ticker := time.NewTicker(5 * time.Millisecond)
+	defer ticker.Stop()
+	for len(ch) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// dropQueuedData 丢弃 ch 中排队的数据包，保留指令包，返回丢弃的数量
+func dropQueuedData(ch chan Packet) int {
+	packets := drain(ch)
+	kept := packets[:0]
+	for _, packet := range packets {
+		if packet.Command != PacketCommandNone {
+			kept = append(kept, packet)
+		}
+	}
+	dropped := len(packets) - len(kept)
+	refill(ch, kept)
+	return dropped
+}
@@ -0,0 +1,162 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newUpper 返回将字符串转为大写的组件，用于区分替换前后的输出
+func newUpper(name string) *passthrough {
+	p := newPassthrough(name)
+	p.SetProcess(func(packet Packet) {
+		packet.Data = strings.ToUpper(packet.Data.(string))
+		p.ForwardPacket(packet)
+	})
+	return p
+}
+
+func startSwapPipeline(t *testing.T, middle Component) (*Pipeline, *passthrough) {
+	t.Helper()
+	source := newPassthrough("source")
+	sink := newPassthrough("sink")
+	p := NewPipelineWithSource(source)
+	assert.NoError(t, p.Connect(middle, sink))
+	assert.NoError(t, p.Start(context.Background()))
+	t.Cleanup(func() { p.Stop(context.Background()) })
+	return p, sink
+}
+
+func TestPipeline_Replace(t *testing.T) {
+	old := newPassthrough("llm")
+	old.SetIgnoreTurn(false)
+	old.SetCurTurnSeq(3)
+	old.SetBackpressure(BlockPolicy(0))
+	p, sink := startSwapPipeline(t, old)
+
+	p.inject(Packet{Data: "a", TurnSeq: 3})
+	assert.Equal(t, "a", recv(t, sink.GetOutputChan()).Data)
+
+	next := newUpper("llm-v2")
+	assert.NoError(t, p.Replace(context.Background(), old, next, SwapOptions{}))
+	assert.Equal(t, ComponentStateStopped, old.GetHealth().State)
+	assert.Same(t, next, p.FindComponent("llm-v2"))
+	assert.Nil(t, p.FindComponent("llm"))
+
+	// 新组件继承轮次序号与背压策略，下游 channel 未被关闭
+	assert.Equal(t, 3, next.GetCurTurnSeq())
+	assert.False(t, next.GetIgnoreTurn())
+	assert.Equal(t, BackpressureBlock, next.GetBackpressure().Mode)
+
+	p.inject(Packet{Data: "b", TurnSeq: 3})
+	assert.Equal(t, "B", recv(t, sink.GetOutputChan()).Data)
+
+	// 被替换的组件保留其累计计数，供指标汇总
+	current, replaced := p.ComponentsWithReplaced()
+	assert.NotContains(t, current, Component(old))
+	assert.Equal(t, []Component{old}, replaced)
+	assert.EqualValues(t, 1, replaced[0].GetHealth().ProcessedCount)
+}
+
+func TestPipeline_ReplaceMovesQueuedPackets(t *testing.T) {
+	old := newPassthrough("asr")
+	release := make(chan struct{})
+	old.SetProcess(func(packet Packet) {
+		<-release
+		old.ForwardPacket(packet)
+	})
+	p, sink := startSwapPipeline(t, old)
+
+	for _, s := range []string{"a", "b", "c"} {
+		p.Process(s)
+	}
+	// 旧组件卡在第一个数据包上，其余数据包排队
+	assert.Eventually(t, func() bool { return len(old.GetInputChan()) == 2 }, time.Second, time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() { done <- p.Replace(context.Background(), old, newUpper("asr-v2"), SwapOptions{}) }()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	assert.NoError(t, <-done)
+
+	var got []interface{}
+	for i := 0; i < 3; i++ {
+		got = append(got, recv(t, sink.GetOutputChan()).Data)
+	}
+	assert.Equal(t, []interface{}{"a", "B", "C"}, got)
+}
+
+func TestPipeline_ReplaceDropsQueuedData(t *testing.T) {
+	old := newPassthrough("tts")
+	release := make(chan struct{})
+	old.SetProcess(func(packet Packet) {
+		<-release
+		old.ForwardPacket(packet)
+	})
+	p, sink := startSwapPipeline(t, old)
+
+	p.Process("a")
+	assert.Eventually(t, func() bool { return len(old.GetInputChan()) == 0 }, time.Second, time.Millisecond)
+	p.Process("b")
+	p.SendInterrupt(1)
+	assert.Eventually(t, func() bool {
+		return len(old.GetInputChan()) == 1 && len(old.GetControlChan()) == 1
+	}, time.Second, time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.Replace(context.Background(), old, newUpper("tts-v2"), SwapOptions{Queue: SwapDropQueued})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	assert.NoError(t, <-done)
+
+	// 正在处理的数据包照常输出，排队的数据被丢弃，指令包保留
+	assert.Equal(t, "a", recv(t, sink.GetOutputChan()).Data)
+	assert.Equal(t, PacketCommandInterrupt, recv(t, sink.GetOutputChan()).Command)
+}
+
+func TestPipeline_ReplaceDrainQueued(t *testing.T) {
+	old := newPassthrough("asr")
+	p, sink := startSwapPipeline(t, old)
+
+	for _, s := range []string{"a", "b"} {
+		p.Process(s)
+	}
+	assert.NoError(t, p.Replace(context.Background(), old, newUpper("asr-v2"), SwapOptions{Queue: SwapDrainQueued}))
+	p.Process("c")
+
+	assert.Equal(t, "a", recv(t, sink.GetOutputChan()).Data)
+	assert.Equal(t, "b", recv(t, sink.GetOutputChan()).Data)
+	assert.Equal(t, "C", recv(t, sink.GetOutputChan()).Data)
+}
+
+func TestPipeline_ReplaceErrors(t *testing.T) {
+	old := newPassthrough("llm")
+	p, _ := startSwapPipeline(t, old)
+
+	assert.Error(t, p.Replace(context.Background(), newPassthrough("other"), newPassthrough("x"), SwapOptions{}))
+
+	pcm := newPassthrough("pcm16k")
+	pcm.SetInputFormat(NewPCMFormat(16000, 1))
+	assert.NoError(t, p.Replace(context.Background(), old, pcm, SwapOptions{}))
+	pcm48k := newPassthrough("pcm48k")
+	pcm48k.SetInputFormat(NewPCMFormat(48000, 2))
+	assert.ErrorContains(t, p.Replace(context.Background(), pcm, pcm48k, SwapOptions{}), "format mismatch")
+
+	assert.NoError(t, p.Stop(context.Background()))
+	assert.Error(t, p.Replace(context.Background(), pcm, newPassthrough("late"), SwapOptions{}))
+}
+
+func TestParseSwapQueuePolicy(t *testing.T) {
+	for _, policy := range []SwapQueuePolicy{SwapMoveQueued, SwapDrainQueued, SwapDropQueued} {
+		parsed, ok := ParseSwapQueuePolicy(policy.String())
+		assert.True(t, ok)
+		assert.Equal(t, policy, parsed)
+	}
+	_, ok := ParseSwapQueuePolicy("flush")
+	assert.False(t, ok)
+}
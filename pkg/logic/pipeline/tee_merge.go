@@ -0,0 +1,244 @@
+package pipeline
+
+import (
+	"streamlink/pkg/logger"
+	"sync"
+)
+
+// Tee 将一路输入复制到多个分支，指令包会广播到每个分支
+type Tee struct {
+	*BaseComponent
+	branches []teeBranch
+	mu       sync.RWMutex
+}
+
+// teeBranch 是 Tee 的一个输出分支，每个分支拥有独立的背压策略
+type teeBranch struct {
+	ch      chan Packet
+	control chan Packet // 分支的控制通道，下游不支持时为 nil
+	policy  BackpressurePolicy
+}
+
+// NewTee 创建分流组件
+func NewTee(name string) *Tee {
+	t := &Tee{
+		BaseComponent: NewBaseComponent(name, 0),
+	}
+	// Tee 不关心轮次，所有数据原样转发
+	t.SetIgnoreTurn(true)
+	t.SetProcess(t.broadcast)
+	t.SetDefaultCommandHandler(t.broadcast)
+	t.closeOutputs = t.closeBranches
+	return t
+}
+
+// closeBranches 关闭所有分支，由 CloseOutput 在持有 sendLock 时调用
+func (t *Tee) closeBranches() {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, b := range t.branches {
+		close(b.ch)
+	}
+}
+
+// AddBranch 新增一个分支并返回其 channel，分支使用 Tee 自身的背压策略
+func (t *Tee) AddBranch(bufferSize int) chan Packet {
+	return t.AddBranchWithPolicy(bufferSize, t.GetBackpressure())
+}
+
+// AddBranchWithPolicy 新增一个使用指定背压策略的分支
+func (t *Tee) AddBranchWithPolicy(bufferSize int, policy BackpressurePolicy) chan Packet {
+	ch := make(chan Packet, bufferSize)
+	t.mu.Lock()
+	t.branches = append(t.branches, teeBranch{ch: ch, policy: policy})
+	t.mu.Unlock()
+	return ch
+}
+
+// branchControl 返回分支的控制通道，首次连接时创建
+func (t *Tee) branchControl(ch chan Packet) chan Packet {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.branches {
+		if t.branches[i].ch != ch {
+			continue
+		}
+		if t.branches[i].control == nil {
+			t.branches[i].control = make(chan Packet, controlBufferSize)
+		}
+		return t.branches[i].control
+	}
+	return nil
+}
+
+// Branches 返回所有分支的 channel
+func (t *Tee) Branches() []chan Packet {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	chans := make([]chan Packet, 0, len(t.branches))
+	for _, b := range t.branches {
+		chans = append(chans, b.ch)
+	}
+	return chans
+}
+
+// broadcast 将数据包发送到所有分支
+// 数据包按各分支的背压策略写入，指令包则必须送达每个分支
+func (t *Tee) broadcast(packet Packet) {
+	t.mu.RLock()
+	branches := append([]teeBranch(nil), t.branches...)
+	t.mu.RUnlock()
+
+	// 每个分支各持有池化音频帧的一个引用
+	if len(branches) == 0 {
+		ReleasePacket(packet)
+	}
+	for i := 1; i < len(branches); i++ {
+		RetainPacket(packet)
+	}
+	for _, b := range branches {
+		if isControlCommand(packet.Command) && b.control != nil {
+			t.DeliverPacket(b.control, packet, b.policy)
+			continue
+		}
+		t.DeliverPacket(b.ch, packet, b.policy)
+	}
+}
+
+// Connect 为 next 新增一个分支，返回 next 以支持链式调用
+func (t *Tee) Connect(next Component) Component {
+	logger.Info("Connect component %s to %s", t.GetName(), componentName(next))
+	branch := t.AddBranch(100)
+	next.SetInputChan(branch)
+	if lane, ok := next.(ControlLane); ok {
+		lane.SetControlChan(t.branchControl(branch))
+	}
+	return next
+}
+
+// GetOutputChan 返回第一个分支，便于健康检查统计
+func (t *Tee) GetOutputChan() chan Packet {
+	branches := t.Branches()
+	if len(branches) == 0 {
+		return nil
+	}
+	return branches[0]
+}
+
+// SetOutput 实现 Component 接口，回调作为一个新的分支
+func (t *Tee) SetOutput(output func(Packet)) {
+	ch := t.AddBranch(100)
+	go func() {
+		for packet := range ch {
+			if output != nil {
+				output(packet)
+			}
+		}
+	}()
+}
+
+// Merge 将多路输入汇聚为一路输出
+// 同一指令经由不同分支到达时只转发一次，流结束指令在所有输入都结束后转发
+type Merge struct {
+	*BaseComponent
+	inputs      []chan Packet
+	controls    []chan Packet // 各路输入的控制通道，汇聚到 Merge 自身的控制通道
+	lastCommand *Packet
+	endedInputs int // 已收到流结束指令的输入数
+}
+
+// NewMerge 创建汇聚组件
+func NewMerge(name string, bufferSize int) *Merge {
+	if bufferSize <= 0 {
+		bufferSize = 100
+	}
+	m := &Merge{
+		BaseComponent: NewBaseComponent(name, bufferSize),
+	}
+	m.SetIgnoreTurn(true)
+	m.SetInputChan(make(chan Packet, bufferSize))
+	m.SetControlChan(make(chan Packet, controlBufferSize))
+	m.SetProcess(m.forward)
+	m.SetDefaultCommandHandler(m.forwardCommand)
+	m.RegisterCommandHandler(PacketCommandEndOfStream, m.handleEndOfStream)
+	return m
+}
+
+// AddInput 新增一路输入，需在 Start 之前调用
+func (m *Merge) AddInput(ch chan Packet) {
+	m.inputs = append(m.inputs, ch)
+}
+
+// AddControlInput 新增一路输入的控制通道，需在 Start 之前调用
+func (m *Merge) AddControlInput(ch chan Packet) {
+	if ch != nil {
+		m.controls = append(m.controls, ch)
+	}
+}
+
+// Start 启动各路输入的汇聚协程和处理循环
+// 所有输入都关闭后关闭汇聚后的 channel，使处理循环排空退出
+func (m *Merge) Start() error {
+	merged := m.GetInputChan()
+	var wg sync.WaitGroup
+	for _, in := range m.inputs {
+		wg.Add(1)
+		go func(in chan Packet) {
+			defer wg.Done()
+			m.pipe(in, merged)
+		}(in)
+	}
+	go func() {
+		wg.Wait()
+		if !m.isStopped() {
+			close(merged)
+		}
+	}()
+	// 控制通道不会关闭，汇聚协程随组件停止退出
+	for _, in := range m.controls {
+		go m.pipe(in, m.GetControlChan())
+	}
+	return m.BaseComponent.Start()
+}
+
+// pipe 将 in 中的数据包转入 out，直到 in 关闭或组件停止
+func (m *Merge) pipe(in, out chan Packet) {
+	for {
+		select {
+		case <-m.GetStopCh():
+			return
+		case packet, ok := <-in:
+			if !ok {
+				return
+			}
+			select {
+			case out <- packet:
+			case <-m.GetStopCh():
+				return
+			}
+		}
+	}
+}
+
+func (m *Merge) forward(packet Packet) {
+	m.ForwardPacket(packet)
+}
+
+// forwardCommand 转发指令包，连续到达的相同指令视为同一指令的多个副本
+func (m *Merge) forwardCommand(packet Packet) {
+	if m.lastCommand != nil && m.lastCommand.Command == packet.Command && m.lastCommand.TurnSeq == packet.TurnSeq {
+		return
+	}
+	m.lastCommand = &packet
+	m.ForwardPacket(packet)
+}
+
+// handleEndOfStream 所有输入都结束后转发流结束指令，汇聚组件随各路输入关闭退出
+func (m *Merge) handleEndOfStream(packet Packet) {
+	m.endedInputs++
+	if m.endedInputs < len(m.inputs) {
+		return
+	}
+	m.lastCommand = &packet
+	m.ForwardPacket(packet)
+}
@@ -0,0 +1,55 @@
+package pipeline
+
+import "time"
+
+// 组件定时器：到期回调在处理循环中执行，与数据包处理串行，组件状态无需额外加锁
+// 每个组件只有一个定时器，重新设置时覆盖之前未到期的定时；处理循环退出时自动取消
+//
+// 派生协程（如外部请求）的结果通过 Post 交回处理循环，同样与数据包处理串行
+
+// postedBufferSize 等待处理循环执行的回调数
+const postedBufferSize = 16
+
+// SetTimer 在 d 之后于处理循环中调用 fn，覆盖之前未到期的定时，只能在处理循环中调用
+func (b *BaseComponent) SetTimer(d time.Duration, fn func()) {
+	// 每次创建新的定时器，避免旧定时器已触发但未取出的值被误认为新的到期
+	b.StopTimer()
+	b.timer = b.Clock().NewTimer(d)
+	b.timerFunc = fn
+}
+
+// StopTimer 取消未到期的定时，只能在处理循环中调用
+func (b *BaseComponent) StopTimer() {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.timer = nil
+	b.timerFunc = nil
+}
+
+// timerC 返回定时器的到期 channel，未设置定时时为 nil，在 select 中永远不会就绪
+func (b *BaseComponent) timerC() <-chan time.Time {
+	if b.timer == nil {
+		return nil
+	}
+	return b.timer.C()
+}
+
+// fireTimer 执行到期的定时回调
+func (b *BaseComponent) fireTimer() {
+	fn := b.timerFunc
+	b.timer = nil
+	b.timerFunc = nil
+	if fn != nil {
+		fn()
+	}
+}
+
+// Post 在处理循环中调用 fn，用于将派生协程的结果交回处理循环，可在任意协程中调用
+// 组件停止或处理循环退出后 fn 不再执行；调用方应限制未完成的请求数，避免缓冲写满后阻塞
+func (b *BaseComponent) Post(fn func()) {
+	select {
+	case b.posted <- fn:
+	case <-b.stopCh:
+	}
+}
@@ -0,0 +1,243 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"streamlink/pkg/logger"
+	"sync"
+	"time"
+)
+
+// 轮次追踪的打点名称，按链路顺序排列
+const (
+	SpanSpeechEnd     = "speech_end"      // 用户停止说话（由识别结果的音频偏移推算）
+	SpanASRFinal      = "asr_final"       // 收到句子的最终识别结果
+	SpanTurnCommit    = "turn_commit"     // TurnManager 确认轮次并发出完整句子
+	SpanLLMFirstToken = "llm_first_token" // LLM 输出首个 token
+	SpanTTSFirstAudio = "tts_first_audio" // TTS 输出首个音频数据
+	SpanFirstRTPOut   = "first_rtp_out"   // 首个回复音频包写入 WebRTC 轨道
+)
+
+// Span 追踪中的一个打点
+type Span struct {
+	Name      string    `json:"name"`
+	Component string    `json:"component"`
+	Time      time.Time `json:"time"`
+}
+
+// TurnTrace 一个轮次从用户说完话到回复音频发出的全链路打点
+// 随数据包在组件间传递，所有方法可并发调用，nil 接收者上的调用被忽略
+type TurnTrace struct {
+	mu        sync.Mutex
+	turnSeq   int
+	spans     []Span
+	completed bool
+	recorder  *TraceRecorder
+	clock     Clock // Mark 使用的时间来源，为空时使用系统时间
+}
+
+// NewTurnTrace 创建轮次追踪，recorder 为空时完成后只输出日志
+func NewTurnTrace(turnSeq int, recorder *TraceRecorder) *TurnTrace {
+	return &TurnTrace{turnSeq: turnSeq, recorder: recorder}
+}
+
+// SetTurnSeq 更新追踪所属的轮次，由 TurnManager 确认轮次时调用
+func (t *TurnTrace) SetTurnSeq(turnSeq int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.turnSeq = turnSeq
+}
+
+// Mark 以当前时间打点，同名打点只记录第一次
+func (t *TurnTrace) Mark(name, component string) bool {
+	if t == nil {
+		return false
+	}
+	now := time.Now()
+	if t.clock != nil {
+		now = t.clock.Now()
+	}
+	return t.MarkAt(name, component, now)
+}
+
+// MarkAt 以指定时间打点，同名打点只记录第一次，追踪完成后不再接受打点
+func (t *TurnTrace) MarkAt(name, component string, at time.Time) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.completed {
+		return false
+	}
+	for _, s := range t.spans {
+		if s.Name == name {
+			return false
+		}
+	}
+	t.spans = append(t.spans, Span{Name: name, Component: component, Time: at})
+	return true
+}
+
+// Span 返回指定名称的打点
+func (t *TurnTrace) Span(name string) (Span, bool) {
+	if t == nil {
+		return Span{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, s := range t.spans {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Span{}, false
+}
+
+// Between 返回两个打点之间的时长，任一打点缺失时返回 false
+func (t *TurnTrace) Between(from, to string) (time.Duration, bool) {
+	start, ok := t.Span(from)
+	if !ok {
+		return 0, false
+	}
+	end, ok := t.Span(to)
+	if !ok {
+		return 0, false
+	}
+	return end.Time.Sub(start.Time), true
+}
+
+// Complete 结束追踪，输出结构化事件并交给 recorder 保存，只生效一次
+func (t *TurnTrace) Complete() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.completed {
+		t.mu.Unlock()
+		return
+	}
+	t.completed = true
+	record := t.recordLocked()
+	recorder := t.recorder
+	t.mu.Unlock()
+
+	if data, err := json.Marshal(record); err == nil {
+		logger.Info("[TurnSeq: %d] turn_trace %s", record.TurnSeq, data)
+	}
+	if recorder != nil {
+		recorder.add(record)
+	}
+}
+
+// Record 返回当前打点的快照
+func (t *TurnTrace) Record() TurnTraceRecord {
+	if t == nil {
+		return TurnTraceRecord{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.recordLocked()
+}
+
+func (t *TurnTrace) recordLocked() TurnTraceRecord {
+	record := TurnTraceRecord{
+		TurnSeq:   t.turnSeq,
+		Spans:     append([]Span(nil), t.spans...),
+		OffsetsMs: make(map[string]int64, len(t.spans)),
+	}
+	if len(t.spans) == 0 {
+		return record
+	}
+	// 以最早的打点为起点计算各打点的偏移
+	origin := t.spans[0].Time
+	for _, s := range t.spans {
+		if s.Time.Before(origin) {
+			origin = s.Time
+		}
+	}
+	for _, s := range t.spans {
+		record.OffsetsMs[s.Name] = s.Time.Sub(origin).Milliseconds()
+	}
+	return record
+}
+
+// TurnTraceRecord 已完成轮次的追踪结果，用于日志与 API 输出
+type TurnTraceRecord struct {
+	TurnSeq   int              `json:"turn_seq"`
+	Spans     []Span           `json:"spans"`
+	OffsetsMs map[string]int64 `json:"offsets_ms"` // 各打点相对最早打点的偏移
+}
+
+// TraceRecorder 保存最近完成的轮次追踪，可并发使用
+type TraceRecorder struct {
+	mu       sync.Mutex
+	capacity int
+	records  []TurnTraceRecord
+	onRecord func(TurnTraceRecord)
+}
+
+// NewTraceRecorder 创建最多保留 capacity 条记录的 recorder
+func NewTraceRecorder(capacity int) *TraceRecorder {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &TraceRecorder{capacity: capacity}
+}
+
+func (r *TraceRecorder) add(record TurnTraceRecord) {
+	r.mu.Lock()
+	if len(r.records) == r.capacity {
+		r.records = append(r.records[:0], r.records[1:]...)
+	}
+	r.records = append(r.records, record)
+	onRecord := r.onRecord
+	r.mu.Unlock()
+	if onRecord != nil {
+		onRecord(record)
+	}
+}
+
+// OnRecord 设置每条追踪完成时的回调，记录不受容量限制时可据此另行保存
+func (r *TraceRecorder) OnRecord(fn func(TurnTraceRecord)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onRecord = fn
+}
+
+// Records 返回保存的追踪记录，按完成顺序排列
+func (r *TraceRecorder) Records() []TurnTraceRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]TurnTraceRecord(nil), r.records...)
+}
+
+// Get 返回指定轮次最近一次完成的追踪记录
+func (r *TraceRecorder) Get(turnSeq int) (TurnTraceRecord, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.records) - 1; i >= 0; i-- {
+		if r.records[i].TurnSeq == turnSeq {
+			return r.records[i], true
+		}
+	}
+	return TurnTraceRecord{}, false
+}
+
+// SetTraceRecorder 设置组件创建轮次追踪时使用的 recorder
+func (b *BaseComponent) SetTraceRecorder(recorder *TraceRecorder) {
+	b.traceLock.Lock()
+	defer b.traceLock.Unlock()
+	b.traceRecorder = recorder
+}
+
+// NewTurnTrace 创建关联组件 recorder 与时间来源的轮次追踪
+func (b *BaseComponent) NewTurnTrace(turnSeq int) *TurnTrace {
+	b.traceLock.Lock()
+	defer b.traceLock.Unlock()
+	trace := NewTurnTrace(turnSeq, b.traceRecorder)
+	trace.clock = b.Clock()
+	return trace
+}
@@ -0,0 +1,89 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTurnTrace_Mark(t *testing.T) {
+	start := time.Now()
+	trace := NewTurnTrace(1, nil)
+	assert.True(t, trace.MarkAt(SpanSpeechEnd, "asr", start))
+	assert.True(t, trace.MarkAt(SpanASRFinal, "asr", start.Add(300*time.Millisecond)))
+	// 同名打点只记录第一次
+	assert.False(t, trace.MarkAt(SpanASRFinal, "asr", start.Add(time.Second)))
+	assert.True(t, trace.MarkAt(SpanLLMFirstToken, "llm", start.Add(800*time.Millisecond)))
+
+	d, ok := trace.Between(SpanSpeechEnd, SpanLLMFirstToken)
+	assert.True(t, ok)
+	assert.Equal(t, 800*time.Millisecond, d)
+	_, ok = trace.Between(SpanSpeechEnd, SpanFirstRTPOut)
+	assert.False(t, ok)
+
+	record := trace.Record()
+	assert.Equal(t, 1, record.TurnSeq)
+	assert.Len(t, record.Spans, 3)
+	assert.Equal(t, map[string]int64{SpanSpeechEnd: 0, SpanASRFinal: 300, SpanLLMFirstToken: 800}, record.OffsetsMs)
+}
+
+func TestTurnTrace_Nil(t *testing.T) {
+	var trace *TurnTrace
+	assert.False(t, trace.Mark(SpanASRFinal, "asr"))
+	trace.SetTurnSeq(2)
+	trace.Complete()
+	_, ok := trace.Span(SpanASRFinal)
+	assert.False(t, ok)
+}
+
+func TestTurnTrace_Complete(t *testing.T) {
+	recorder := NewTraceRecorder(2)
+	for seq := 1; seq <= 3; seq++ {
+		trace := NewTurnTrace(seq, recorder)
+		trace.Mark(SpanASRFinal, "asr")
+		trace.Complete()
+		trace.Complete()
+		// 完成后不再接受打点
+		assert.False(t, trace.Mark(SpanFirstRTPOut, "sink"))
+	}
+
+	records := recorder.Records()
+	assert.Len(t, records, 2)
+	assert.Equal(t, 2, records[0].TurnSeq)
+	assert.Equal(t, 3, records[1].TurnSeq)
+	_, ok := recorder.Get(1)
+	assert.False(t, ok)
+	record, ok := recorder.Get(3)
+	assert.True(t, ok)
+	assert.Len(t, record.Spans, 1)
+}
+
+func TestTurnManager_CommitsTrace(t *testing.T) {
+	recorder := NewTraceRecorder(10)
+	source := newPassthrough("source")
+	tm := NewTurnManager(DefaultTurnManagerConfig())
+	tm.SetIgnoreTurn(true)
+	sink := newPassthrough("sink")
+	p := NewPipelineWithSource(source)
+	p.SetTraceRecorder(recorder)
+	assert.NoError(t, p.Connect(tm, sink))
+	assert.NoError(t, p.Start(context.Background()))
+	defer p.Stop(context.Background())
+
+	trace := sink.NewTurnTrace(0)
+	trace.Mark(SpanASRFinal, "asr")
+	p.inject(Packet{Data: "你好，今天天气怎么样？", Trace: trace})
+
+	out := recv(t, sink.GetOutputChan())
+	assert.Same(t, trace, out.Trace)
+	_, ok := out.Trace.Span(SpanTurnCommit)
+	assert.True(t, ok)
+
+	// 输出端完成追踪后可按轮次查询
+	out.Trace.Complete()
+	record, ok := recorder.Get(out.TurnSeq)
+	assert.True(t, ok)
+	assert.Equal(t, []string{SpanASRFinal, SpanTurnCommit}, []string{record.Spans[0].Name, record.Spans[1].Name})
+}
@@ -0,0 +1,131 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 会话记录中发言的角色
+const (
+	TranscriptRoleUser  = "user"
+	TranscriptRoleAgent = "agent"
+)
+
+// TranscriptEntry 会话记录中的一条发言
+type TranscriptEntry struct {
+	Role          string           `json:"role"`
+	TurnSeq       int              `json:"turn_seq"`
+	Text          string           `json:"text"`
+	Time          time.Time        `json:"time"`                     // 用户的话被确认或代理回复生成结束的时间
+	Interrupted   bool             `json:"interrupted,omitempty"`    // 代理的回复未生成完或未播放完就被打断
+	InterruptType InterruptType    `json:"interrupt_type,omitempty"` // 打断回复的来源
+	Played        time.Duration    `json:"played,omitempty"`         // 回复被打断前已播放的时长
+	LatencyMs     map[string]int64 `json:"latency_ms,omitempty"`     // 代理回复所在轮次的追踪，各打点相对最早打点的偏移
+}
+
+// Transcript 记录一个会话中用户与代理的全部发言，可并发使用
+// 用户的话来自 TurnManager 的轮次事件，代理的回复来自 LLM 的回复事件，打断状态与延迟在之后补充
+type Transcript struct {
+	mu            sync.Mutex
+	entries       []TranscriptEntry
+	latencies     map[int]map[string]int64 // 按轮次保存的追踪偏移
+	interruptType InterruptType            // 最近一次打断的来源
+}
+
+// NewTranscript 创建空的会话记录
+func NewTranscript() *Transcript {
+	return &Transcript{latencies: make(map[int]map[string]int64)}
+}
+
+// Follow 在事件总线上同步记录发言，不经过订阅的缓冲区，事件不会因订阅者处理不及时而丢失
+func (t *Transcript) Follow(bus *EventBus) {
+	bus.Observe(t.Apply, EventTurnStarted, EventReply, EventInterrupt, EventPlaybackInterrupted)
+}
+
+// Apply 按事件记录发言或补充打断状态
+func (t *Transcript) Apply(event Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch data := event.Data.(type) {
+	case TurnEvent:
+		t.entries = append(t.entries, TranscriptEntry{
+			Role:    TranscriptRoleUser,
+			TurnSeq: event.TurnSeq,
+			Text:    data.Text,
+			Time:    event.Time,
+		})
+	case ReplyEvent:
+		entry := TranscriptEntry{
+			Role:        TranscriptRoleAgent,
+			TurnSeq:     event.TurnSeq,
+			Text:        data.Text,
+			Time:        event.Time,
+			Interrupted: data.Interrupted,
+		}
+		if entry.Interrupted {
+			entry.InterruptType = t.interruptType
+		}
+		t.entries = append(t.entries, entry)
+	case InterruptEvent:
+		t.interruptType = data.Type
+	case PlaybackPosition:
+		// 回复可能已生成完但未播放完，标记该轮次最近的一条回复
+		for i := len(t.entries) - 1; i >= 0; i-- {
+			entry := &t.entries[i]
+			if entry.Role == TranscriptRoleAgent && entry.TurnSeq == data.TurnSeq {
+				entry.Interrupted = true
+				entry.InterruptType = t.interruptType
+				entry.Played = data.TurnPlayed
+				break
+			}
+		}
+	}
+}
+
+// AddTrace 记录轮次追踪，代理回复的延迟取自所在轮次的追踪
+func (t *Transcript) AddTrace(record TurnTraceRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.latencies[record.TurnSeq] = record.OffsetsMs
+}
+
+// Entries 返回按时间顺序排列的发言
+func (t *Transcript) Entries() []TranscriptEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entries := append([]TranscriptEntry(nil), t.entries...)
+	for i := range entries {
+		if entries[i].Role == TranscriptRoleAgent {
+			entries[i].LatencyMs = t.latencies[entries[i].TurnSeq]
+		}
+	}
+	return entries
+}
+
+// Save 将发言以 JSON 数组写入 path，目录不存在时创建
+func (t *Transcript) Save(path string) error {
+	data, err := json.MarshalIndent(t.Entries(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadTranscript 读取 Save 保存的发言
+func LoadTranscript(path string) ([]TranscriptEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []TranscriptEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
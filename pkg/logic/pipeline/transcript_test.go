@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranscript_Follow(t *testing.T) {
+	bus := NewEventBus()
+	transcript := NewTranscript()
+	transcript.Follow(bus)
+	start := time.Unix(1700000000, 0)
+
+	bus.Publish(Event{Type: EventTurnStarted, Time: start, TurnSeq: 1, Data: TurnEvent{Text: "今天天气怎么样"}})
+	bus.Publish(Event{Type: EventReply, Time: start.Add(time.Second), TurnSeq: 1, Data: ReplyEvent{Text: "今天晴天。"}})
+	// 第二轮回复生成完后在播放中被打断
+	bus.Publish(Event{Type: EventTurnStarted, Time: start.Add(2 * time.Second), TurnSeq: 2, Data: TurnEvent{Text: "讲个故事"}})
+	bus.Publish(Event{Type: EventReply, Time: start.Add(3 * time.Second), TurnSeq: 2, Data: ReplyEvent{Text: "从前有座山。"}})
+	bus.Publish(Event{Type: EventInterrupt, TurnSeq: 2, Data: InterruptEvent{Type: InterruptTypeBargeIn}})
+	bus.Publish(Event{Type: EventPlaybackInterrupted, TurnSeq: 3, Data: PlaybackPosition{TurnSeq: 2, TurnPlayed: 800 * time.Millisecond}})
+	// 不相关的事件不记录
+	bus.Publish(Event{Type: EventTranscript, Data: TranscriptEvent{Text: "停", Final: true}})
+	bus.Close()
+
+	transcript.AddTrace(TurnTraceRecord{TurnSeq: 1, OffsetsMs: map[string]int64{SpanLLMFirstToken: 300}})
+
+	entries := transcript.Entries()
+	assert.Equal(t, []TranscriptEntry{
+		{Role: TranscriptRoleUser, TurnSeq: 1, Text: "今天天气怎么样", Time: start},
+		{Role: TranscriptRoleAgent, TurnSeq: 1, Text: "今天晴天。", Time: start.Add(time.Second),
+			LatencyMs: map[string]int64{SpanLLMFirstToken: 300}},
+		{Role: TranscriptRoleUser, TurnSeq: 2, Text: "讲个故事", Time: start.Add(2 * time.Second)},
+		{Role: TranscriptRoleAgent, TurnSeq: 2, Text: "从前有座山。", Time: start.Add(3 * time.Second),
+			Interrupted: true, InterruptType: InterruptTypeBargeIn, Played: 800 * time.Millisecond},
+	}, entries)
+
+	// 保存后读回的记录一致
+	path := filepath.Join(t.TempDir(), "transcripts", "session.json")
+	assert.NoError(t, transcript.Save(path))
+	loaded, err := LoadTranscript(path)
+	assert.NoError(t, err)
+	assert.Len(t, loaded, len(entries))
+	for i := range entries {
+		assert.True(t, entries[i].Time.Equal(loaded[i].Time))
+		loaded[i].Time = entries[i].Time
+	}
+	assert.Equal(t, entries, loaded)
+}
+
+func TestTranscript_InterruptedGeneration(t *testing.T) {
+	transcript := NewTranscript()
+	transcript.Apply(Event{Type: EventInterrupt, TurnSeq: 1, Data: InterruptEvent{Type: InterruptTypeSemantic}})
+	transcript.Apply(Event{Type: EventReply, TurnSeq: 1, Data: ReplyEvent{Text: "好的，我", Interrupted: true}})
+
+	entries := transcript.Entries()
+	assert.Len(t, entries, 1)
+	assert.True(t, entries[0].Interrupted)
+	assert.Equal(t, InterruptTypeSemantic, entries[0].InterruptType)
+	assert.Equal(t, "好的，我", entries[0].Text)
+}
+
+func TestTranscript_FollowLossless(t *testing.T) {
+	bus := NewEventBus()
+	transcript := NewTranscript()
+	transcript.Follow(bus)
+
+	// 会话记录不经过订阅缓冲区，事件再多也不会丢失发言
+	for i := 1; i <= 5000; i++ {
+		bus.Publish(Event{Type: EventTurnStarted, TurnSeq: i, Data: TurnEvent{Text: "好的"}})
+	}
+	assert.Len(t, transcript.Entries(), 5000)
+}
@@ -1,10 +1,13 @@
 package pipeline
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"streamlink/pkg/logger"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // InterruptType 定义打断类型
@@ -14,8 +17,39 @@ const (
 	InterruptTypeNone     InterruptType = iota
 	InterruptTypeCommand                // 用户指令打断
 	InterruptTypeSemantic               // 语义打断
+	InterruptTypeBargeIn                // 播放期间用户说话打断
 )
 
+// String 返回打断类型的字符串表示
+func (t InterruptType) String() string {
+	switch t {
+	case InterruptTypeCommand:
+		return "command"
+	case InterruptTypeSemantic:
+		return "semantic"
+	case InterruptTypeBargeIn:
+		return "barge_in"
+	default:
+		return "none"
+	}
+}
+
+// MarshalText 事件输出 JSON 时使用字符串表示
+func (t InterruptType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler，解析 MarshalText 的输出
+func (t *InterruptType) UnmarshalText(text []byte) error {
+	for _, candidate := range []InterruptType{InterruptTypeNone, InterruptTypeCommand, InterruptTypeSemantic, InterruptTypeBargeIn} {
+		if candidate.String() == string(text) {
+			*t = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown interrupt type %q", text)
+}
+
 // TurnState 定义轮次状态
 type TurnState int
 
@@ -31,17 +65,29 @@ type TurnInfo struct {
 	TurnSeq        int
 	StartTime      time.Time
 	LastUpdateTime time.Time
-	Text           string // ASR 识别出的文本
+	Text           string // 本轮发出的用户输入
 	State          TurnState
 	InterruptType  InterruptType
 }
 
+// EndpointRules 按标点判断句子结束的规则，不同语言分别配置
+type EndpointRules struct {
+	PunctuationMarks  []string // 表示句子结束的标点符号
+	MinSentenceLength int      // 按标点结束句子所需的最少字符数，不计标点与空白，更短的句子等待静音超时
+}
+
 // TurnManagerConfig 配置
 type TurnManagerConfig struct {
-	SilenceTimeout    time.Duration // 静音超时时间，超过这个时间认为句子结束
-	MaxTurnDuration   time.Duration // 最大轮次持续时间
-	MinSentenceLength int           // 最小句子长度
-	PunctuationMarks  []string      // 表示句子结束的标点符号
+	SilenceTimeout  time.Duration            // 静音超时时间，超过这个时间认为句子结束
+	MaxTurnDuration time.Duration            // 最大轮次持续时间
+	SpeechThreshold float64                  // 输入音频帧的 RMS 能量不低于该值时视为用户仍在说话
+	Language        string                   // 识别文本的语言，选用 Languages 中对应的规则
+	Languages       map[string]EndpointRules // 按语言配置的句子结束规则
+
+	// 以下用于语义断句，通过 SetScorer 开启：不再按标点立即结束句子，静音超时随缓存句子的完整度在两者之间调整
+	MinSilenceTimeout time.Duration // 完整度为 1 时的静音超时
+	MaxSilenceTimeout time.Duration // 完整度为 0 时的静音超时
+	ScoreTimeout      time.Duration // 单次评估的最长时间，失败或超时时使用 SilenceTimeout
 }
 
 // DefaultTurnManagerConfig 返回默认配置
@@ -49,9 +95,37 @@ func DefaultTurnManagerConfig() TurnManagerConfig {
 	return TurnManagerConfig{
 		SilenceTimeout:    2 * time.Second,
 		MaxTurnDuration:   30 * time.Second,
-		MinSentenceLength: 4,
-		PunctuationMarks:  []string{"。", "？", "！", ".", "?", "!"},
+		SpeechThreshold:   500,
+		Language:          "zh",
+		MinSilenceTimeout: 300 * time.Millisecond,
+		MaxSilenceTimeout: 3 * time.Second,
+		ScoreTimeout:      500 * time.Millisecond,
+		Languages: map[string]EndpointRules{
+			"zh": {
+				PunctuationMarks:  []string{"。", "？", "！", ".", "?", "!"},
+				MinSentenceLength: 2,
+			},
+			"en": {
+				PunctuationMarks:  []string{".", "?", "!"},
+				MinSentenceLength: 3,
+			},
+		},
+	}
+}
+
+// Rules 返回 Language 对应的句子结束规则，未配置时只按静音超时与最大轮次时长结束句子
+func (c TurnManagerConfig) Rules() EndpointRules {
+	return c.Languages[c.Language]
+}
+
+// SetRules 设置 lang 的句子结束规则，复制 Languages 以免修改共享的配置
+func (c *TurnManagerConfig) SetRules(lang string, rules EndpointRules) {
+	languages := make(map[string]EndpointRules, len(c.Languages)+1)
+	for k, v := range c.Languages {
+		languages[k] = v
 	}
+	languages[lang] = rules
+	c.Languages = languages
 }
 
 // TurnManager 组件
@@ -60,9 +134,19 @@ type TurnManager struct {
 	currentTurn    *TurnInfo
 	previousTurn   *TurnInfo
 	config         TurnManagerConfig
+	rules          EndpointRules // 当前语言的句子结束规则
 	sentenceBuffer string
-	lastUpdateTime time.Time
+	bufferStart    time.Time // 缓存中第一段文本到达的时间
+	lastActivity   time.Time // 最近一次识别结果到达或检测到语音的时间，零值表示尚未收到
+	speaking       bool      // VAD 报告用户正在说话，期间不按静音超时结束句子
+	scorer         CompletenessScorer
+	scoring        context.CancelFunc // 取消进行中的完整度评估，没有评估时为 nil
+	scoreSeq       int                // 每次评估或清空缓存时递增，用于丢弃过期的评估结果
+	speculative    bool               // 收到稳定的中间识别结果时向下游发出 SpeculativeTranscript
+	speculation    string             // 当前句子最近一次发出的推测输入，避免重复发送
+	silenceTimeout time.Duration      // 当前缓存句子的静音超时，语义断句时随完整度调整
 	metrics        TurnMetrics
+	trace          *TurnTrace // 最近一句识别结果的追踪，随缓存的句子一起发出
 }
 
 // NewTurnManager 创建新的 TurnManager
@@ -70,113 +154,311 @@ func NewTurnManager(config TurnManagerConfig) *TurnManager {
 	tm := &TurnManager{
 		BaseComponent:  NewBaseComponent("TurnManager", 100),
 		config:         config,
-		lastUpdateTime: time.Now(),
+		rules:          config.Rules(),
+		silenceTimeout: config.SilenceTimeout,
 	}
 	tm.SetProcess(tm.processPacket)
+	// 送往 LLM 的文本不允许丢弃
+	tm.SetBackpressure(BlockPolicy(0))
 	// register command handler
 	tm.RegisterCommandHandler(PacketCommandInterrupt, tm.handleCommandInterrupt)
+	// 冲刷或流结束时，缓存中未结束的句子作为新轮次发出
+	tm.SetFlushHandler(tm.flushSentence)
 	return tm
 }
 
+// SetScorer 开启语义断句，由 scorer 评估缓存句子的完整度并调整静音超时，需在 Start 之前调用
+func (tm *TurnManager) SetScorer(scorer CompletenessScorer) {
+	tm.scorer = scorer
+}
+
+// SetSpeculative 设置是否根据稳定的中间识别结果推测用户输入，下游 LLM 需开启推测生成，需在 Start 之前调用
+func (tm *TurnManager) SetSpeculative(enabled bool) {
+	tm.speculative = enabled
+}
+
 // processPacket 处理输入的数据包
 func (tm *TurnManager) processPacket(packet Packet) {
 	// 1. 处理 ASR 结果
 	if text, ok := packet.Data.(string); ok {
-		// log.Printf("**%s** handle asr string: %v", tm.GetName(), packet)
 		tm.handleASRResult(text, packet)
 		return
 	}
 
-	logger.Info("**%s** forward packet:%v", tm.GetName(), packet)
-	// 2. 转发其他类型的包
+	// 2. 语音起止、中间识别结果与输入音频用于判断用户是否仍在说话，不向下游转发
+	if activity, ok := packet.Data.(VoiceActivity); ok {
+		tm.handleVoiceActivity(activity)
+		return
+	}
+	if interim, ok := packet.Data.(InterimTranscript); ok {
+		tm.handleInterim(interim)
+		return
+	}
+	if frame, ok := packet.Data.(AudioFrame); ok {
+		tm.handleAudio(frame)
+		ReleasePacket(packet)
+		return
+	}
+
+	// 3. 转发其他类型的包
 	tm.ForwardPacket(packet)
 }
 
 func (tm *TurnManager) handleASRResult(text string, packet Packet) {
+	now := tm.Clock().Now()
+
+	// 距上一次语音活动已静音超时，缓存中的句子已经结束，先作为独立轮次发出
+	// 通常定时器已提前发出，这里处理定时器尚未执行的情况
+	if tm.sentenceBuffer != "" && tm.silenceTimedOut(now) {
+		tm.commitTurn()
+	}
+
 	// 更新时间戳
-	tm.lastUpdateTime = time.Now()
-	tm.metrics.TurnStartTs = time.Now().UnixMilli()
+	tm.lastActivity = now
+	tm.metrics.TurnStartTs = now.UnixMilli()
 	tm.metrics.TurnEndTs = 0
 
 	// 更新句子缓存
+	if tm.sentenceBuffer == "" {
+		tm.bufferStart = now
+	}
 	tm.sentenceBuffer += text
+	if packet.Trace != nil {
+		tm.trace = packet.Trace
+	}
 
-	// 检查是否需要创建新轮次
+	// 检查是否需要创建新轮次，否则等待静音超时
 	if tm.shouldCreateNewTurn() {
-		tm.IncrTurnSeq()
+		tm.commitTurn()
+		return
+	}
+	tm.scoreCompleteness()
+	tm.scheduleEndpoint(now)
+}
 
-		logger.Info("TurnManager: start new turn, seq: %d, cur text: %s", tm.GetCurTurnSeq(), tm.sentenceBuffer)
+// scoreCompleteness 语义断句时在派生协程中评估缓存句子的完整度，评估期间沿用当前的静音超时
+// 结果交回处理循环后按完整度在最短与最长静音超时之间线性调整：
+// 句子越完整，静音超时越短，简短的回答很快结束，停在半句话时等待用户继续说。
+// 新的识别结果到达时取消进行中的评估，缓存已变化的结果被丢弃
+func (tm *TurnManager) scoreCompleteness() {
+	if tm.scorer == nil {
+		return
+	}
+	tm.cancelScoring()
+	seq, text := tm.scoreSeq, tm.sentenceBuffer
+	ctx, cancel := context.WithTimeout(tm.Context(), tm.config.ScoreTimeout)
+	tm.scoring = cancel
+
+	tm.Go(func() {
+		defer cancel()
+		score, err := tm.scorer.Score(ctx, text)
+		tm.Post(func() { tm.applyScore(seq, text, score, err) })
+	})
+}
 
-		// 1. 先发送语义打断指令
-		if tm.GetUseInterrupt() {
-			tm.broadcastInterrupt(tm.GetCurTurnSeq(), InterruptTypeSemantic)
-		}
+// applyScore 在处理循环中应用完整度评估的结果，缓存已变化时丢弃
+func (tm *TurnManager) applyScore(seq int, text string, score float64, err error) {
+	if seq != tm.scoreSeq || text != tm.sentenceBuffer {
+		return
+	}
+	tm.scoring = nil
+	if err != nil {
+		logger.Error("TurnManager: failed to score completeness of %q: %v", text, err)
+		tm.UpdateErrorStatus(err)
+		tm.silenceTimeout = tm.config.SilenceTimeout
+		tm.scheduleEndpoint(tm.Clock().Now())
+		return
+	}
+	score = math.Max(0, math.Min(1, score))
+	span := tm.config.MaxSilenceTimeout - tm.config.MinSilenceTimeout
+	tm.silenceTimeout = tm.config.MaxSilenceTimeout - time.Duration(score*float64(span))
+	tm.scheduleEndpoint(tm.Clock().Now())
+	tm.Publish(EventUtteranceScored, tm.GetCurTurnSeq(), CompletenessEvent{
+		Text:           text,
+		Score:          score,
+		SilenceTimeout: tm.silenceTimeout,
+	})
+}
 
-		// 2. 等待一小段时间让打断指令传播
-		// time.Sleep(100 * time.Millisecond)
-
-		// 3. 发送当前缓存的完整句子
-		tm.metrics.TurnEndTs = time.Now().UnixMilli()
-		if tm.sentenceBuffer != "" {
-			previousMetrics := packet.TurnMetricStat
-			previousMetrics[fmt.Sprintf("%s_%d", tm.GetName(), tm.GetSeq())] = tm.metrics
-			packet.TurnMetricKeys = append(packet.TurnMetricKeys, fmt.Sprintf("%s_%d", tm.GetName(), tm.GetSeq()))
-
-			tm.ForwardPacket(Packet{
-				Data:           tm.sentenceBuffer,
-				Seq:            tm.GetSeq(),
-				TurnSeq:        tm.GetCurTurnSeq(),
-				TurnMetricStat: previousMetrics,
-				TurnMetricKeys: packet.TurnMetricKeys,
-			})
-		}
+// cancelScoring 取消进行中的完整度评估，其结果随之过期
+func (tm *TurnManager) cancelScoring() {
+	if tm.scoring != nil {
+		tm.scoring()
+		tm.scoring = nil
+	}
+	tm.scoreSeq++
+}
+
+// handleAudio 输入音频的能量达到阈值时记为语音活动，推迟静音超时
+func (tm *TurnManager) handleAudio(frame AudioFrame) {
+	if tm.config.SpeechThreshold <= 0 || frame.RMS() < tm.config.SpeechThreshold {
+		return
+	}
+	now := tm.Clock().Now()
+	tm.lastActivity = now
+	if tm.sentenceBuffer != "" {
+		tm.scheduleEndpoint(now)
+	}
+}
+
+// handleInterim 中间识别结果表明用户仍在说话，推迟静音超时
+// 开启推测时，稳定的中间结果连同缓存的句子作为推测的用户输入发往下游
+func (tm *TurnManager) handleInterim(interim InterimTranscript) {
+	now := tm.Clock().Now()
+	tm.lastActivity = now
+	if tm.sentenceBuffer != "" {
+		tm.scheduleEndpoint(now)
+	}
+
+	text := tm.sentenceBuffer + interim.Text
+	if !tm.speculative || !interim.Stable || text == tm.speculation {
+		return
+	}
+	tm.speculation = text
+	logger.Info("TurnManager: speculating on stable partial transcript: %s", text)
+	tm.ForwardPacket(Packet{
+		Data:    SpeculativeTranscript{Text: text},
+		TurnSeq: tm.GetCurTurnSeq(),
+	})
+}
+
+// handleVoiceActivity 记录 VAD 报告的说话状态，说话期间暂停静音超时，停止说话后重新计算
+func (tm *TurnManager) handleVoiceActivity(activity VoiceActivity) {
+	now := tm.Clock().Now()
+	tm.speaking = activity.Speaking
+	tm.lastActivity = now
+	if tm.sentenceBuffer != "" {
+		tm.scheduleEndpoint(now)
+	}
+}
+
+// scheduleEndpoint 按静音超时与最大轮次时长中较早的一个设置定时器
+func (tm *TurnManager) scheduleEndpoint(now time.Time) {
+	tm.SetTimer(tm.endpointDeadline().Sub(now), tm.handleEndpointTimer)
+}
+
+// endpointDeadline 返回缓存的句子最晚结束的时间，用户正在说话时只受最大轮次时长限制
+func (tm *TurnManager) endpointDeadline() time.Time {
+	deadline := tm.bufferStart.Add(tm.config.MaxTurnDuration)
+	if tm.speaking {
+		return deadline
+	}
+	if silence := tm.lastActivity.Add(tm.silenceTimeout); silence.Before(deadline) {
+		deadline = silence
+	}
+	return deadline
+}
+
+// handleEndpointTimer 静音超时或达到最大轮次时长，缓存的句子作为新轮次发出
+func (tm *TurnManager) handleEndpointTimer() {
+	if tm.sentenceBuffer == "" {
+		return
+	}
+	now := tm.Clock().Now()
+	if now.Before(tm.endpointDeadline()) {
+		tm.scheduleEndpoint(now)
+		return
+	}
+	logger.Info("TurnManager: endpoint timeout after %v of silence", now.Sub(tm.lastActivity))
+	tm.commitTurn()
+}
+
+// commitTurn 开始新轮次并发出缓存的完整句子
+func (tm *TurnManager) commitTurn() {
+	tm.IncrTurnSeq()
+
+	logger.Info("TurnManager: start new turn, seq: %d, cur text: %s", tm.GetCurTurnSeq(), tm.sentenceBuffer)
+
+	// 1. 先发送语义打断指令
+	if tm.GetUseInterrupt() {
+		tm.broadcastInterrupt(tm.GetCurTurnSeq(), InterruptTypeSemantic)
+	}
+
+	// 2. 发送当前缓存的完整句子
+	tm.metrics.TurnEndTs = tm.Clock().Now().UnixMilli()
+	if tm.sentenceBuffer != "" {
+		tm.Publish(EventTurnStarted, tm.GetCurTurnSeq(), TurnEvent{Text: tm.sentenceBuffer})
+		tm.ForwardPacket(Packet{
+			Data:    tm.sentenceBuffer,
+			Seq:     tm.GetSeq(),
+			TurnSeq: tm.GetCurTurnSeq(),
+			Trace:   tm.commitTrace(),
+		})
+	}
+
+	// 3. 创建新轮次
+	tm.createNewTurn(tm.GetCurTurnSeq())
+}
 
-		// 4. 创建新轮次
-		tm.createNewTurn(tm.GetCurTurnSeq())
+// flushSentence 将缓存中未结束的句子作为新轮次发出
+func (tm *TurnManager) flushSentence() {
+	if tm.sentenceBuffer != "" {
+		tm.commitTurn()
 	}
 }
 
 func (tm *TurnManager) shouldCreateNewTurn() bool {
-	// 1. 检查是否有结束标点
-	for _, mark := range tm.config.PunctuationMarks {
-		if strings.Contains(tm.sentenceBuffer, mark) {
+	// 1. 检查是否以结束标点结尾，过短的句子（如语气词）等待静音超时
+	// 语义断句时标点只作为完整度评估的依据，由静音超时结束句子
+	text := strings.TrimSpace(tm.sentenceBuffer)
+	for _, mark := range tm.rules.PunctuationMarks {
+		if tm.scorer == nil && strings.HasSuffix(text, mark) && sentenceLength(text) >= tm.rules.MinSentenceLength {
 			return true
 		}
 	}
 
-	// 2. 检查静音时间
-	if time.Since(tm.lastUpdateTime) > tm.config.SilenceTimeout {
+	// 2. 检查轮次持续时间，从缓存中第一段文本到达开始计算
+	// 静音超时由定时器检查
+	if tm.sentenceBuffer != "" && tm.Clock().Since(tm.bufferStart) > tm.config.MaxTurnDuration {
 		return true
 	}
 
-	// 3. 检查轮次持续时间
-	if tm.currentTurn != nil && time.Since(tm.currentTurn.StartTime) > tm.config.MaxTurnDuration {
-		return true
+	return false
+}
+
+// sentenceLength 返回句子的字符数，不计标点与空白
+func sentenceLength(text string) int {
+	n := 0
+	for _, r := range text {
+		if !unicode.IsPunct(r) && !unicode.IsSpace(r) {
+			n++
+		}
 	}
+	return n
+}
 
-	return false
+// silenceTimedOut 判断距上一次语音活动是否已超过静音超时
+func (tm *TurnManager) silenceTimedOut(now time.Time) bool {
+	if tm.lastActivity.IsZero() || tm.speaking {
+		return false
+	}
+	return now.Sub(tm.lastActivity) > tm.silenceTimeout
 }
 
 func (tm *TurnManager) handleCommandInterrupt(packet Packet) {
 	tm.IncrTurnSeq()
 
-	// 1. 先发送命令打断指令
-	tm.broadcastInterrupt(tm.GetCurTurnSeq(), InterruptTypeCommand)
-
-	// 2. 等待一小段时间让打断指令传播
-	time.Sleep(20 * time.Millisecond)
+	// 1. 先发送命令打断指令，打断来源（如 BargeIn）可在 Data 中注明打断类型
+	interruptType := InterruptTypeCommand
+	if t, ok := packet.Data.(InterruptType); ok {
+		interruptType = t
+	}
+	// 打断指令经由控制通道转发，下游先于排队的数据处理，无需等待传播
+	tm.broadcastInterrupt(tm.GetCurTurnSeq(), interruptType)
 
-	// 3. 如果有未处理的文本，作为新轮次的开始发送
+	// 2. 如果有未处理的文本，作为新轮次的开始发送
 	if tm.sentenceBuffer != "" {
+		tm.Publish(EventTurnStarted, tm.GetCurTurnSeq(), TurnEvent{Text: tm.sentenceBuffer})
 		tm.ForwardPacket(Packet{
 			Data:    tm.sentenceBuffer,
 			Seq:     0,
 			TurnSeq: tm.GetCurTurnSeq(),
+			Trace:   tm.commitTrace(),
 			Command: PacketCommandNone,
 		})
 	}
 
-	// 4. 创建新轮次
+	// 3. 创建新轮次
 	tm.createNewTurn(tm.GetCurTurnSeq())
 }
 
@@ -187,71 +469,44 @@ func (tm *TurnManager) createNewTurn(turnSeq int) {
 		tm.currentTurn.State = TurnStateComplete
 	}
 
-	// 创建新轮次
+	// 创建新轮次，记录本轮发出的用户输入
+	now := tm.Clock().Now()
 	tm.currentTurn = &TurnInfo{
 		TurnSeq:        turnSeq,
-		StartTime:      time.Now(),
-		LastUpdateTime: time.Now(),
+		StartTime:      now,
+		LastUpdateTime: now,
+		Text:           tm.sentenceBuffer,
 		State:          TurnStateActive,
 	}
 
-	// 清空缓存
+	// 清空缓存，取消等待静音超时的定时器
 	tm.sentenceBuffer = ""
-	// log.Printf("TurnManager: Created new turn %d", turnSeq)
+	tm.trace = nil
+	tm.speculation = ""
+	tm.silenceTimeout = tm.config.SilenceTimeout
+	tm.cancelScoring()
+	tm.StopTimer()
+}
+
+// commitTrace 将缓存句子的追踪归属到当前轮次并打点
+func (tm *TurnManager) commitTrace() *TurnTrace {
+	tm.trace.SetTurnSeq(tm.GetCurTurnSeq())
+	tm.trace.Mark(SpanTurnCommit, tm.GetName())
+	return tm.trace
 }
 
 func (tm *TurnManager) broadcastInterrupt(turnSeq int, interruptType InterruptType) {
+	// Data 注明打断类型，下游据此区分轮次提交的语义打断与用户打断
 	packet := Packet{
+		Data:    interruptType,
 		Command: PacketCommandInterrupt,
 		TurnSeq: turnSeq,
 	}
 	tm.ForwardPacket(packet)
+	tm.Publish(EventInterrupt, turnSeq, InterruptEvent{Type: interruptType})
 	logger.Info("[TurnSeq: %d] TurnManager: Broadcasting interrupt type=%v", turnSeq, interruptType)
 }
 
-// GetID 实现 Component 接口
-func (tm *TurnManager) GetID() interface{} {
-	return tm.GetName()
-}
-
-// Process 实现 Component 接口
-func (tm *TurnManager) Process(packet Packet) {
-	select {
-	case tm.GetInputChan() <- packet:
-	default:
-		logger.Error("**%s** Input channel full, dropping packet", tm.GetName())
-	}
-}
-
-// SetOutput 实现 Component 接口
-func (tm *TurnManager) SetOutput(output func(Packet)) {
-	outChan := make(chan Packet, 100)
-	tm.SetOutputChan(outChan)
-	go func() {
-		for packet := range tm.GetOutputChan() {
-			if output != nil {
-				output(packet)
-			}
-		}
-	}()
-}
-
-// Start 实现 Component 接口
-func (tm *TurnManager) Start() error {
-	tm.BaseComponent.Start()
-	return nil
-}
-
-// GetHealth 实现 Component 接口
-func (tm *TurnManager) GetHealth() ComponentHealth {
-	return tm.BaseComponent.GetHealth()
-}
-
-// UpdateHealth 实现 Component 接口
-func (tm *TurnManager) UpdateHealth(health ComponentHealth) {
-	tm.BaseComponent.UpdateHealth(health)
-}
-
 // GetCurrentTurn 获取当前轮次信息
 func (tm *TurnManager) GetCurrentTurn() *TurnInfo {
 	return tm.currentTurn
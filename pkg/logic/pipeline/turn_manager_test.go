@@ -0,0 +1,193 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newClockedTurnManager 返回使用虚拟时间的 TurnManager，测试中直接调用处理函数
+func newClockedTurnManager() (*TurnManager, *FakeClock) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tm := NewTurnManager(DefaultTurnManagerConfig())
+	tm.SetIgnoreTurn(true)
+	tm.SetClock(clock)
+	return tm, clock
+}
+
+func TestTurnManager_SilenceTimeout(t *testing.T) {
+	tm, clock := newClockedTurnManager()
+
+	tm.processPacket(Packet{Data: "你好"})
+	clock.Advance(time.Second)
+	tm.processPacket(Packet{Data: "我想问"})
+	assert.Empty(t, tm.GetOutputChan())
+
+	// 静音超过超时时间后，缓存的句子作为独立轮次发出，新文本开始下一轮
+	clock.Advance(3 * time.Second)
+	tm.processPacket(Packet{Data: "在吗"})
+	packet := recv(t, tm.GetOutputChan())
+	assert.Equal(t, "你好我想问", packet.Data)
+	assert.Equal(t, 1, packet.TurnSeq)
+	assert.Empty(t, tm.GetOutputChan())
+	assert.Equal(t, "在吗", tm.sentenceBuffer)
+}
+
+func TestTurnManager_MaxTurnDuration(t *testing.T) {
+	tm, clock := newClockedTurnManager()
+
+	// 持续说话不停顿且没有结束标点，超过最大轮次时长后发出
+	for i := 0; i <= 30; i++ {
+		tm.processPacket(Packet{Data: "啊"})
+		clock.Advance(time.Second)
+	}
+	assert.Empty(t, tm.GetOutputChan())
+
+	tm.processPacket(Packet{Data: "啊"})
+	packet := recv(t, tm.GetOutputChan())
+	assert.Len(t, []rune(packet.Data.(string)), 32)
+	assert.Equal(t, 1, packet.TurnSeq)
+	assert.Empty(t, tm.sentenceBuffer)
+}
+
+// startClockedTurnManager 启动使用虚拟时间的 TurnManager
+// setup 在启动前调整 TurnManager，返回的 processed 追加一个不改变状态的静音帧，等待此前共 n 个数据包全部处理完
+func startClockedTurnManager(t *testing.T, setup ...func(*TurnManager)) (*TurnManager, *FakeClock, func(n int64)) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tm := NewTurnManager(DefaultTurnManagerConfig())
+	tm.SetIgnoreTurn(true)
+	tm.SetClock(clock)
+	for _, fn := range setup {
+		fn(tm)
+	}
+	tm.SetInputChan(make(chan Packet, 10))
+	assert.NoError(t, tm.Start())
+	t.Cleanup(tm.Stop)
+
+	quiet := NewPCMFrame([]int16{10, -10}, 16000, 1, 0)
+	processed := func(n int64) {
+		tm.Process(Packet{Data: quiet})
+		assert.Eventually(t, func() bool { return tm.GetHealth().ProcessedCount == n }, time.Second, time.Millisecond)
+	}
+	return tm, clock, processed
+}
+
+func TestTurnManager_SilenceTimer(t *testing.T) {
+	tm, clock, processed := startClockedTurnManager(t)
+	loud := NewPCMFrame([]int16{1000, -1000}, 16000, 1, 0)
+
+	// 没有结束标点的句子在静音超时后结束，不必等待下一段识别结果
+	tm.Process(Packet{Data: "我想订一张机票"})
+	processed(2)
+	clock.Advance(1500 * time.Millisecond)
+
+	// 输入音频表明用户仍在说话，静音超时从此刻重新计算
+	tm.Process(Packet{Data: loud})
+	processed(4)
+	clock.Advance(time.Second)
+	assert.Empty(t, tm.GetOutputChan())
+	clock.Advance(time.Second)
+	packet := recv(t, tm.GetOutputChan())
+	assert.Equal(t, "我想订一张机票", packet.Data)
+	assert.Equal(t, 1, packet.TurnSeq)
+
+	// 过短的句子即使以结束标点结尾也等待静音超时
+	tm.Process(Packet{Data: "嗯。"})
+	processed(6)
+	assert.Empty(t, tm.GetOutputChan())
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, "嗯。", recv(t, tm.GetOutputChan()).Data)
+}
+
+func TestTurnManager_VoiceActivity(t *testing.T) {
+	tm, clock, processed := startClockedTurnManager(t)
+
+	// VAD 报告用户仍在说话，句中的长停顿不结束句子
+	tm.Process(Packet{Data: "我想订一张去"})
+	tm.Process(Packet{Data: VoiceActivity{Speaking: true}})
+	processed(3)
+	clock.Advance(5 * time.Second)
+	assert.Empty(t, tm.GetOutputChan())
+
+	// 停止说话后重新计算静音超时
+	tm.Process(Packet{Data: VoiceActivity{Speaking: false}})
+	processed(5)
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, "我想订一张去", recv(t, tm.GetOutputChan()).Data)
+}
+
+func TestTurnManager_InterimTranscript(t *testing.T) {
+	tm, clock, processed := startClockedTurnManager(t)
+
+	// 中间识别结果表明用户仍在说话，静音超时从此刻重新计算，中间结果不向下游转发
+	tm.Process(Packet{Data: "我想订一张机票"})
+	processed(2)
+	clock.Advance(1500 * time.Millisecond)
+	tm.Process(Packet{Data: InterimTranscript{Text: "去北", Index: 1}})
+	processed(4)
+	clock.Advance(time.Second)
+	assert.Empty(t, tm.GetOutputChan())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, "我想订一张机票", recv(t, tm.GetOutputChan()).Data)
+	assert.Empty(t, tm.GetOutputChan())
+}
+
+func TestTurnManager_Speculation(t *testing.T) {
+	tm, _, processed := startClockedTurnManager(t, func(tm *TurnManager) { tm.SetSpeculative(true) })
+
+	// 只有稳定的中间结果连同缓存的句子作为推测输入发出，相同的推测不重复发出
+	tm.Process(Packet{Data: "我想订一张机票，"})
+	tm.Process(Packet{Data: InterimTranscript{Text: "去北", Index: 1}})
+	tm.Process(Packet{Data: InterimTranscript{Text: "去北京", StableText: "去北京", Stable: true, Index: 1}})
+	tm.Process(Packet{Data: InterimTranscript{Text: "去北京", StableText: "去北京", Stable: true, Index: 1}})
+	processed(5)
+	assert.Equal(t, SpeculativeTranscript{Text: "我想订一张机票，去北京"}, recv(t, tm.GetOutputChan()).Data)
+	assert.Empty(t, tm.GetOutputChan())
+
+	// 新轮次中与上一轮相同的话仍会推测
+	tm.Process(Packet{Data: "去北京。"})
+	tm.Process(Packet{Data: InterimTranscript{Text: "好的", StableText: "好的", Stable: true, Index: 2}})
+	tm.Process(Packet{Data: "好的。"})
+	tm.Process(Packet{Data: InterimTranscript{Text: "好的", StableText: "好的", Stable: true, Index: 3}})
+	processed(10)
+	assert.Equal(t, "我想订一张机票，去北京。", recv(t, tm.GetOutputChan()).Data)
+	assert.Equal(t, SpeculativeTranscript{Text: "好的"}, recv(t, tm.GetOutputChan()).Data)
+	assert.Equal(t, "好的。", recv(t, tm.GetOutputChan()).Data)
+	assert.Equal(t, SpeculativeTranscript{Text: "好的"}, recv(t, tm.GetOutputChan()).Data)
+	assert.Empty(t, tm.GetOutputChan())
+}
+
+func TestTurnManager_CommandInterrupt(t *testing.T) {
+	tm, _, processed := startClockedTurnManager(t)
+
+	// 打断指令立即转发，缓存的句子随即作为新轮次发出，不等待虚拟时间推进
+	tm.Process(Packet{Data: "我想订一张"})
+	tm.Process(Packet{Command: PacketCommandInterrupt, Data: InterruptTypeBargeIn})
+	processed(3)
+	interrupt := recv(t, tm.GetOutputChan())
+	assert.Equal(t, PacketCommandInterrupt, interrupt.Command)
+	assert.Equal(t, 1, interrupt.TurnSeq)
+	packet := recv(t, tm.GetOutputChan())
+	assert.Equal(t, "我想订一张", packet.Data)
+	assert.Equal(t, 1, packet.TurnSeq)
+}
+
+func TestTurnManagerConfig_Rules(t *testing.T) {
+	cfg := DefaultTurnManagerConfig()
+	cfg.Language = "en"
+	tm := NewTurnManager(cfg)
+	tm.SetIgnoreTurn(true)
+
+	tm.processPacket(Packet{Data: "Hm."})
+	assert.Empty(t, tm.GetOutputChan())
+	tm.processPacket(Packet{Data: " I need a taxi."})
+	assert.Equal(t, "Hm. I need a taxi.", recv(t, tm.GetOutputChan()).Data)
+	assert.Equal(t, "Hm. I need a taxi.", tm.GetCurrentTurn().Text)
+
+	// 修改规则复制 Languages，不影响已创建组件的配置
+	cfg.SetRules("en", EndpointRules{PunctuationMarks: []string{"."}, MinSentenceLength: 10})
+	assert.Equal(t, 10, cfg.Rules().MinSentenceLength)
+	assert.Equal(t, 3, tm.config.Rules().MinSentenceLength)
+}
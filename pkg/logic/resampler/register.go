@@ -0,0 +1,14 @@
+package resampler
+
+import "streamlink/pkg/logic/pipeline"
+
+func init() {
+	pipeline.Register("resampler", func(params pipeline.Params) (pipeline.Component, error) {
+		return NewResampler(
+			params.Int("in_sample_rate", 48000),
+			params.Int("out_sample_rate", 16000),
+			params.Int("in_channels", 2),
+			params.Int("out_channels", 1),
+		)
+	})
+}
@@ -3,9 +3,7 @@ package resampler
 import (
 	"bytes"
 	"fmt"
-	"io"
 	"streamlink/pkg/logger"
-	"streamlink/pkg/logic/codec"
 	"streamlink/pkg/logic/pipeline"
 	"time"
 
@@ -18,12 +16,15 @@ type Resampler struct {
 	resampler     *resample.Resampler
 	buffer        *bytes.Buffer
 	inputBuffer   []int16 // 用于累积输入样本的缓冲区
+	mixBuffer     []int16 // 声道转换的临时缓冲区，重复使用
+	byteBuffer    []byte  // 写入重采样器的临时缓冲区，重复使用
 	channelsIn    int
 	channelsOut   int
 	sampleRateOut int
 	sampleRateIn  int
 	metrics       pipeline.TurnMetrics
-	minSamples    int // 重采样所需的最小样本数
+	minSamples    int           // 重采样所需的最小样本数
+	inputTs       time.Duration // inputBuffer 首个采样的媒体时间戳
 }
 
 func NewResampler(sampleRateIn, sampleRateOut, channelsIn, channelsOut int) (*Resampler, error) {
@@ -60,15 +61,20 @@ func NewResampler(sampleRateIn, sampleRateOut, channelsIn, channelsOut int) (*Re
 		minSamples:    minSamples,
 	}
 
+	r.SetInputFormat(pipeline.NewPCMFormat(sampleRateIn, channelsIn))
+	r.SetOutputFormat(pipeline.NewPCMFormat(sampleRateOut, channelsOut))
+	r.SetBackpressure(pipeline.DropOldestPolicy())
+
 	// 设置处理函数
 	r.BaseComponent.SetProcess(r.processPacket)
 	r.RegisterCommandHandler(pipeline.PacketCommandInterrupt, r.handleInterrupt)
+	// 冲刷或流结束时输出缓冲区中剩余的样本
+	r.SetFlushHandler(r.flush)
 
 	return r, nil
 }
 
 func (r *Resampler) handleInterrupt(packet pipeline.Packet) {
-	// log.Printf("**%s** Received interrupt command for turn %d", r.GetName(), packet.TurnSeq)
 	r.SetCurTurnSeq(packet.TurnSeq)
 
 	r.ForwardPacket(packet)
@@ -78,33 +84,20 @@ func (r *Resampler) handleInterrupt(packet pipeline.Packet) {
 func (r *Resampler) processPacket(packet pipeline.Packet) {
 	if packet.TurnSeq < r.GetCurTurnSeq() {
 		logger.Info("**%s** Skip turn_seq=%d , text: %s", r.GetName(), packet.TurnSeq, packet.Data)
-		r.inputBuffer = make([]int16, 0)
+		r.inputBuffer = r.inputBuffer[:0]
+		pipeline.ReleasePacket(packet)
 		return
 	}
 	r.metrics.TurnStartTs = time.Now().UnixMilli()
 	r.metrics.TurnEndTs = 0
 
-	var processData []int16
-
-	switch data := packet.Data.(type) {
-	case codec.AudioPacket:
-		bytesData := data.Payload()
-		processData = make([]int16, len(bytesData)/2)
-		for i := 0; i < len(bytesData); i += 2 {
-			processData[i/2] = int16(bytesData[i]) | (int16(bytesData[i+1]) << 8)
-		}
-	case []int16:
-		processData = data
-	case []byte:
-		// 将 []byte 转换为 []int16
-		processData = make([]int16, len(data)/2)
-		for i := 0; i < len(data); i += 2 {
-			processData[i/2] = int16(data[i]) | (int16(data[i+1]) << 8)
-		}
-	default:
-		r.HandleUnsupportedData(packet.Data)
+	frame, ok := r.ExpectAudioFrame(packet)
+	if !ok {
 		return
 	}
+	// 输入采样复制到缓冲区后即可归还
+	defer frame.Release()
+	processData := frame.Samples
 
 	if len(processData) == 0 {
 		logger.Warn("**%s** Warning: received empty input data", r.GetName())
@@ -112,6 +105,11 @@ func (r *Resampler) processPacket(packet pipeline.Packet) {
 		return
 	}
 
+	// 输入缓冲区为空时，以当前帧的时间戳作为起点
+	if len(r.inputBuffer) == 0 {
+		r.inputTs = frame.Timestamp
+	}
+
 	// 将新数据添加到输入缓冲区
 	r.inputBuffer = append(r.inputBuffer, processData...)
 
@@ -119,23 +117,35 @@ func (r *Resampler) processPacket(packet pipeline.Packet) {
 	if len(r.inputBuffer) < r.minSamples {
 		return
 	}
+	r.resampleBuffered(packet.Trace)
+}
+
+// flush 用静音将缓冲区补齐到处理单位的整数倍后全部输出
+func (r *Resampler) flush() {
+	if len(r.inputBuffer) == 0 {
+		return
+	}
+	if rem := len(r.inputBuffer) % r.minSamples; rem != 0 {
+		r.inputBuffer = append(r.inputBuffer, make([]int16, r.minSamples-rem)...)
+	}
+	r.resampleBuffered(nil)
+}
 
+// resampleBuffered 重采样缓冲区中整数个处理单位的样本并输出，剩余样本留待下次处理
+func (r *Resampler) resampleBuffered(trace *pipeline.TurnTrace) {
 	// 计算可以处理的样本数（必须是minSamples的整数倍）
 	processableSamples := (len(r.inputBuffer) / r.minSamples) * r.minSamples
 
 	// 获取要处理的数据
 	samplesForProcessing := r.inputBuffer[:processableSamples]
 
-	// 保存剩余的数据
-	remainingSamples := r.inputBuffer[processableSamples:]
-
 	// 处理输入缓冲区中的数据
 	var processedData []int16
 
 	// 如果是立体声转单声道，先做声道转换
 	if r.channelsIn > r.channelsOut {
 		// 立体声转单声道
-		processedData = make([]int16, len(samplesForProcessing)/2)
+		processedData = r.mixSamples(len(samplesForProcessing) / 2)
 		for i := 0; i < len(samplesForProcessing); i += r.channelsIn {
 			if i+1 >= len(samplesForProcessing) {
 				break
@@ -156,113 +166,53 @@ func (r *Resampler) processPacket(packet pipeline.Packet) {
 			processedData[i/2] = int16(mixed)
 		}
 	} else if r.channelsIn < r.channelsOut {
-		processedData = make([]int16, len(samplesForProcessing)*2)
+		processedData = r.mixSamples(len(samplesForProcessing) * 2)
 		for i := 0; i < len(samplesForProcessing); i++ {
 			processedData[i*2] = samplesForProcessing[i]
 			processedData[i*2+1] = samplesForProcessing[i]
 		}
 	} else {
-		processedData = make([]int16, len(samplesForProcessing))
-		copy(processedData, samplesForProcessing)
+		processedData = samplesForProcessing
 	}
 
 	// Convert []int16 to []byte
-	audioBytes := make([]byte, len(processedData)*2)
-	for i, sample := range processedData {
-		audioBytes[i*2] = byte(sample)
-		audioBytes[i*2+1] = byte(sample >> 8)
-	}
+	r.byteBuffer = pipeline.AppendSamplesToBytes(r.byteBuffer[:0], processedData)
 
 	r.buffer.Reset()
 	// 写入数据
-	_, err := r.resampler.Write(audioBytes)
+	_, err := r.resampler.Write(r.byteBuffer)
 	if err != nil {
 		logger.Error("**%s** Resampling failed: %v", r.GetName(), err)
 		r.UpdateErrorStatus(err)
 		return
 	}
 
-	// 读取重采样后的数据
-	resampledBytes := make([]byte, r.buffer.Len())
-	n, err := r.buffer.Read(resampledBytes)
-	if err != nil && err != io.EOF {
-		logger.Error("**%s** Failed to read resampled data: %v", r.GetName(), err)
-		r.UpdateErrorStatus(err)
-		return
-	}
-	resampledBytes = resampledBytes[:n]
+	// 读取重采样后的数据，直接转换到池化的缓冲区
+	resampledBytes := r.buffer.Bytes()
+	out := pipeline.NewSampleBuffer(len(resampledBytes) / 2)
+	out.Samples = pipeline.AppendBytesToSamples(out.Samples[:0], resampledBytes)
+	frameTs := r.inputTs
 
-	// Convert []byte back to []int16
-	currentData := make([]int16, len(resampledBytes)/2)
-	for i := 0; i < len(resampledBytes)/2; i++ {
-		currentData[i] = int16(resampledBytes[i*2]) | (int16(resampledBytes[i*2+1]) << 8)
-	}
-
-	// 更新输入缓冲区为剩余的样本
-	r.inputBuffer = make([]int16, len(remainingSamples))
-	copy(r.inputBuffer, remainingSamples)
+	// 剩余的样本移到输入缓冲区开头
+	r.inputBuffer = r.inputBuffer[:copy(r.inputBuffer, r.inputBuffer[processableSamples:])]
+	r.inputTs += pipeline.SamplesDuration(processableSamples, r.sampleRateIn, r.channelsIn)
 
 	// 发送重采样后的数据
 	r.metrics.TurnEndTs = time.Now().UnixMilli()
 
-	previousMetrics := packet.TurnMetricStat
-	if previousMetrics != nil {
-		packet.TurnMetricKeys = append(packet.TurnMetricKeys, fmt.Sprintf("%s_%d", r.GetName(), r.GetSeq()))
-		previousMetrics[fmt.Sprintf("%s_%d", r.GetName(), r.GetSeq())] = r.metrics
-	}
-
 	r.ForwardPacket(pipeline.Packet{
-		Data:           currentData,
-		Seq:            r.GetSeq(),
-		TurnSeq:        r.GetCurTurnSeq(),
-		TurnMetricStat: previousMetrics,
-		TurnMetricKeys: packet.TurnMetricKeys,
+		Data:    pipeline.NewPooledPCMFrame(out, r.sampleRateOut, r.channelsOut, frameTs),
+		Seq:     r.GetSeq(),
+		TurnSeq: r.GetCurTurnSeq(),
+		Trace:   trace,
 	})
 }
 
-// GetID 实现 Component 接口
-func (r *Resampler) GetID() interface{} {
-	return r.GetSeq()
-}
-
-// Stop 实现 Component 接口，扩展基础组件的 Stop 方法
-func (r *Resampler) Stop() {
-	r.BaseComponent.Stop()
-}
-
-// 为了向后兼容，保留这些方法
-func (r *Resampler) Process(packet pipeline.Packet) {
-	select {
-	case r.GetInputChan() <- packet:
-	default:
-		logger.Error("**%s** Input channel full, dropping packet", r.GetName())
+// mixSamples 返回长度为 n 的声道转换缓冲区
+func (r *Resampler) mixSamples(n int) []int16 {
+	if cap(r.mixBuffer) < n {
+		r.mixBuffer = make([]int16, n)
 	}
-}
-
-func (r *Resampler) SetOutput(output func(pipeline.Packet)) {
-	outChan := make(chan pipeline.Packet, 100)
-	r.SetOutputChan(outChan)
-	go func() {
-		for packet := range r.GetOutputChan() {
-			if output != nil {
-				output(packet)
-			}
-		}
-	}()
-}
-
-// Start 实现 Component 接口
-func (r *Resampler) Start() error {
-	r.BaseComponent.Start()
-	return nil
-}
-
-// GetHealth 实现 Component 接口
-func (r *Resampler) GetHealth() pipeline.ComponentHealth {
-	return r.BaseComponent.GetHealth()
-}
-
-// UpdateHealth 实现 Component 接口
-func (r *Resampler) UpdateHealth(health pipeline.ComponentHealth) {
-	r.BaseComponent.UpdateHealth(health)
+	r.mixBuffer = r.mixBuffer[:n]
+	return r.mixBuffer
 }
@@ -0,0 +1,15 @@
+package stt
+
+import "streamlink/pkg/logic/pipeline"
+
+func init() {
+	pipeline.Register("tencent_asr", func(params pipeline.Params) (pipeline.Component, error) {
+		return NewTencentAsr(
+			params.String("app_id", ""),
+			params.String("secret_id", ""),
+			params.String("secret_key", ""),
+			params.String("engine_model_type", "16k_zh"),
+			params.Int("slice_size", 6400),
+		), nil
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"streamlink/pkg/logger"
 	"streamlink/pkg/logic/pipeline"
+	"strings"
 	"sync"
 	"time"
 
@@ -22,6 +23,8 @@ type TencentAsr struct {
 	engineModelType string
 	sliceSize       int
 	recognizer      *asr.SpeechRecognizer
+	recognizerMu    sync.Mutex
+	sessionID       int // 当前识别会话的监听器 id，用于忽略已废弃会话的回调
 	resultChan      chan string
 	resultMutex     sync.Mutex
 	currentText     string
@@ -40,9 +43,16 @@ func NewTencentAsr(appID, secretID, secretKey, engineModelType string, sliceSize
 		resultChan:      make(chan string, 4000),
 	}
 
+	// 引擎只接受单声道 PCM，采样率由引擎模型决定
+	t.SetInputFormat(pipeline.NewPCMFormat(engineSampleRate(engineModelType), 1))
+	// 识别文本不允许丢弃
+	t.SetBackpressure(pipeline.BlockPolicy(0))
+
 	// 设置处理函数
 	t.BaseComponent.SetProcess(t.processPacket)
 	t.RegisterCommandHandler(pipeline.PacketCommandInterrupt, t.handleInterrupt)
+	// 输入排空后结束识别，等待最后一句结果输出后再关闭输出
+	t.SetDrainHandler(t.stopRecognizer)
 
 	return t
 }
@@ -56,50 +66,88 @@ func (t *TencentAsr) handleInterrupt(packet pipeline.Packet) {
 
 // Start 启动语音识别服务
 func (t *TencentAsr) Start() error {
+	if err := t.startRecognizer(); err != nil {
+		return err
+	}
+
+	// 启动基础组件的处理循环
+	if err := t.BaseComponent.Start(); err != nil {
+		log.Printf("Failed to start base component: %v", err)
+		t.stopRecognizer()
+		return fmt.Errorf("start base component failed: %w", err)
+	}
+
+	return nil
+}
+
+// Restart 实现 pipeline.Restartable，重建识别会话，处理循环与 channel 保持不变
+func (t *TencentAsr) Restart() error {
+	t.stopRecognizer()
+	return t.startRecognizer()
+}
+
+// startRecognizer 建立新的识别会话
+func (t *TencentAsr) startRecognizer() error {
+	t.recognizerMu.Lock()
+	defer t.recognizerMu.Unlock()
 	if t.recognizer != nil {
 		return fmt.Errorf("recognizer already started")
 	}
 
 	id := rand.Intn(1000000)
 	listener := &asrListener{
-		id:  id,
-		asr: t,
+		id:        id,
+		asr:       t,
+		startedAt: time.Now(),
 	}
 
 	credential := common.NewCredential(t.secretID, t.secretKey)
-	t.recognizer = asr.NewSpeechRecognizer(t.appID, credential, t.engineModelType, listener)
-	t.recognizer.VoiceFormat = asr.AudioFormatPCM
+	recognizer := asr.NewSpeechRecognizer(t.appID, credential, t.engineModelType, listener)
+	recognizer.VoiceFormat = asr.AudioFormatPCM
+	// 返回带标点的词级结果，中间结果按词的稳定标记判断已稳定的前缀
+	recognizer.WordInfo = 2
 
-	if err := t.recognizer.Start(); err != nil {
+	if err := recognizer.Start(); err != nil {
 		log.Printf("Failed to start recognizer: %v", err)
-		t.recognizer = nil
 		return fmt.Errorf("start recognizer failed: %w", err)
 	}
-
-	// 启动基础组件的处理循环
-	if err := t.BaseComponent.Start(); err != nil {
-		log.Printf("Failed to start base component: %v", err)
-		t.recognizer.Stop()
-		t.recognizer = nil
-		return fmt.Errorf("start base component failed: %w", err)
-	}
-
+	t.recognizer = recognizer
+	t.sessionID = id
 	return nil
 }
 
+// isCurrentSession 判断监听器是否属于当前识别会话
+func (t *TencentAsr) isCurrentSession(id int) bool {
+	t.recognizerMu.Lock()
+	defer t.recognizerMu.Unlock()
+	return t.recognizer != nil && t.sessionID == id
+}
+
 // Stop 停止语音识别服务
 func (t *TencentAsr) Stop() {
 	t.BaseComponent.Stop()
-	if t.recognizer != nil {
-		t.recognizer.Stop()
-		t.recognizer = nil
-	}
+	t.stopRecognizer()
 	// 清理状态
 	t.resultMutex.Lock()
 	t.currentText = ""
 	t.resultMutex.Unlock()
 }
 
+// stopRecognizer 结束识别会话，可重复调用
+// recognizer.Stop 会等待 SDK 回调结束，因此不能持有 recognizerMu 调用
+func (t *TencentAsr) stopRecognizer() {
+	t.recognizerMu.Lock()
+	recognizer := t.recognizer
+	t.recognizer = nil
+	t.recognizerMu.Unlock()
+
+	if recognizer != nil {
+		if err := recognizer.Stop(); err != nil {
+			logger.Warn("**%s** Failed to stop recognizer: %v", t.GetName(), err)
+		}
+	}
+}
+
 // processPacket 处理输入的数据包
 func (t *TencentAsr) processPacket(packet pipeline.Packet) {
 	// 处理指令
@@ -107,41 +155,43 @@ func (t *TencentAsr) processPacket(packet pipeline.Packet) {
 		return
 	}
 
-	// 检查 recognizer 是否已初始化
-	if t.recognizer == nil {
-		log.Printf("**%s** Error: recognizer not initialized", t.GetName())
-		t.UpdateErrorStatus(fmt.Errorf("recognizer not initialized"))
+	frame, ok := t.ExpectAudioFrame(packet)
+	if !ok {
 		return
 	}
+	// SDK 异步发送写入的字节切片，不能复用，转换后即可归还采样缓冲区
+	defer frame.Release()
 
-	switch data := packet.Data.(type) {
-	case []byte:
-		if err := t.recognizer.Write(data); err != nil {
-			log.Printf("**%s** Failed to write audio data: %v", t.GetName(), err)
-			t.UpdateErrorStatus(err)
-		}
+	t.recognizerMu.Lock()
+	recognizer := t.recognizer
+	t.recognizerMu.Unlock()
 
-	case []int16:
-		// 将 []int16 转换为 []byte
-		audioBytes := make([]byte, len(data)*2)
-		for i, sample := range data {
-			audioBytes[i*2] = byte(sample)
-			audioBytes[i*2+1] = byte(sample >> 8)
-		}
+	// 检查 recognizer 是否已初始化，重启期间的音频会被丢弃
+	if recognizer == nil {
+		log.Printf("**%s** Error: recognizer not initialized", t.GetName())
+		t.UpdateErrorStatus(fmt.Errorf("recognizer not initialized"))
+		t.UpdateDroppedStatus()
+		return
+	}
 
-		if err := t.recognizer.Write(audioBytes); err != nil {
-			log.Printf("**%s** Failed to write audio data: %v", t.GetName(), err)
-			t.UpdateErrorStatus(err)
+	if err := recognizer.Write(frame.Bytes()); err != nil {
+		log.Printf("**%s** Failed to write audio data: %v", t.GetName(), err)
+		// 已被替换的会话写入失败不影响组件状态
+		t.recognizerMu.Lock()
+		current := t.recognizer == recognizer
+		t.recognizerMu.Unlock()
+		if current {
+			t.ReportFailure(err)
 		}
-
-	default:
-		t.HandleUnsupportedData(packet.Data)
 	}
 }
 
-// GetID 实现 Component 接口
-func (t *TencentAsr) GetID() interface{} {
-	return t.GetSeq()
+// engineSampleRate 根据引擎模型类型推断输入采样率，如 8k_zh、16k_zh_large
+func engineSampleRate(engineModelType string) int {
+	if strings.HasPrefix(engineModelType, "8k") {
+		return 8000
+	}
+	return 16000
 }
 
 // GetResult 获取当前识别结果
@@ -156,34 +206,18 @@ func (t *TencentAsr) GetResultChan() <-chan string {
 	return t.resultChan
 }
 
-// 为了向后兼容，保留这些方法
-func (t *TencentAsr) Process(packet pipeline.Packet) {
-	select {
-	case t.GetInputChan() <- packet:
-	default:
-		log.Printf("TencentAsr: input channel full, dropping packet")
-	}
-}
-
+// SetInput 创建输入通道，用于单独使用组件
 func (t *TencentAsr) SetInput() {
 	inChan := make(chan pipeline.Packet, 100)
 	t.SetInputChan(inChan)
 }
 
-func (t *TencentAsr) SetOutput(output func(pipeline.Packet)) {
-	go func() {
-		for packet := range t.GetOutputChan() {
-			if output != nil {
-				output(packet)
-			}
-		}
-	}()
-}
-
 // asrListener 实现语音识别监听器
 type asrListener struct {
-	id  int
-	asr *TencentAsr
+	id        int
+	asr       *TencentAsr
+	startedAt time.Time                  // 识别会话开始时间，识别结果中的音频偏移以此为起点
+	interim   pipeline.InterimTranscript // 当前句子上一次的中间结果，SDK 按顺序回调，无需加锁
 }
 
 func (l *asrListener) OnRecognitionStart(response *asr.SpeechRecognitionResponse) {
@@ -197,7 +231,8 @@ func (l *asrListener) OnSentenceBegin(response *asr.SpeechRecognitionResponse) {
 }
 
 func (l *asrListener) OnRecognitionResultChange(response *asr.SpeechRecognitionResponse) {
-	resultText := fmt.Sprintf("%v", response.Result)
+	resultText := response.Result.VoiceTextStr
+	l.forwardInterim(response.Result)
 
 	// 更新当前文本
 	l.asr.resultMutex.Lock()
@@ -218,11 +253,69 @@ func (l *asrListener) OnRecognitionResultChange(response *asr.SpeechRecognitionR
 	}
 }
 
+// forwardInterim 将中间结果作为 InterimTranscript 发往下游，空结果与上一次相同的结果不发送
+func (l *asrListener) forwardInterim(result asr.SpeechRecognitionResponseResult) {
+	interim := newInterimTranscript(result, l.interim)
+	if interim.Text == "" || interim == l.interim {
+		return
+	}
+	l.interim = interim
+
+	l.asr.Publish(pipeline.EventTranscript, l.asr.GetCurTurnSeq(), pipeline.TranscriptEvent{Text: interim.Text, Stable: interim.Stable})
+	l.asr.ForwardPacket(pipeline.Packet{
+		Data:    interim,
+		Src:     l.asr,
+		TurnSeq: l.asr.GetCurTurnSeq(),
+	})
+}
+
+// newInterimTranscript 根据中间结果生成 InterimTranscript，prev 为同一会话上一次的中间结果
+// 有词级结果时，开头连续带稳定标记的词为已稳定的前缀，全部稳定时整句稳定；
+// 没有词级结果时，与上一次相同的识别文本视为已稳定
+func newInterimTranscript(result asr.SpeechRecognitionResponseResult, prev pipeline.InterimTranscript) pipeline.InterimTranscript {
+	interim := pipeline.InterimTranscript{Text: result.VoiceTextStr, Index: result.Index}
+	if len(result.WordList) == 0 {
+		if prev.Index == result.Index && prev.Text == result.VoiceTextStr {
+			interim.Stable = true
+			interim.StableText = interim.Text
+		}
+		return interim
+	}
+
+	var stable strings.Builder
+	interim.Stable = true
+	for _, word := range result.WordList {
+		if word.StableFlag == 0 {
+			interim.Stable = false
+			break
+		}
+		stable.WriteString(word.Word)
+	}
+	interim.StableText = stable.String()
+	if interim.Stable {
+		interim.StableText = interim.Text
+	}
+	return interim
+}
+
 func (l *asrListener) OnSentenceEnd(response *asr.SpeechRecognitionResponse) {
-	resultText := fmt.Sprintf("%v", response.Result.VoiceTextStr)
+	resultText := response.Result.VoiceTextStr
 	logger.Info("**%s** Sentence end: voice_id=%s, text=%s", l.asr.GetName(), response.VoiceID, resultText)
+	l.interim = pipeline.InterimTranscript{}
 
 	l.asr.metrics.TurnEndTs = time.Now().UnixMilli()
+	if l.asr.metrics.TurnStartTs > 0 {
+		l.asr.ObserveLatency(pipeline.LatencyASR, time.Duration(l.asr.metrics.TurnEndTs-l.asr.metrics.TurnStartTs)*time.Millisecond)
+	}
+
+	// 音频实时写入，会话开始时间加上句子结束偏移即用户停止说话的时间
+	now := time.Now()
+	trace := l.asr.NewTurnTrace(l.asr.GetCurTurnSeq())
+	if speechEnd := l.startedAt.Add(time.Duration(response.Result.EndTime) * time.Millisecond); response.Result.EndTime > 0 && speechEnd.Before(now) {
+		trace.MarkAt(pipeline.SpanSpeechEnd, l.asr.GetName(), speechEnd)
+	}
+	trace.MarkAt(pipeline.SpanASRFinal, l.asr.GetName(), now)
+	l.asr.Publish(pipeline.EventTranscript, l.asr.GetCurTurnSeq(), pipeline.TranscriptEvent{Text: resultText, Final: true})
 
 	// 发送识别结果到输出通道
 	l.asr.ForwardPacket(pipeline.Packet{
@@ -230,10 +323,7 @@ func (l *asrListener) OnSentenceEnd(response *asr.SpeechRecognitionResponse) {
 		Seq:     l.asr.GetSeq(),
 		Src:     l.asr,
 		TurnSeq: l.asr.GetCurTurnSeq(),
-		TurnMetricStat: map[string]pipeline.TurnMetrics{
-			fmt.Sprintf("%s_%d", l.asr.GetName(), l.asr.GetSeq()): l.asr.metrics,
-		},
-		TurnMetricKeys: []string{fmt.Sprintf("%s_%d", l.asr.GetName(), l.asr.GetSeq())},
+		Trace:   trace,
 	})
 	l.asr.IncrSeq()
 }
@@ -244,15 +334,8 @@ func (l *asrListener) OnRecognitionComplete(response *asr.SpeechRecognitionRespo
 
 func (l *asrListener) OnFail(response *asr.SpeechRecognitionResponse, err error) {
 	logger.Error("**%s** Recognition failed: voice_id=%s, error=%v", l.asr.GetName(), response.VoiceID, err)
-	l.asr.UpdateErrorStatus(err)
-}
-
-// GetHealth 实现 Component 接口
-func (t *TencentAsr) GetHealth() pipeline.ComponentHealth {
-	return t.BaseComponent.GetHealth()
-}
-
-// UpdateHealth 实现 Component 接口
-func (t *TencentAsr) UpdateHealth(health pipeline.ComponentHealth) {
-	t.BaseComponent.UpdateHealth(health)
+	// 识别会话已失效，交由 Supervisor 重启；已被替换的会话不再影响组件状态
+	if l.asr.isCurrentSession(l.id) {
+		l.asr.ReportFailure(err)
+	}
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/asr"
 )
 
 var (
@@ -110,10 +111,10 @@ func TestTencentAsr_Process(t *testing.T) {
 		t.Error("Should not receive result for invalid audio data")
 	})
 
-	// 测试处理 []byte 数据
+	// 测试处理字节数据
 	byteData := []byte{1, 2, 3, 4}
 	asr.Process(pipeline.Packet{
-		Data: byteData,
+		Data: pipeline.NewPCMFrame(pipeline.BytesToSamples(byteData), 16000, 1, 0),
 		Seq:  0,
 		Src:  nil,
 	})
@@ -121,7 +122,7 @@ func TestTencentAsr_Process(t *testing.T) {
 	// 测试处理 []int16 数据
 	int16Data := []int16{1, 2, 3, 4}
 	asr.Process(pipeline.Packet{
-		Data: int16Data,
+		Data: pipeline.NewPCMFrame(int16Data, 16000, 1, 0),
 		Seq:  1,
 		Src:  nil,
 	})
@@ -177,6 +178,31 @@ done:
 	assert.True(t, count > 0)
 }
 
+func TestNewInterimTranscript(t *testing.T) {
+	// 有词级结果时，开头连续稳定的词为已稳定的前缀
+	words := []asr.SpeechRecognitionResponseResultWord{
+		{Word: "我想", StableFlag: 1},
+		{Word: "订", StableFlag: 1},
+		{Word: "一张", StableFlag: 0},
+		{Word: "票", StableFlag: 1},
+	}
+	result := asr.SpeechRecognitionResponseResult{Index: 0, VoiceTextStr: "我想订一张票", WordList: words}
+	interim := newInterimTranscript(result, pipeline.InterimTranscript{})
+	assert.Equal(t, pipeline.InterimTranscript{Text: "我想订一张票", StableText: "我想订", Index: 0}, interim)
+
+	words[2].StableFlag = 1
+	interim = newInterimTranscript(result, interim)
+	assert.True(t, interim.Stable)
+	assert.Equal(t, "我想订一张票", interim.StableText)
+
+	// 没有词级结果时，同一句与上一次相同的识别文本视为已稳定
+	result = asr.SpeechRecognitionResponseResult{Index: 1, VoiceTextStr: "好的"}
+	interim = newInterimTranscript(result, interim)
+	assert.False(t, interim.Stable)
+	interim = newInterimTranscript(result, interim)
+	assert.Equal(t, pipeline.InterimTranscript{Text: "好的", StableText: "好的", Stable: true, Index: 1}, interim)
+}
+
 func TestTencentAsr_ProcessRealAudio(t *testing.T) {
 	asr := getTestClient(t)
 	defer cleanup(asr)
@@ -212,7 +238,7 @@ func TestTencentAsr_ProcessRealAudio(t *testing.T) {
 		}
 
 		asr.Process(pipeline.Packet{
-			Data: buffer[:n],
+			Data: pipeline.NewPCMFrame(pipeline.BytesToSamples(buffer[:n]), 16000, 1, 0),
 			Seq:  0,
 			Src:  nil,
 		})
@@ -0,0 +1,24 @@
+package tts
+
+import "streamlink/pkg/logic/pipeline"
+
+func init() {
+	pipeline.Register("tencent_tts", func(params pipeline.Params) (pipeline.Component, error) {
+		return NewTencentTTS(
+			params.Int64("app_id", 0),
+			params.String("secret_id", ""),
+			params.String("secret_key", ""),
+			params.Int64("voice_type", 0),
+			params.String("codec", "pcm"),
+		), nil
+	})
+	pipeline.Register("tencent_stream_tts", func(params pipeline.Params) (pipeline.Component, error) {
+		return NewTencentStreamTTS(
+			params.Int64("app_id", 0),
+			params.String("secret_id", ""),
+			params.String("secret_key", ""),
+			params.Int64("voice_type", 0),
+			params.String("codec", "pcm"),
+		), nil
+	})
+}
@@ -12,6 +12,31 @@ import (
 	"github.com/tencentcloud/tencentcloud-speech-sdk-go/tts"
 )
 
+// ttsSampleRate 腾讯云 TTS 输出音频的采样率
+const ttsSampleRate = 16000
+
+// ttsBlockTimeout 输出缓冲满时等待下游消费的最长时间
+const ttsBlockTimeout = 2 * time.Second
+
+// codecOutputFormat 根据 TTS 编码格式返回输出的音频格式
+func codecOutputFormat(codec string) pipeline.AudioFormat {
+	if codec == "mp3" {
+		return pipeline.AudioFormat{SampleRate: ttsSampleRate, Channels: 1, Format: pipeline.SampleFormatMP3}
+	}
+	return pipeline.NewPCMFormat(ttsSampleRate, 1)
+}
+
+// newTTSAudioFrame 将 TTS 返回的音频数据封装为音频帧
+func newTTSAudioFrame(data []byte, codec string, timestamp time.Duration) pipeline.AudioFrame {
+	format := codecOutputFormat(codec)
+	if format.Format.IsEncoded() {
+		return pipeline.NewEncodedFrame(data, format, 0, timestamp)
+	}
+	buf := pipeline.NewSampleBuffer(len(data) / 2)
+	buf.Samples = pipeline.AppendBytesToSamples(buf.Samples[:0], data)
+	return pipeline.NewPooledPCMFrame(buf, format.SampleRate, format.Channels, timestamp)
+}
+
 // TencentTTS 实现 Component 接口
 type TencentTTS struct {
 	*pipeline.BaseComponent
@@ -24,6 +49,7 @@ type TencentTTS struct {
 	listener    *ttsSynthesisListener
 	mu          sync.Mutex
 	metrics     pipeline.TurnMetrics
+	mediaTs     time.Duration // 下一帧输出音频的媒体时间戳
 }
 
 // NewTencentTTS 创建一个新的语音合成组件
@@ -38,6 +64,10 @@ func NewTencentTTS(appID int64, secretID, secretKey string, voiceType int64, cod
 		metrics:       pipeline.TurnMetrics{},
 	}
 
+	t.SetOutputFormat(codecOutputFormat(codec))
+	// 合成语音丢失会导致播放断句，缓冲满时等待下游消费
+	t.SetBackpressure(pipeline.BlockPolicy(ttsBlockTimeout))
+
 	// 设置处理函数
 	t.BaseComponent.SetProcess(t.processPacket)
 	t.RegisterCommandHandler(pipeline.PacketCommandInterrupt, t.handleInterrupt)
@@ -46,7 +76,6 @@ func NewTencentTTS(appID int64, secretID, secretKey string, voiceType int64, cod
 }
 
 func (t *TencentTTS) handleInterrupt(packet pipeline.Packet) {
-	// log.Printf("**%s** Received interrupt command for turn %d", t.GetName(), packet.TurnSeq)
 	t.SetCurTurnSeq(packet.TurnSeq)
 
 	t.ForwardPacket(packet)
@@ -94,18 +123,16 @@ func (t *TencentTTS) processPacket(packet pipeline.Packet) {
 
 		// 发送处理后的数据
 		t.metrics.TurnEndTs = time.Now().UnixMilli()
-		previousMetrics := packet.TurnMetricStat
-		if previousMetrics == nil {
-			previousMetrics = make(map[string]pipeline.TurnMetrics)
-		}
-		previousMetrics[fmt.Sprintf("%s_%d", t.GetName(), t.GetSeq())] = t.metrics
-		packet.TurnMetricKeys = append(packet.TurnMetricKeys, fmt.Sprintf("%s_%d", t.GetName(), t.GetSeq()))
+		// 非流式合成完成后才输出音频，整段合成耗时即首包延迟
+		t.ObserveLatency(pipeline.LatencyTTSFirstAudio, time.Duration(t.metrics.TurnEndTs-t.metrics.TurnStartTs)*time.Millisecond)
+		packet.Trace.Mark(pipeline.SpanTTSFirstAudio, t.GetName())
+		frame := newTTSAudioFrame(t.listener.data, t.codec, t.mediaTs)
+		t.mediaTs += frame.Duration
 		t.ForwardPacket(pipeline.Packet{
-			Data:           t.listener.data,
-			Seq:            t.GetSeq(),
-			TurnSeq:        t.GetCurTurnSeq(),
-			TurnMetricStat: previousMetrics,
-			TurnMetricKeys: packet.TurnMetricKeys,
+			Data:    frame,
+			Seq:     t.GetSeq(),
+			TurnSeq: t.GetCurTurnSeq(),
+			Trace:   packet.Trace,
 		})
 
 	default:
@@ -113,11 +140,6 @@ func (t *TencentTTS) processPacket(packet pipeline.Packet) {
 	}
 }
 
-// GetID 实现 Component 接口
-func (t *TencentTTS) GetID() interface{} {
-	return t.GetSeq()
-}
-
 // Stop 实现 Component 接口，扩展基础组件的 Stop 方法
 func (t *TencentTTS) Stop() {
 	t.BaseComponent.Stop()
@@ -129,33 +151,12 @@ func (t *TencentTTS) Stop() {
 	}
 }
 
-// Process 为了向后兼容，保留这些方法
-func (t *TencentTTS) Process(packet pipeline.Packet) {
-	select {
-	case t.GetInputChan() <- packet:
-	default:
-		logger.Error("TencentTTS: input channel full, dropping packet")
-	}
-}
-
 // SetInput 设置输入通道
 func (t *TencentTTS) SetInput() {
 	inChan := make(chan pipeline.Packet, 100)
 	t.SetInputChan(inChan)
 }
 
-func (t *TencentTTS) SetOutput(output func(pipeline.Packet)) {
-	outChan := make(chan pipeline.Packet, 100)
-	t.SetOutputChan(outChan)
-	go func() {
-		for packet := range t.GetOutputChan() {
-			if output != nil {
-				output(packet)
-			}
-		}
-	}()
-}
-
 // GetAudioData 获取已合成的音频数据
 func (t *TencentTTS) GetAudioData() []byte {
 	if t.listener != nil {
@@ -172,16 +173,7 @@ func (t *TencentTTS) SetVoiceType(voiceType int64) {
 // SetCodec 设置音频编码格式
 func (t *TencentTTS) SetCodec(codec string) {
 	t.codec = codec
-}
-
-// GetHealth 实现 Component 接口
-func (t *TencentTTS) GetHealth() pipeline.ComponentHealth {
-	return t.BaseComponent.GetHealth()
-}
-
-// UpdateHealth 实现 Component 接口
-func (t *TencentTTS) UpdateHealth(health pipeline.ComponentHealth) {
-	t.BaseComponent.UpdateHealth(health)
+	t.SetOutputFormat(codecOutputFormat(codec))
 }
 
 // ttsSynthesisListener 实现语音合成监听器
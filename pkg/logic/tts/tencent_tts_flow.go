@@ -23,6 +23,7 @@ type TencentStreamTTS struct {
 	activeSynthesizerIdx int                       // 当前活跃的合成器索引 (0=主, 1=备用)
 	listener             *tts2SynthesisListener
 	mu                   sync.Mutex
+	completeOnce         sync.Once
 	metrics              pipeline.TurnMetrics
 	// 自定义延迟指标
 	firstTokenLatencyMs int64 // 首token延迟(毫秒)
@@ -41,9 +42,15 @@ func NewTencentStreamTTS(appID int64, secretID, secretKey string, voiceType int6
 		activeSynthesizerIdx: -1, // 初始使用主TTS合成器
 	}
 
+	t.SetOutputFormat(codecOutputFormat(codec))
+	// 合成语音丢失会导致播放断句，缓冲满时等待下游消费
+	t.SetBackpressure(pipeline.BlockPolicy(ttsBlockTimeout))
+
 	// 设置处理函数
 	t.BaseComponent.SetProcess(t.processPacket)
 	t.RegisterCommandHandler(pipeline.PacketCommandInterrupt, t.handleInterrupt)
+	// 输入排空后等待已提交的文本合成完毕，再关闭输出
+	t.SetDrainHandler(t.completeSynthesis)
 
 	return t
 }
@@ -71,7 +78,7 @@ func (t *TencentStreamTTS) Start() error {
 	t.primarySynthesizer = NewFlowingSpeechSynthesizer(t.appID, fmt.Sprintf("TTS_Flow_0_%d", time.Now().UnixMicro()), credential, t.listener)
 	t.primarySynthesizer.SetVoiceType(t.voiceType)
 	t.primarySynthesizer.SetCodec(t.codec)
-	t.primarySynthesizer.SetSampleRate(16000)
+	t.primarySynthesizer.SetSampleRate(ttsSampleRate)
 	t.primarySynthesizer.SetVolume(0)
 	t.primarySynthesizer.SetSpeed(1)
 	t.primarySynthesizer.SetEnableSubtitle(false)
@@ -80,7 +87,7 @@ func (t *TencentStreamTTS) Start() error {
 	t.backupSynthesizer = NewFlowingSpeechSynthesizer(t.appID, fmt.Sprintf("TTS_Flow_1_%d", time.Now().UnixMicro()), credential, t.listener)
 	t.backupSynthesizer.SetVoiceType(t.voiceType)
 	t.backupSynthesizer.SetCodec(t.codec)
-	t.backupSynthesizer.SetSampleRate(16000)
+	t.backupSynthesizer.SetSampleRate(ttsSampleRate)
 	t.backupSynthesizer.SetVolume(0)
 	t.backupSynthesizer.SetSpeed(1)
 	t.backupSynthesizer.SetEnableSubtitle(false)
@@ -125,9 +132,16 @@ func (t *TencentStreamTTS) Start() error {
 
 func (t *TencentStreamTTS) keepAcive() {
 	go func() {
-		ticker := time.NewTicker(100 * time.Millisecond)
+		ticker := t.Clock().NewTicker(100 * time.Millisecond)
 		defer ticker.Stop()
-		for range ticker.C {
+		for {
+			select {
+			case <-t.GetStopCh():
+				return
+			case <-t.Done():
+				return
+			case <-ticker.C():
+			}
 			t.mu.Lock()
 			if t.activeSynthesizerIdx == 0 {
 				if t.backupSynthesizer != nil {
@@ -185,7 +199,7 @@ func (t *TencentStreamTTS) handleInterrupt(packet pipeline.Packet) {
 		t.backupSynthesizer = NewFlowingSpeechSynthesizer(t.appID, fmt.Sprintf("TTS_Flow_1_%d", time.Now().UnixMicro()), credential, t.listener)
 		t.backupSynthesizer.SetVoiceType(t.voiceType)
 		t.backupSynthesizer.SetCodec(t.codec)
-		t.backupSynthesizer.SetSampleRate(16000)
+		t.backupSynthesizer.SetSampleRate(ttsSampleRate)
 		t.backupSynthesizer.SetVolume(0)
 		t.backupSynthesizer.SetSpeed(1)
 		t.backupSynthesizer.SetEnableSubtitle(false)
@@ -207,7 +221,7 @@ func (t *TencentStreamTTS) handleInterrupt(packet pipeline.Packet) {
 		t.primarySynthesizer = NewFlowingSpeechSynthesizer(t.appID, fmt.Sprintf("TTS_Flow_0_%d", time.Now().UnixMicro()), credential, t.listener)
 		t.primarySynthesizer.SetVoiceType(t.voiceType)
 		t.primarySynthesizer.SetCodec(t.codec)
-		t.primarySynthesizer.SetSampleRate(16000)
+		t.primarySynthesizer.SetSampleRate(ttsSampleRate)
 		t.primarySynthesizer.SetVolume(0)
 		t.primarySynthesizer.SetSpeed(0)
 		t.primarySynthesizer.SetEnableSubtitle(false)
@@ -250,7 +264,6 @@ func (t *TencentStreamTTS) processPacket(packet pipeline.Packet) {
 			return
 		}
 
-		// log.Printf("**%s** Processing turn_seq=%d , text: %s", t.GetName(), packet.TurnSeq, data)
 		t.mu.Lock()
 		defer t.mu.Unlock()
 
@@ -278,34 +291,29 @@ func (t *TencentStreamTTS) processPacket(packet pipeline.Packet) {
 	}
 }
 
-// GetID 实现 Component 接口
-func (t *TencentStreamTTS) GetID() interface{} {
-	return t.GetSeq()
+// completeSynthesis 结束活跃合成器的会话并等待剩余音频输出，只执行一次
+func (t *TencentStreamTTS) completeSynthesis() {
+	t.completeOnce.Do(func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		// 尝试完成活跃的合成器
+		activeSynthesizer := t.getActiveSynthesizer()
+		if activeSynthesizer != nil {
+			if err := activeSynthesizer.Complete("ACTION_COMPLETE"); err != nil {
+				logger.Error("Complete active synthesis failed: %v", err)
+				t.UpdateErrorStatus(err)
+			} else {
+				// 等待合成完成
+				activeSynthesizer.Wait()
+			}
+		}
+	})
 }
 
 // Stop 实现 Component 接口，扩展基础组件的 Stop 方法
 func (t *TencentStreamTTS) Stop() {
-	t.mu.Lock()
-
-	// 获取当前活跃的合成器
-	var activeSynthesizer *FlowingSpeechSynthesizer
-	if t.activeSynthesizerIdx == 0 {
-		activeSynthesizer = t.primarySynthesizer
-	} else {
-		activeSynthesizer = t.backupSynthesizer
-	}
-
-	// 尝试完成活跃的合成器
-	if activeSynthesizer != nil {
-		if err := activeSynthesizer.Complete("ACTION_COMPLETE"); err != nil {
-			logger.Error("Complete active synthesis failed: %v", err)
-			t.UpdateErrorStatus(err)
-		} else {
-			// 等待合成完成
-			activeSynthesizer.Wait()
-		}
-	}
-	t.mu.Unlock()
+	t.completeSynthesis()
 
 	t.BaseComponent.Stop()
 
@@ -323,27 +331,6 @@ func (t *TencentStreamTTS) Stop() {
 	}
 }
 
-// 为了向后兼容，保留这些方法
-func (t *TencentStreamTTS) Process(packet pipeline.Packet) {
-	select {
-	case t.GetInputChan() <- packet:
-	default:
-		logger.Error("TencentStreamTTS: input channel full, dropping packet")
-	}
-}
-
-func (t *TencentStreamTTS) SetOutput(output func(pipeline.Packet)) {
-	outChan := make(chan pipeline.Packet, 100)
-	t.SetOutputChan(outChan)
-	go func() {
-		for packet := range t.GetOutputChan() {
-			if output != nil {
-				output(packet)
-			}
-		}
-	}()
-}
-
 // SetVoiceType 设置音色
 func (t *TencentStreamTTS) SetVoiceType(voiceType int64) {
 	t.voiceType = voiceType
@@ -358,6 +345,7 @@ func (t *TencentStreamTTS) SetVoiceType(voiceType int64) {
 // SetCodec 设置音频编码格式
 func (t *TencentStreamTTS) SetCodec(codec string) {
 	t.codec = codec
+	t.SetOutputFormat(codecOutputFormat(codec))
 	if t.primarySynthesizer != nil {
 		t.primarySynthesizer.SetCodec(codec)
 	}
@@ -366,16 +354,6 @@ func (t *TencentStreamTTS) SetCodec(codec string) {
 	}
 }
 
-// GetHealth 实现 Component 接口
-func (t *TencentStreamTTS) GetHealth() pipeline.ComponentHealth {
-	return t.BaseComponent.GetHealth()
-}
-
-// UpdateHealth 实现 Component 接口
-func (t *TencentStreamTTS) UpdateHealth(health pipeline.ComponentHealth) {
-	t.BaseComponent.UpdateHealth(health)
-}
-
 // tts2SynthesisListener 实现语音合成监听器
 type tts2SynthesisListener struct {
 	mu        sync.Mutex
@@ -384,9 +362,10 @@ type tts2SynthesisListener struct {
 	tts            *TencentStreamTTS
 	packet         pipeline.Packet
 	turnSeq        int
-	startTime      time.Time // 当前packet处理开始时间
-	firstTokenTime time.Time // 当前packet首个音频数据接收时间
-	hasFirstToken  bool      // 当前packet是否已接收首个音频数据
+	startTime      time.Time     // 当前packet处理开始时间
+	firstTokenTime time.Time     // 当前packet首个音频数据接收时间
+	hasFirstToken  bool          // 当前packet是否已接收首个音频数据
+	mediaTs        time.Duration // 下一帧输出音频的媒体时间戳
 
 	// 按turn序列号记录的计时信息
 	turnStartTimes  map[int]time.Time // 每个turn序列的真正开始时间
@@ -457,23 +436,6 @@ func (l *tts2SynthesisListener) OnSynthesisEnd() {
 	// 标记该turn已处理完成
 	l.processedTurns[l.turnSeq] = true
 
-	// 发送处理后的数据
-	previousMetrics := l.packet.TurnMetricStat
-	if previousMetrics == nil {
-		previousMetrics = make(map[string]pipeline.TurnMetrics)
-	}
-
-	metricKey := fmt.Sprintf("%s_%d", l.tts.GetName(), l.tts.GetSeq())
-	previousMetrics[metricKey] = l.tts.metrics
-	l.packet.TurnMetricKeys = append(l.packet.TurnMetricKeys, metricKey)
-
-	// 为指标添加自定义字段信息
-	metricKey = fmt.Sprintf("%s_%d_first_token_ms", l.tts.GetName(), l.tts.GetSeq())
-	l.packet.TurnMetricKeys = append(l.packet.TurnMetricKeys, metricKey)
-
-	metricKey = fmt.Sprintf("%s_%d_total_ms", l.tts.GetName(), l.tts.GetSeq())
-	l.packet.TurnMetricKeys = append(l.packet.TurnMetricKeys, metricKey)
-
 	// 清理旧的turn记录，只保留最近10个
 	l.cleanupOldTurnRecords(10)
 
@@ -532,6 +494,8 @@ func (l *tts2SynthesisListener) OnAudioResult(audioBytes []byte) {
 			if startTime, ok := l.turnStartTimes[l.turnSeq]; ok {
 				firstTokenLatency := now.Sub(startTime)
 				l.tts.firstTokenLatencyMs = firstTokenLatency.Milliseconds()
+				l.tts.ObserveLatency(pipeline.LatencyTTSFirstAudio, firstTokenLatency)
+				l.packet.Trace.MarkAt(pipeline.SpanTTSFirstAudio, l.tts.GetName(), now)
 				logger.Info("[TurnSeq: %d] **%s**  %s, First audio token received latency: %v",
 					l.turnSeq, l.tts.GetName(), l.sessionID, firstTokenLatency)
 			}
@@ -551,10 +515,13 @@ func (l *tts2SynthesisListener) OnAudioResult(audioBytes []byte) {
 	// }
 
 	// 转发音频数据
+	frame := newTTSAudioFrame(audioBytes, l.tts.codec, l.mediaTs)
+	l.mediaTs += frame.Duration
 	l.tts.ForwardPacket(pipeline.Packet{
-		Data:    audioBytes,
+		Data:    frame,
 		Seq:     l.tts.GetSeq(),
 		TurnSeq: l.turnSeq,
+		Trace:   l.packet.Trace,
 	})
 }
 
@@ -99,10 +99,10 @@ func TestTencentTTS_Process(t *testing.T) {
 	resultReceived := false
 	tts.SetOutput(func(packet pipeline.Packet) {
 		resultReceived = true
-		assert.IsType(t, []byte{}, packet.Data)
-		audioData, ok := packet.Data.([]byte)
+		assert.IsType(t, pipeline.AudioFrame{}, packet.Data)
+		frame, ok := packet.Data.(pipeline.AudioFrame)
 		assert.True(t, ok)
-		assert.NotEmpty(t, audioData)
+		assert.NotZero(t, frame.Len())
 	})
 
 	// 测试处理字符串数据
@@ -129,10 +129,10 @@ func TestTencentTTS_SetVoiceAndCodec(t *testing.T) {
 	resultReceived := false
 	tts.SetOutput(func(packet pipeline.Packet) {
 		resultReceived = true
-		assert.IsType(t, []byte{}, packet.Data)
-		audioData, ok := packet.Data.([]byte)
+		assert.IsType(t, pipeline.AudioFrame{}, packet.Data)
+		frame, ok := packet.Data.(pipeline.AudioFrame)
 		assert.True(t, ok)
-		assert.NotEmpty(t, audioData)
+		assert.NotZero(t, frame.Len())
 	})
 
 	// 测试设置音色
@@ -185,11 +185,11 @@ func TestTencentTTS_WithOpusEncoding(t *testing.T) {
 	resultReceived := false
 	oggDumper.SetOutput(func(packet pipeline.Packet) {
 		resultReceived = true
-		// Check if it's an RTPAudioPacket
-		audioPacket, ok := packet.Data.(*codec.RTPAudioPacket)
+		// Check if it's an Opus frame
+		frame, ok := packet.Data.(pipeline.AudioFrame)
 		assert.True(t, ok)
-		assert.NotNil(t, audioPacket)
-		assert.NotEmpty(t, audioPacket.Payload())
+		assert.Equal(t, pipeline.SampleFormatOpus, frame.Format)
+		assert.NotEmpty(t, frame.Payload)
 	})
 
 	// 设置处理链
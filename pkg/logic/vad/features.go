@@ -0,0 +1,123 @@
+package vad
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// 谱平坦度只统计语音主要能量所在的频段
+const (
+	speechBandLow  = 100.0
+	speechBandHigh = 4000.0
+	silenceDB      = -100.0 // 全零窗口的能量
+)
+
+// Features 一个分析窗口的声学特征
+type Features struct {
+	Energy   float64 // RMS 能量，单位 dBFS
+	SNR      float64 // 高出噪声基底的分贝数
+	ZCR      float64 // 过零率，相邻采样符号变化的比例
+	Flatness float64 // 语音频段内的谱平坦度，0 为纯音，白噪声约 0.5 以上
+}
+
+// analyzer 计算窗口特征，复用 FFT 缓冲区
+type analyzer struct {
+	sampleRate int
+	fft        []complex128
+	window     []float64 // Hann 窗
+}
+
+func newAnalyzer(sampleRate, windowSize int) *analyzer {
+	size := 1
+	for size < windowSize {
+		size <<= 1
+	}
+	window := make([]float64, windowSize)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(windowSize-1))
+	}
+	return &analyzer{
+		sampleRate: sampleRate,
+		fft:        make([]complex128, size),
+		window:     window,
+	}
+}
+
+// analyze 计算窗口的能量、过零率与谱平坦度，SNR 由调用方根据噪声基底填写
+func (a *analyzer) analyze(samples []float64) Features {
+	var f Features
+
+	var sum float64
+	crossings := 0
+	for i, s := range samples {
+		sum += s * s
+		if i > 0 && (s >= 0) != (samples[i-1] >= 0) {
+			crossings++
+		}
+	}
+	f.Energy = silenceDB
+	if rms := math.Sqrt(sum / float64(len(samples))); rms > 0 {
+		f.Energy = math.Max(20*math.Log10(rms/32768), silenceDB)
+	}
+	if len(samples) > 1 {
+		f.ZCR = float64(crossings) / float64(len(samples)-1)
+	}
+	f.Flatness = a.flatness(samples)
+	return f
+}
+
+// flatness 计算语音频段功率谱的几何平均与算术平均之比
+func (a *analyzer) flatness(samples []float64) float64 {
+	for i := range a.fft {
+		a.fft[i] = 0
+	}
+	for i, s := range samples {
+		a.fft[i] = complex(s*a.window[i], 0)
+	}
+	fft(a.fft)
+
+	binHz := float64(a.sampleRate) / float64(len(a.fft))
+	low := int(math.Ceil(speechBandLow / binHz))
+	high := int(math.Min(speechBandHigh/binHz, float64(len(a.fft)/2)))
+	if high <= low {
+		return 1
+	}
+
+	var logSum, sum float64
+	for k := low; k <= high; k++ {
+		// 加上极小值，避免静音时取对数溢出
+		p := real(a.fft[k])*real(a.fft[k]) + imag(a.fft[k])*imag(a.fft[k]) + 1e-10
+		logSum += math.Log(p)
+		sum += p
+	}
+	n := float64(high - low + 1)
+	return math.Exp(logSum/n) / (sum / n)
+}
+
+// fft 原地计算基 2 快速傅里叶变换，长度必须为 2 的幂
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				u := x[start+k]
+				v := x[start+k+size/2] * w
+				x[start+k] = u + v
+				x[start+k+size/2] = u - v
+				w *= step
+			}
+		}
+	}
+}
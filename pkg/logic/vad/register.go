@@ -0,0 +1,16 @@
+package vad
+
+import "streamlink/pkg/logic/pipeline"
+
+func init() {
+	pipeline.Register("vad", func(params pipeline.Params) (pipeline.Component, error) {
+		cfg := DefaultConfig()
+		cfg.FrameDuration = params.Duration("frame_duration", cfg.FrameDuration)
+		cfg.EnergyThreshold = params.Float64("energy_threshold", cfg.EnergyThreshold)
+		cfg.SNRThreshold = params.Float64("snr_threshold", cfg.SNRThreshold)
+		cfg.FlatnessThreshold = params.Float64("flatness_threshold", cfg.FlatnessThreshold)
+		cfg.MinSpeechDuration = params.Duration("min_speech_duration", cfg.MinSpeechDuration)
+		cfg.Hangover = params.Duration("hangover", cfg.Hangover)
+		return NewVAD(cfg), nil
+	})
+}
@@ -0,0 +1,217 @@
+package vad
+
+import (
+	"streamlink/pkg/logger"
+	"streamlink/pkg/logic/pipeline"
+	"time"
+)
+
+// Config VAD 参数
+type Config struct {
+	FrameDuration     time.Duration // 分析窗口时长
+	EnergyThreshold   float64       // 语音窗口的最低能量，单位 dBFS
+	SNRThreshold      float64       // 语音窗口至少高出噪声基底的分贝数
+	FlatnessThreshold float64       // 语音窗口的最大谱平坦度，平坦的宽带噪声（风扇、电流声）高于该值
+	MinSpeechDuration time.Duration // 连续语音达到该时长才判定开始说话，过滤咳嗽、敲击等短促声音
+	Hangover          time.Duration // 语音结束后保持说话状态的时长，避免字词间的停顿被判定为停止说话
+	Model             Model         // 非空时由模型判断语音窗口，替代阈值判断
+}
+
+// DefaultConfig 返回默认参数，适用于 16kHz 单声道的麦克风输入
+func DefaultConfig() Config {
+	return Config{
+		FrameDuration:     20 * time.Millisecond,
+		EnergyThreshold:   -50,
+		SNRThreshold:      10,
+		FlatnessThreshold: 0.4,
+		MinSpeechDuration: 200 * time.Millisecond,
+		Hangover:          400 * time.Millisecond,
+	}
+}
+
+// Model 根据窗口特征给出语音概率，用于替换内置的阈值判断，如嵌入的小型分类模型
+type Model interface {
+	SpeechProbability(f Features) float64
+}
+
+// 噪声基底的初始值与跟踪速度
+const (
+	initialNoiseFloor = -70.0
+	noiseAdaptRate    = 0.05 // 非语音窗口向当前能量靠拢的比例，噪声基底只快速下降、缓慢上升
+)
+
+// VAD 本地语音活动检测组件，按能量与频谱特征判断用户是否在说话
+// 输入 PCM 音频，输出 pipeline.VoiceActivity 数据包，并在会话事件总线上发布 speech_started/speech_ended
+// VAD 不转发音频，通常通过扇出接在输入 Resampler 之后，输出连到 TurnManager
+type VAD struct {
+	*pipeline.Stage[pipeline.AudioFrame, pipeline.VoiceActivity]
+	config   Config
+	analyzer *analyzer
+
+	buffer     []float64     // 未满一个分析窗口的单声道采样
+	window     int           // 分析窗口的采样点数
+	sampleRate int           // 当前的输入采样率
+	pos        time.Duration // buffer 首个采样的媒体时间戳
+	started    bool          // 已收到第一帧
+	noiseFloor float64       // 噪声基底，单位 dBFS
+
+	speaking    bool
+	voiced      time.Duration // 未开始说话时连续语音窗口的时长
+	silence     time.Duration // 说话时连续非语音窗口的时长
+	speechStart time.Duration // 本段语音开始的媒体时间戳
+	turnSeq     int           // 最近一帧所属的轮次，冲刷时使用
+}
+
+// NewVAD 创建 VAD 组件
+func NewVAD(config Config) *VAD {
+	v := &VAD{
+		config:     config,
+		noiseFloor: initialNoiseFloor,
+	}
+	v.Stage = pipeline.NewStage("VAD", 100, v.detect)
+	v.SetInputFormat(pipeline.AudioFormat{Format: pipeline.SampleFormatS16})
+	// 语音检测作用于整条输入流，不按轮次过滤
+	v.SetIgnoreTurn(true)
+	// 流结束时结束正在进行的语音
+	v.SetFlushHandler(v.flush)
+	return v
+}
+
+// IsSpeaking 返回当前是否处于说话状态，只能在处理循环中调用
+func (v *VAD) IsSpeaking() bool {
+	return v.speaking
+}
+
+// detect 累积采样并按窗口判断语音，说话状态变化时输出 VoiceActivity
+func (v *VAD) detect(frame pipeline.AudioFrame, packet pipeline.Packet, emit func(pipeline.VoiceActivity)) error {
+	// 采样转换到内部缓冲区后即可归还
+	defer frame.Release()
+	v.turnSeq = packet.TurnSeq
+
+	if !v.started || frame.SampleRate != v.sampleRate {
+		v.reset(frame)
+	}
+
+	// 多声道取平均
+	channels := frame.Channels
+	for i := 0; i+channels <= len(frame.Samples); i += channels {
+		var sum float64
+		for c := 0; c < channels; c++ {
+			sum += float64(frame.Samples[i+c])
+		}
+		v.buffer = append(v.buffer, sum/float64(channels))
+	}
+
+	step := time.Duration(v.window) * time.Second / time.Duration(v.sampleRate)
+	consumed := 0
+	for len(v.buffer)-consumed >= v.window {
+		v.update(v.classify(v.buffer[consumed:consumed+v.window]), step, emit)
+		consumed += v.window
+		v.pos += step
+	}
+	v.buffer = append(v.buffer[:0], v.buffer[consumed:]...)
+	return nil
+}
+
+// reset 按输入帧的采样率重新初始化分析窗口，丢弃未满一个窗口的采样
+func (v *VAD) reset(frame pipeline.AudioFrame) {
+	v.sampleRate = frame.SampleRate
+	v.window = int(int64(frame.SampleRate) * int64(v.config.FrameDuration) / int64(time.Second))
+	if v.window < 2 {
+		v.window = 2
+	}
+	v.analyzer = newAnalyzer(frame.SampleRate, v.window)
+	v.buffer = v.buffer[:0]
+	if !v.started {
+		v.pos = frame.Timestamp
+		v.started = true
+	}
+}
+
+// classify 判断一个分析窗口是否为语音，并在非语音窗口上更新噪声基底
+func (v *VAD) classify(samples []float64) bool {
+	f := v.analyzer.analyze(samples)
+	f.SNR = f.Energy - v.noiseFloor
+
+	var speech bool
+	if v.config.Model != nil {
+		speech = v.config.Model.SpeechProbability(f) >= 0.5
+	} else {
+		speech = f.Energy >= v.config.EnergyThreshold &&
+			f.SNR >= v.config.SNRThreshold &&
+			f.Flatness <= v.config.FlatnessThreshold
+	}
+
+	if !speech {
+		if f.Energy < v.noiseFloor {
+			v.noiseFloor = f.Energy
+		} else {
+			v.noiseFloor += noiseAdaptRate * (f.Energy - v.noiseFloor)
+		}
+	}
+	return speech
+}
+
+// update 根据窗口判断结果推进说话状态
+func (v *VAD) update(speech bool, step time.Duration, emit func(pipeline.VoiceActivity)) {
+	if speech {
+		v.silence = 0
+		if v.speaking {
+			return
+		}
+		if v.voiced == 0 {
+			v.speechStart = v.pos
+		}
+		v.voiced += step
+		if v.voiced >= v.config.MinSpeechDuration {
+			v.speaking = true
+			v.voiced = 0
+			v.report(pipeline.VoiceActivity{Speaking: true, Timestamp: v.speechStart}, emit)
+		}
+		return
+	}
+
+	v.voiced = 0
+	if !v.speaking {
+		return
+	}
+	v.silence += step
+	if v.silence >= v.config.Hangover {
+		// 语音在第一个非语音窗口开始处结束
+		v.endSpeech(v.pos+step-v.silence, emit)
+	}
+}
+
+// endSpeech 在 end 处结束当前语音
+func (v *VAD) endSpeech(end time.Duration, emit func(pipeline.VoiceActivity)) {
+	v.speaking = false
+	v.silence = 0
+	v.report(pipeline.VoiceActivity{Timestamp: end, Duration: end - v.speechStart}, emit)
+}
+
+// report 发出说话状态的变化
+func (v *VAD) report(activity pipeline.VoiceActivity, emit func(pipeline.VoiceActivity)) {
+	event := pipeline.EventSpeechEnded
+	if activity.Speaking {
+		event = pipeline.EventSpeechStarted
+	}
+	logger.Info("[TurnSeq: %d] **%s** %s at %v", v.turnSeq, v.GetName(), event, activity.Timestamp)
+	v.Publish(event, v.turnSeq, activity)
+	emit(activity)
+}
+
+// flush 流结束或冲刷时结束正在进行的语音
+func (v *VAD) flush() {
+	if !v.speaking {
+		return
+	}
+	v.endSpeech(v.pos-v.silence, func(activity pipeline.VoiceActivity) {
+		v.ForwardPacket(pipeline.Packet{
+			Data:    activity,
+			Seq:     v.GetSeq(),
+			Src:     v,
+			TurnSeq: v.turnSeq,
+		})
+		v.IncrSeq()
+	})
+}
@@ -0,0 +1,108 @@
+package vad
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"streamlink/internal/config"
+	"streamlink/pkg/logger"
+	"streamlink/pkg/logic/pipeline"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger(&config.LogConfig{Level: "error"})
+	os.Exit(m.Run())
+}
+
+const testSampleRate = 16000
+
+// signal 生成时长为 d 的单声道采样
+type signal func(i int) int16
+
+func silence(int) int16 { return 0 }
+
+func tone(i int) int16 {
+	return int16(8000 * math.Sin(2*math.Pi*220*float64(i)/testSampleRate))
+}
+
+func noise(rng *rand.Rand) signal {
+	return func(int) int16 { return int16(rng.Intn(4000) - 2000) }
+}
+
+// feed 以 20ms 一帧的方式送入信号，返回送入后的媒体时间戳
+func feed(v *VAD, ts time.Duration, d time.Duration, gen signal) time.Duration {
+	frame := 20 * time.Millisecond
+	n := int(frame) * testSampleRate / int(time.Second)
+	for end := ts + d; ts < end; ts += frame {
+		samples := make([]int16, n)
+		for i := range samples {
+			samples[i] = gen(int(ts)*testSampleRate/int(time.Second) + i)
+		}
+		v.Process(pipeline.Packet{Data: pipeline.NewPCMFrame(samples, testSampleRate, 1, ts)})
+	}
+	return ts
+}
+
+func startVAD(t *testing.T) *VAD {
+	v := NewVAD(DefaultConfig())
+	v.SetInputChan(make(chan pipeline.Packet, 1000))
+	assert.NoError(t, v.Start())
+	t.Cleanup(v.Stop)
+	return v
+}
+
+func recvActivity(t *testing.T, v *VAD) pipeline.VoiceActivity {
+	select {
+	case packet := <-v.GetOutputChan():
+		return packet.Data.(pipeline.VoiceActivity)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for voice activity")
+		return pipeline.VoiceActivity{}
+	}
+}
+
+func TestVAD_SpeechStartEnd(t *testing.T) {
+	v := startVAD(t)
+
+	ts := feed(v, 0, 500*time.Millisecond, silence)
+	// 短促的声音不足最小语音时长
+	ts = feed(v, ts, 100*time.Millisecond, tone)
+	ts = feed(v, ts, 500*time.Millisecond, silence)
+	// 字词间短于 hangover 的停顿不结束语音
+	ts = feed(v, ts, 400*time.Millisecond, tone)
+	ts = feed(v, ts, 200*time.Millisecond, silence)
+	ts = feed(v, ts, 400*time.Millisecond, tone)
+	feed(v, ts, time.Second, silence)
+
+	start := recvActivity(t, v)
+	assert.True(t, start.Speaking)
+	assert.Equal(t, 1100*time.Millisecond, start.Timestamp)
+
+	end := recvActivity(t, v)
+	assert.False(t, end.Speaking)
+	assert.Equal(t, 2100*time.Millisecond, end.Timestamp)
+	assert.Equal(t, time.Second, end.Duration)
+	assert.Empty(t, v.GetOutputChan())
+}
+
+func TestVAD_IgnoresBroadbandNoise(t *testing.T) {
+	v := startVAD(t)
+
+	// 能量远高于阈值但频谱平坦的宽带噪声不是语音，噪声基底随之抬高
+	ts := feed(v, 0, time.Second, noise(rand.New(rand.NewSource(1))))
+	ts = feed(v, ts, 400*time.Millisecond, tone)
+	v.Process(pipeline.Packet{Command: pipeline.PacketCommandFlush})
+
+	start := recvActivity(t, v)
+	assert.True(t, start.Speaking)
+	assert.Equal(t, time.Second, start.Timestamp)
+
+	// 冲刷时结束正在进行的语音
+	end := recvActivity(t, v)
+	assert.False(t, end.Speaking)
+	assert.Equal(t, ts, end.Timestamp)
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"streamlink/pkg/logger"
+	"streamlink/pkg/logic/pipeline"
+	"streamlink/pkg/server/agent"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// swapTimeout 热替换组件时等待旧组件退出的最长时间
+const swapTimeout = 10 * time.Second
+
+// replaceComponentRequest 热替换组件的请求
+type replaceComponentRequest struct {
+	Type   string                 `json:"type" binding:"required"` // 注册的组件类型，如 openai、tencent_stream_tts
+	Params map[string]interface{} `json:"params"`                  // 组件参数，未声明的取全局配置
+	Queue  string                 `json:"queue"`                   // 排队数据的处理方式：move/drain/drop
+}
+
+// getVoiceAgent 查找会话的语音代理
+func (s *WHIPServer) getVoiceAgent(sessionID string) *agent.VoiceAgent {
+	conn, ok := s.connections.Load(sessionID)
+	if !ok {
+		return nil
+	}
+	holder, ok := conn.(interface{ GetVoiceAgent() *agent.VoiceAgent })
+	if !ok {
+		return nil
+	}
+	return holder.GetVoiceAgent()
+}
+
+// HandleReplaceComponent 在会话进行中替换 pipeline 节点，不断开 WebRTC 连接
+// 例如服务商质量下降时切换 LLM 模型、TTS 音色或 ASR 引擎
+func (s *WHIPServer) HandleReplaceComponent(c *gin.Context) {
+	sessionID := c.Param("id")
+	node := c.Param("node")
+
+	var req replaceComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	queue, ok := pipeline.ParseSwapQueuePolicy(req.Queue)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown queue policy: " + req.Queue})
+		return
+	}
+
+	voiceAgent := s.getVoiceAgent(sessionID)
+	if voiceAgent == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), swapTimeout)
+	defer cancel()
+	if err := voiceAgent.ReplaceComponent(ctx, node, req.Type, req.Params, pipeline.SwapOptions{Queue: queue}); err != nil {
+		logger.Error("[%s] Failed to replace node %s: %v", sessionID, node, err)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"session": sessionID, "node": node, "type": req.Type, "queue": queue.String()})
+}
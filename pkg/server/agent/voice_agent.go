@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
@@ -11,6 +12,13 @@ import (
 	"streamlink/pkg/logic/pipeline"
 	"streamlink/pkg/logic/stt"
 	"streamlink/pkg/logic/tts"
+	"sync"
+
+	// 注册 pipelines 定义中可用的组件类型
+	_ "streamlink/pkg/logic/codec"
+	_ "streamlink/pkg/logic/dumper"
+	_ "streamlink/pkg/logic/resampler"
+	_ "streamlink/pkg/logic/vad"
 )
 
 // VoiceAgent 处理语音对话的代理
@@ -23,10 +31,20 @@ type VoiceAgent struct {
 	llm         *llm.DeepSeek
 	tts         interface{ pipeline.Component }
 	stopCh      chan struct{}
+	stopErr     error
 	processor   flux.AudioProcessor
 	turnManager *pipeline.TurnManager
+	onFatal     func(error)
+	nodes       map[string]pipeline.Component // 按节点名索引的组件，用于热替换
+	nodesMu     sync.Mutex
+	traces      *pipeline.TraceRecorder // 最近完成的轮次延迟追踪
+	events      *pipeline.EventBus      // 会话事件总线，组件发布轮次、打断、识别结果与错误事件
+	transcript  *pipeline.Transcript    // 会话中用户与代理的全部发言
 }
 
+// traceCapacity 每个会话保留的轮次追踪数
+const traceCapacity = 100
+
 // NewVoiceAgent 创建一个新的语音代理
 func NewVoiceAgent(config *config.Config, source flux.Source, sink flux.Sink, processor flux.AudioProcessor) *VoiceAgent {
 	// 如果没有提供处理器，使用默认处理器
@@ -34,33 +52,168 @@ func NewVoiceAgent(config *config.Config, source flux.Source, sink flux.Sink, pr
 		processor = flux.NewDefaultAudioProcessor()
 	}
 
-	// 创建 ASR 实例
-	appIDStr := config.ASR.TencentASR.AppID
+	return &VoiceAgent{
+		config:     config,
+		source:     source,
+		sink:       sink,
+		stopCh:     make(chan struct{}),
+		processor:  processor,
+		traces:     pipeline.NewTraceRecorder(traceCapacity),
+		events:     pipeline.NewEventBus(),
+		transcript: pipeline.NewTranscript(),
+	}
+}
+
+// SetAudioProcessor 设置音频处理器, 不允许设置为nil
+func (v *VoiceAgent) SetAudioProcessor(processor flux.AudioProcessor) error {
+	if processor == nil {
+		return fmt.Errorf("processor is nil")
+	}
+	v.processor = processor
+	return nil
+}
+
+// Start 启动语音代理，ctx 用于取消启动过程
+func (v *VoiceAgent) Start(ctx context.Context) error {
+	// 创建 Pipeline，配置了 pipelines 定义时按配置构建
+	var pipe *pipeline.Pipeline
+	var err error
+	if v.config.Server.Pipeline != "" {
+		pipe, err = v.buildConfiguredPipeline(v.config.Server.Pipeline)
+	} else {
+		pipe, err = v.buildDefaultPipeline()
+	}
+	if err != nil {
+		logger.Error("Failed to connect output chain:", err)
+		return err
+	}
+
+	// 组件失败时自动重启，无法恢复时结束会话
+	supervisor := pipeline.NewSupervisor(restartPolicy(v.config.Server.Restart))
+	supervisor.OnEscalate(func(c pipeline.Component, err error) {
+		if v.onFatal != nil {
+			go v.onFatal(err)
+		}
+	})
+	pipe.SetSupervisor(supervisor)
+	if v.config.Server.Health.Interval > 0 {
+		pipe.SetHealthCheckInterval(v.config.Server.Health.Interval)
+	}
+	pipe.SetHealthThresholds(healthThresholds(v.config.Server.Health))
+	pipe.SetTraceRecorder(v.traces)
+	pipe.SetEventBus(v.events)
+	v.traces.OnRecord(v.transcript.AddTrace)
+	v.transcript.Follow(v.events)
+
+	// 启动 pipeline
+	if err := pipe.Start(ctx); err != nil {
+		logger.Error("Failed to start pipeline:", err)
+		return err
+	}
+
+	v.pipeline = pipe
+
+	return nil
+}
+
+// turnAudioBufferSize 内置链路中送往 TurnManager 的输入音频的缓冲大小
+const turnAudioBufferSize = 100
+
+// buildDefaultPipeline 构建内置的 asr -> turnManager -> llm -> tts 语音对话链路，输入音频同时送往 turnManager
+func (v *VoiceAgent) buildDefaultPipeline() (*pipeline.Pipeline, error) {
+	pipe := pipeline.NewPipelineWithSource(v.source)
+
+	// ASR、LLM 与 TTS 只在内置链路中按全局配置创建，pipelines 定义中的节点由注册表创建
+	v.asr = newASR(v.config)
+	v.llm = newLLM(v.config)
+	v.tts = newTTS(v.config)
+
+	// 创建 TurnManager
+	v.turnManager = pipeline.NewTurnManager(turnManagerConfig(v.config.Server.Turn))
+	v.turnManager.SetIgnoreTurn(true)
+	v.turnManager.SetUseInterrupt(v.config.Server.Interrupt)
+	v.turnManager.SetSpeculative(v.config.Server.Speculative)
+	if name := v.config.Server.Turn.Scorer; name != "" {
+		scorer, err := pipeline.NewScorer(name, componentDefaults(v.config)["turn_manager"])
+		if err != nil {
+			return nil, err
+		}
+		v.turnManager.SetScorer(scorer)
+	}
+	// 获取基础组件，开启打断检测时接在 ASR 之前
+	var bargeIn []pipeline.Component
+	if v.config.Server.BargeIn.Enabled {
+		b := pipeline.NewBargeIn(bargeInConfig(v.config.Server.BargeIn))
+		v.trackComponent(b)
+		bargeIn = append(bargeIn, b)
+	}
+	chain := append(bargeIn, v.asr)
+	// 开启语义打断时接在 ASR 之后，代理说话期间的附和与噪声不进入 TurnManager
+	if v.config.Server.SemanticInterrupt {
+		s, err := pipeline.NewComponent("semantic_interrupt", componentDefaults(v.config)["semantic_interrupt"])
+		if err != nil {
+			return nil, err
+		}
+		v.trackComponent(s)
+		chain = append(chain, s)
+	}
+	input := v.processor.ProcessInput(v.source)
+	components := flux.GenComponents(input,
+		v.processor.ProcessOutput(v.sink),
+		append(chain, v.turnManager, v.llm, v.tts)...)
+	g := pipeline.NewGraph().Chain(append([]pipeline.Component{v.source}, components...)...)
+
+	// 输入音频同时送往 TurnManager，用户仍在说话时推迟静音超时
+	// 该边单独缓冲并丢弃最旧的音频，TurnManager 处理不及时不会阻塞 ASR
+	audio := input.Last
+	if audio == nil {
+		audio = v.source
+	}
+	dropOldest := pipeline.DropOldestPolicy()
+	g.AddEdge(audio, v.turnManager, pipeline.EdgeOptions{BufferSize: turnAudioBufferSize, Backpressure: &dropOldest})
+
+	if err := pipe.ConnectGraph(g); err != nil {
+		return nil, err
+	}
+	v.nodes = map[string]pipeline.Component{
+		"asr":          v.asr,
+		"turn_manager": v.turnManager,
+		"llm":          v.llm,
+		"tts":          v.tts,
+	}
+	return pipe, nil
+}
+
+// newASR 按全局配置创建内置链路使用的 ASR
+func newASR(cfg *config.Config) *stt.TencentAsr {
+	appIDStr := cfg.ASR.TencentASR.AppID
 	if appIDStr != "" && appIDStr[0] == '$' {
 		appIDStr = os.Getenv(appIDStr[1:])
 	}
-	secretID := config.ASR.TencentASR.SecretID
+	secretID := cfg.ASR.TencentASR.SecretID
 	if secretID != "" && secretID[0] == '$' {
 		secretID = os.Getenv(secretID[1:])
 	}
-	secretKey := config.ASR.TencentASR.SecretKey
+	secretKey := cfg.ASR.TencentASR.SecretKey
 	if secretKey != "" && secretKey[0] == '$' {
 		secretKey = os.Getenv(secretKey[1:])
 	}
-	asr := stt.NewTencentAsr(
+	return stt.NewTencentAsr(
 		appIDStr,
 		secretID,
 		secretKey,
-		config.ASR.TencentASR.EngineModelType,
-		config.ASR.TencentASR.SliceSize,
+		cfg.ASR.TencentASR.EngineModelType,
+		cfg.ASR.TencentASR.SliceSize,
 	)
+}
 
-	// 创建 LLM 实例
-	apiKey := config.LLM.OpenAI.APIKey
+// newLLM 按全局配置创建内置链路使用的 LLM
+func newLLM(cfg *config.Config) *llm.DeepSeek {
+	apiKey := cfg.LLM.OpenAI.APIKey
 	if apiKey != "" && apiKey[0] == '$' {
 		apiKey = os.Getenv(apiKey[1:])
 	}
-	baseURL := config.LLM.OpenAI.BaseURL
+	baseURL := cfg.LLM.OpenAI.BaseURL
 	if baseURL != "" && baseURL[0] == '$' {
 		baseURL = os.Getenv(baseURL[1:])
 	}
@@ -68,14 +221,18 @@ func NewVoiceAgent(config *config.Config, source flux.Source, sink flux.Sink, pr
 		apiKey,
 		baseURL,
 	)
-	llmInstance.SetModel(config.LLM.OpenAI.Model)
+	llmInstance.SetModel(cfg.LLM.OpenAI.Model)
 	// Configure LLM streaming based on low latency mode
-	if config.Server.LowLatency {
+	if cfg.Server.LowLatency {
 		llmInstance.SetStreaming(true)
 	}
+	llmInstance.SetSpeculative(cfg.Server.Speculative)
+	return llmInstance
+}
 
-	// 创建 TTS 实例
-	appIDStr = config.TTS.TencentTTS.AppID
+// newTTS 按全局配置创建内置链路使用的 TTS，低延迟模式下使用流式合成
+func newTTS(cfg *config.Config) pipeline.Component {
+	appIDStr := cfg.TTS.TencentTTS.AppID
 	if appIDStr != "" && appIDStr[0] == '$' {
 		appIDStr = os.Getenv(appIDStr[1:])
 	}
@@ -84,94 +241,405 @@ func NewVoiceAgent(config *config.Config, source flux.Source, sink flux.Sink, pr
 		logger.Error("Failed to parse appID: %v", err)
 		appID = 0
 	}
-	secretID = config.TTS.TencentTTS.SecretID
+	secretID := cfg.TTS.TencentTTS.SecretID
 	if secretID != "" && secretID[0] == '$' {
 		secretID = os.Getenv(secretID[1:])
 	}
-	secretKey = config.TTS.TencentTTS.SecretKey
+	secretKey := cfg.TTS.TencentTTS.SecretKey
 	if secretKey != "" && secretKey[0] == '$' {
 		secretKey = os.Getenv(secretKey[1:])
 	}
 
-	var ttsInstance interface{ pipeline.Component }
-	if config.Server.LowLatency {
-		ttsInstance = tts.NewTencentStreamTTS(
-			appID,
-			secretID,
-			secretKey,
-			config.TTS.TencentTTS.VoiceType,
-			config.TTS.TencentTTS.Codec,
-		)
-	} else {
-		ttsInstance = tts.NewTencentTTS(
+	if cfg.Server.LowLatency {
+		return tts.NewTencentStreamTTS(
 			appID,
 			secretID,
 			secretKey,
-			config.TTS.TencentTTS.VoiceType,
-			config.TTS.TencentTTS.Codec,
+			cfg.TTS.TencentTTS.VoiceType,
+			cfg.TTS.TencentTTS.Codec,
 		)
 	}
+	return tts.NewTencentTTS(
+		appID,
+		secretID,
+		secretKey,
+		cfg.TTS.TencentTTS.VoiceType,
+		cfg.TTS.TencentTTS.Codec,
+	)
+}
+
+// buildConfiguredPipeline 按 config.yaml 中的 pipelines 定义构建 pipeline
+// 节点 source/sink 绑定为连接提供的音频源与接收端，音频前后处理也由定义声明，不再使用 AudioProcessor
+func (v *VoiceAgent) buildConfiguredPipeline(name string) (*pipeline.Pipeline, error) {
+	def, ok := v.config.Pipelines[name]
+	if !ok {
+		return nil, fmt.Errorf("pipeline %q is not defined in config", name)
+	}
 
-	return &VoiceAgent{
-		config:    config,
-		source:    source,
-		sink:      sink,
-		asr:       asr,
-		llm:       llmInstance,
-		tts:       ttsInstance,
-		stopCh:    make(chan struct{}),
-		processor: processor,
+	g, nodes, err := pipeline.BuildGraph(graphDef(def, disabledNodeTypes(v.config)), pipeline.BuildOptions{
+		Bindings: map[string]pipeline.Component{
+			"source": v.source,
+			"sink":   v.sink,
+		},
+		Defaults: componentDefaults(v.config),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("build pipeline %q: %w", name, err)
+	}
+
+	pipe := pipeline.NewPipeline()
+	if err := pipe.ConnectGraph(g); err != nil {
+		return nil, fmt.Errorf("connect pipeline %q: %w", name, err)
+	}
+
+	v.nodes = nodes
+	for _, c := range nodes {
+		v.trackComponent(c)
 	}
+	return pipe, nil
 }
 
-// SetAudioProcessor 设置音频处理器, 不允许设置为nil
-func (v *VoiceAgent) SetAudioProcessor(processor flux.AudioProcessor) error {
-	if processor == nil {
-		return fmt.Errorf("processor is nil")
+// graphDef 将配置中的 pipelines 定义转换为组件图定义
+// 类型在 disabled 中的节点被移出定义：chain 中前后节点直接相连，与其相连的额外边一并去除
+func graphDef(cfg config.PipelineConfig, disabled map[string]bool) pipeline.GraphDef {
+	var def pipeline.GraphDef
+	skipped := make(map[string]bool)
+	for _, n := range cfg.Nodes {
+		if n.Type != "" && disabled[n.Type] {
+			logger.Info("VoiceAgent: %s is disabled, skipping pipeline node %s", n.Type, n.Name)
+			skipped[n.Name] = true
+			continue
+		}
+		def.Nodes = append(def.Nodes, pipeline.NodeDef{
+			Name:       n.Name,
+			Type:       n.Type,
+			Params:     n.Params,
+			IgnoreTurn: n.IgnoreTurn,
+		})
 	}
-	v.processor = processor
-	return nil
+	for _, name := range cfg.Chain {
+		if !skipped[name] {
+			def.Chain = append(def.Chain, name)
+		}
+	}
+	for _, e := range cfg.Edges {
+		if skipped[e.From] || skipped[e.To] {
+			continue
+		}
+		def.Edges = append(def.Edges, pipeline.EdgeDef{
+			From:         e.From,
+			To:           e.To,
+			BufferSize:   e.BufferSize,
+			Backpressure: e.Backpressure,
+			Timeout:      e.Timeout,
+		})
+	}
+	return def
 }
 
-// Start 启动语音代理
-func (v *VoiceAgent) Start() error {
-	// 创建 Pipeline
-	pipe := pipeline.NewPipelineWithSource(v.source)
+// disabledNodeTypes 返回由开关关闭的组件类型，内置链路与 pipelines 定义使用同样的开关
+func disabledNodeTypes(cfg *config.Config) map[string]bool {
+	return map[string]bool{
+		"barge_in":           !cfg.Server.BargeIn.Enabled,
+		"semantic_interrupt": !cfg.Server.SemanticInterrupt,
+	}
+}
 
-	// 创建 TurnManager
-	v.turnManager = pipeline.NewTurnManager(pipeline.DefaultTurnManagerConfig())
-	v.turnManager.SetIgnoreTurn(true)
-	v.turnManager.SetUseInterrupt(v.config.Server.Interrupt)
-	// 获取基础组件
-	components := flux.GenComponents(v.processor.ProcessInput(v.source),
-		v.processor.ProcessOutput(v.sink),
-		v.asr, v.turnManager, v.llm, v.tts)
+// trackComponent 记录代理需要直接访问的组件
+func (v *VoiceAgent) trackComponent(c pipeline.Component) {
+	switch c := c.(type) {
+	case *pipeline.TurnManager:
+		v.turnManager = c
+	case interface {
+		SetPlayback(pipeline.PlaybackMonitor)
+	}:
+		// BargeIn 与 SemanticInterrupt 需要知道代理是否在说话
+		if playback, ok := v.sink.(pipeline.PlaybackMonitor); ok {
+			c.SetPlayback(playback)
+		}
+	case *stt.TencentAsr:
+		v.asr = c
+	case *llm.DeepSeek:
+		v.llm = c
+	case *tts.TencentTTS, *tts.TencentStreamTTS:
+		v.tts = c
+	}
+}
 
-	if err := pipe.Connect(components...); err != nil {
-		logger.Error("Failed to connect output chain:", err)
-		return err
+// ReplaceComponent 在会话进行中将节点 node 替换为 typeName 类型的新组件
+// params 未声明的参数取全局配置中的默认值，如只切换 LLM 模型或 TTS 音色
+func (v *VoiceAgent) ReplaceComponent(ctx context.Context, node, typeName string, params pipeline.Params, opts pipeline.SwapOptions) error {
+	v.nodesMu.Lock()
+	defer v.nodesMu.Unlock()
+
+	if v.pipeline == nil {
+		return fmt.Errorf("voice agent not started")
+	}
+	old, ok := v.nodes[node]
+	if !ok {
+		return fmt.Errorf("unknown pipeline node %q", node)
 	}
 
-	// 启动 pipeline
-	if err := pipe.Start(); err != nil {
-		logger.Error("Failed to start pipeline:", err)
+	next, err := pipeline.NewComponent(typeName, params.WithDefaults(componentDefaults(v.config)[typeName]))
+	if err != nil {
+		return err
+	}
+	if err := v.pipeline.Replace(ctx, old, next, opts); err != nil {
+		if !containsComponent(v.pipeline.Components(), next) {
+			// 未接入 pipeline 的新组件直接释放
+			next.Stop()
+			return err
+		}
+		// 新组件已接入但启动失败，由 Supervisor 处理
+		v.nodes[node] = next
+		v.trackComponent(next)
 		return err
 	}
 
-	v.pipeline = pipe
-
+	v.nodes[node] = next
+	v.trackComponent(next)
+	logger.Info("VoiceAgent: replaced node %s with %s", node, typeName)
 	return nil
 }
 
-// Stop 停止语音代理
-func (v *VoiceAgent) Stop() {
+func containsComponent(components []pipeline.Component, target pipeline.Component) bool {
+	for _, c := range components {
+		if c == target {
+			return true
+		}
+	}
+	return false
+}
+
+// componentDefaults 以 asr/llm/tts 等全局配置作为对应组件类型的默认参数
+// pipelines 定义中的节点只需声明与全局配置不同的参数
+func componentDefaults(cfg *config.Config) map[string]pipeline.Params {
+	ttsParams := pipeline.Params{
+		"app_id":     cfg.TTS.TencentTTS.AppID,
+		"secret_id":  cfg.TTS.TencentTTS.SecretID,
+		"secret_key": cfg.TTS.TencentTTS.SecretKey,
+		"voice_type": cfg.TTS.TencentTTS.VoiceType,
+		"codec":      cfg.TTS.TencentTTS.Codec,
+	}
+	turn := turnManagerConfig(cfg.Server.Turn)
+	bargeIn := bargeInConfig(cfg.Server.BargeIn)
+	return map[string]pipeline.Params{
+		"tencent_asr": {
+			"app_id":            cfg.ASR.TencentASR.AppID,
+			"secret_id":         cfg.ASR.TencentASR.SecretID,
+			"secret_key":        cfg.ASR.TencentASR.SecretKey,
+			"engine_model_type": cfg.ASR.TencentASR.EngineModelType,
+			"slice_size":        cfg.ASR.TencentASR.SliceSize,
+		},
+		"openai": {
+			"api_key":     cfg.LLM.OpenAI.APIKey,
+			"base_url":    cfg.LLM.OpenAI.BaseURL,
+			"model":       cfg.LLM.OpenAI.Model,
+			"streaming":   cfg.Server.LowLatency,
+			"speculative": cfg.Server.Speculative,
+		},
+		"tencent_tts":        ttsParams,
+		"tencent_stream_tts": ttsParams,
+		"turn_manager": {
+			"interrupt":           cfg.Server.Interrupt,
+			"speculative":         cfg.Server.Speculative,
+			"silence_timeout":     turn.SilenceTimeout,
+			"max_turn_duration":   turn.MaxTurnDuration,
+			"speech_threshold":    turn.SpeechThreshold,
+			"language":            turn.Language,
+			"languages":           turn.Languages,
+			"scorer":              cfg.Server.Turn.Scorer,
+			"scorer_model":        cfg.Server.Turn.ScorerModel,
+			"min_silence_timeout": turn.MinSilenceTimeout,
+			"max_silence_timeout": turn.MaxSilenceTimeout,
+			"api_key":             cfg.LLM.OpenAI.APIKey,
+			"base_url":            cfg.LLM.OpenAI.BaseURL,
+		},
+		"barge_in": {
+			"min_duration":     bargeIn.MinDuration,
+			"energy_threshold": bargeIn.EnergyThreshold,
+			"cooldown":         bargeIn.Cooldown,
+		},
+		"semantic_interrupt": semanticInterruptParams(cfg),
+	}
+}
+
+// semanticInterruptParams 根据配置生成语义打断组件的参数，openai 分类复用 LLM 的接入配置
+func semanticInterruptParams(cfg *config.Config) pipeline.Params {
+	params := pipeline.Params{
+		"classifier": cfg.Server.InterruptClassifier.Type,
+		"api_key":    cfg.LLM.OpenAI.APIKey,
+		"base_url":   cfg.LLM.OpenAI.BaseURL,
+	}
+	if params["classifier"] == "" {
+		params["classifier"] = "rules"
+	}
+	if cfg.Server.InterruptClassifier.Model != "" {
+		params["model"] = cfg.Server.InterruptClassifier.Model
+	}
+	if cfg.Server.InterruptClassifier.HoldTimeout > 0 {
+		params["hold_timeout"] = cfg.Server.InterruptClassifier.HoldTimeout
+	}
+	return params
+}
+
+// OnFatal 设置不可恢复错误的回调，通常用于结束整个会话，需在 Start 之前调用
+func (v *VoiceAgent) OnFatal(fn func(error)) {
+	v.onFatal = fn
+}
+
+// restartPolicy 根据配置生成重启策略，未配置的字段使用默认值
+func restartPolicy(cfg config.RestartConfig) pipeline.RestartPolicy {
+	policy := pipeline.DefaultRestartPolicy()
+	if cfg.InitialBackoff > 0 {
+		policy.InitialBackoff = cfg.InitialBackoff
+	}
+	if cfg.MaxBackoff > 0 {
+		policy.MaxBackoff = cfg.MaxBackoff
+	}
+	if cfg.MaxRestarts > 0 {
+		policy.MaxRestarts = cfg.MaxRestarts
+	}
+	if cfg.Window > 0 {
+		policy.Window = cfg.Window
+	}
+	return policy
+}
+
+// healthThresholds 根据配置生成健康检查阈值，未配置的字段使用默认值
+func healthThresholds(cfg config.HealthConfig) pipeline.HealthThresholds {
+	thresholds := pipeline.DefaultHealthThresholds()
+	if cfg.QueueGrowthChecks > 0 {
+		thresholds.QueueGrowthWarning = cfg.QueueGrowthChecks
+	}
+	if cfg.StallWarning > 0 {
+		thresholds.StallWarning = cfg.StallWarning
+	}
+	if cfg.StallError > 0 {
+		thresholds.StallError = cfg.StallError
+	}
+	if cfg.ErrorRateWarning > 0 {
+		thresholds.ErrorRateWarning = cfg.ErrorRateWarning
+	}
+	return thresholds
+}
+
+// turnManagerConfig 根据配置生成轮次切分参数，未配置的字段与语言使用默认值
+func turnManagerConfig(cfg config.TurnConfig) pipeline.TurnManagerConfig {
+	turn := pipeline.DefaultTurnManagerConfig()
+	if cfg.SilenceTimeout > 0 {
+		turn.SilenceTimeout = cfg.SilenceTimeout
+	}
+	if cfg.MaxTurnDuration > 0 {
+		turn.MaxTurnDuration = cfg.MaxTurnDuration
+	}
+	if cfg.SpeechThreshold > 0 {
+		turn.SpeechThreshold = cfg.SpeechThreshold
+	}
+	if cfg.Language != "" {
+		turn.Language = cfg.Language
+	}
+	if cfg.MinSilenceTimeout > 0 {
+		turn.MinSilenceTimeout = cfg.MinSilenceTimeout
+	}
+	if cfg.MaxSilenceTimeout > 0 {
+		turn.MaxSilenceTimeout = cfg.MaxSilenceTimeout
+	}
+	for lang, rules := range cfg.Languages {
+		turn.SetRules(lang, pipeline.EndpointRules{
+			PunctuationMarks:  rules.PunctuationMarks,
+			MinSentenceLength: rules.MinSentenceLength,
+		})
+	}
+	return turn
+}
+
+// bargeInConfig 根据配置生成打断检测参数，未配置的字段使用默认值
+func bargeInConfig(cfg config.BargeInConfig) pipeline.BargeInConfig {
+	bargeIn := pipeline.DefaultBargeInConfig()
+	if cfg.MinDuration > 0 {
+		bargeIn.MinDuration = cfg.MinDuration
+	}
+	if cfg.EnergyThreshold > 0 {
+		bargeIn.EnergyThreshold = cfg.EnergyThreshold
+	}
+	if cfg.Cooldown > 0 {
+		bargeIn.Cooldown = cfg.Cooldown
+	}
+	return bargeIn
+}
+
+// Stop 停止语音代理，排空已在途的数据（如已合成待播放的音频）后返回
+// ctx 到期时强制停止，返回各组件未能正常退出的聚合错误
+func (v *VoiceAgent) Stop(ctx context.Context) error {
 	select {
 	case <-v.stopCh:
-		return
+		return v.stopErr
 	default:
 		close(v.stopCh)
-		v.asr.Stop()
 	}
+
+	// 停止后关闭事件总线，订阅者的 channel 随之关闭
+	defer v.events.Close()
+
+	if v.pipeline == nil {
+		// pipeline 未启动成功时只需释放 ASR 连接
+		if v.asr != nil {
+			v.asr.Stop()
+		}
+		return nil
+	}
+	v.stopErr = v.pipeline.Stop(ctx)
+	return v.stopErr
+}
+
+// Profile 返回代理使用的 pipeline 定义名，内置链路为 default
+func (v *VoiceAgent) Profile() string {
+	if v.config.Server.Pipeline != "" {
+		return v.config.Server.Pipeline
+	}
+	return "default"
+}
+
+// Components 返回 pipeline 中的组件，未启动时返回 nil
+func (v *VoiceAgent) Components() []pipeline.Component {
+	if v.pipeline == nil {
+		return nil
+	}
+	return v.pipeline.Components()
+}
+
+// ComponentsWithReplaced 返回 pipeline 的当前组件与被热替换下来的组件，未启动时均为 nil
+func (v *VoiceAgent) ComponentsWithReplaced() (current, replaced []pipeline.Component) {
+	if v.pipeline == nil {
+		return nil, nil
+	}
+	return v.pipeline.ComponentsWithReplaced()
+}
+
+// Traces 返回最近完成的轮次延迟追踪，按完成顺序排列
+func (v *VoiceAgent) Traces() []pipeline.TurnTraceRecord {
+	return v.traces.Records()
+}
+
+// Trace 返回指定轮次的延迟追踪
+func (v *VoiceAgent) Trace(turnSeq int) (pipeline.TurnTraceRecord, bool) {
+	return v.traces.Get(turnSeq)
+}
+
+// Events 返回会话事件总线，订阅者无需接入音频链路即可接收事件
+func (v *VoiceAgent) Events() *pipeline.EventBus {
+	return v.events
+}
+
+// Transcript 返回会话中用户与代理的发言，按时间顺序排列
+func (v *VoiceAgent) Transcript() []pipeline.TranscriptEntry {
+	return v.transcript.Entries()
+}
+
+// SaveTranscript 将会话记录保存到 path
+func (v *VoiceAgent) SaveTranscript(path string) error {
+	return v.transcript.Save(path)
 }
 
 // Interrupt 发送打断指令
@@ -184,6 +652,8 @@ func (v *VoiceAgent) Interrupt() {
 
 // GetCurrentTurn 获取当前轮次信息
 func (v *VoiceAgent) GetCurrentTurn() *pipeline.TurnInfo {
+	v.nodesMu.Lock()
+	defer v.nodesMu.Unlock()
 	if v.turnManager != nil {
 		return v.turnManager.GetCurrentTurn()
 	}
@@ -192,6 +662,8 @@ func (v *VoiceAgent) GetCurrentTurn() *pipeline.TurnInfo {
 
 // GetPreviousTurn 获取上一轮次信息
 func (v *VoiceAgent) GetPreviousTurn() *pipeline.TurnInfo {
+	v.nodesMu.Lock()
+	defer v.nodesMu.Unlock()
 	if v.turnManager != nil {
 		return v.turnManager.GetPreviousTurn()
 	}
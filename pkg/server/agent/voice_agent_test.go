@@ -1,10 +1,12 @@
 package agent
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"runtime"
 	"streamlink/internal/config"
+	"streamlink/pkg/logger"
 	"streamlink/pkg/logic/codec"
 	"streamlink/pkg/logic/dumper"
 	"streamlink/pkg/logic/flux"
@@ -21,6 +23,7 @@ func init() {
 	if err := godotenv.Load("../../../.env.test"); err != nil {
 		panic("Error loading .env.test file")
 	}
+	logger.InitLogger(&config.LogConfig{Level: "error"})
 }
 
 // getProjectRoot 获取项目根目录
@@ -137,7 +140,7 @@ func TestVoiceAgent_WithFileAudio(t *testing.T) {
 	assert.NotNil(t, agent)
 
 	// 启动 VoiceAgent
-	err := agent.Start()
+	err := agent.Start(context.Background())
 	assert.NoError(t, err)
 
 	source.Start()
@@ -145,8 +148,10 @@ func TestVoiceAgent_WithFileAudio(t *testing.T) {
 	// 等待处理完成
 	time.Sleep(28 * time.Second)
 
-	// 停止 VoiceAgent
-	agent.Stop()
+	// 停止 VoiceAgent，等待已合成的音频写入文件
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, agent.Stop(ctx))
 
 	// 验证输出文件是否存在且不为空
 	stat, err := os.Stat(outputPath)
@@ -174,7 +179,7 @@ func TestVoiceAgent_ErrorHandling(t *testing.T) {
 	}
 
 	agent := NewVoiceAgent(cfg, source, sink, processor)
-	err := agent.Start()
+	err := agent.Start(context.Background())
 	assert.Error(t, err)
 }
 
@@ -201,7 +206,36 @@ func TestVoiceAgent_ComponentHealth(t *testing.T) {
 	assert.NotNil(t, agent)
 
 	// 启动并立即停止，检查组件状态
-	err := agent.Start()
+	err := agent.Start(context.Background())
 	assert.NoError(t, err)
-	agent.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, agent.Stop(ctx))
+}
+
+func TestGraphDef_DisabledNodes(t *testing.T) {
+	cfg := config.PipelineConfig{
+		Nodes: []config.NodeConfig{
+			{Name: "source"},
+			{Name: "barge_in", Type: "barge_in"},
+			{Name: "asr", Type: "tencent_asr"},
+			{Name: "vad", Type: "vad"},
+			{Name: "sink"},
+		},
+		Chain: []string{"source", "barge_in", "asr", "sink"},
+		Edges: []config.EdgeConfig{
+			{From: "source", To: "vad"},
+			{From: "barge_in", To: "sink"},
+		},
+	}
+
+	// 关闭的节点移出链路，前后节点直接相连
+	def := graphDef(cfg, map[string]bool{"barge_in": true})
+	assert.Len(t, def.Nodes, 4)
+	assert.Equal(t, []string{"source", "asr", "sink"}, def.Chain)
+	assert.Equal(t, []pipeline.EdgeDef{{From: "source", To: "vad"}}, def.Edges)
+
+	def = graphDef(cfg, map[string]bool{"barge_in": false})
+	assert.Equal(t, cfg.Chain, def.Chain)
+	assert.Len(t, def.Edges, 2)
 }
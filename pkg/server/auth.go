@@ -0,0 +1,32 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth 返回会话与管理接口的鉴权中间件，请求需携带 Authorization: Bearer <token>
+// 未配置 server.admin.token 时返回 false，调用方不应开放这些接口
+func (s *WHIPServer) AdminAuth() (gin.HandlerFunc, bool) {
+	token := s.config.Server.Admin.Token
+	if token != "" && token[0] == '$' {
+		token = os.Getenv(token[1:])
+	}
+	if token == "" {
+		return nil, false
+	}
+
+	expected := []byte(token)
+	return func(c *gin.Context) {
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), expected) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		c.Next()
+	}, true
+}
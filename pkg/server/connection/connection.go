@@ -14,6 +14,9 @@ type Connection interface {
 
 // ConnectionFactory 定义了创建不同类型连接的工厂接口
 type ConnectionFactory interface {
-	// CreateConnection 创建一个新的连接
-	CreateConnection(cfg *config.Config) (Connection, error)
+	// CreateConnection 创建一个新的连接，onFatal 在会话不可恢复时以连接 ID 回调，由调用方负责移除并停止连接
+	CreateConnection(cfg *config.Config, onFatal FatalHandler) (Connection, error)
 }
+
+// FatalHandler 处理连接的不可恢复错误
+type FatalHandler func(id string, err error)
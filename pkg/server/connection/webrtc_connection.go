@@ -1,7 +1,10 @@
 package connection
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"streamlink/internal/config"
@@ -82,12 +85,16 @@ func (p *webRTCAudioProcessor) ProcessOutput(sink pipeline.Component) flux.Proce
 	}
 }
 
+// drainTimeout 连接关闭时等待 pipeline 排空的最长时间
+const drainTimeout = 5 * time.Second
+
 type WebRTCConnection struct {
 	id              string
 	peerConnection  *webrtc.PeerConnection
 	config          *config.Config
 	localAudioTrack *webrtc.TrackLocalStaticSample
-	stopCh          chan struct{}
+	stopOnce        sync.Once
+	onFatal         FatalHandler
 	source          flux.Source
 	sink            flux.Sink
 	voiceAgent      *agent.VoiceAgent
@@ -103,7 +110,7 @@ func NewWebRTCFactory(api *webrtc.API, config webrtc.Configuration, udpMux ice.U
 	return &WebRTCFactory{api: api, webrtcConfig: config, udpMux: udpMux}
 }
 
-func (f *WebRTCFactory) CreateConnection(cfg *config.Config) (Connection, error) {
+func (f *WebRTCFactory) CreateConnection(cfg *config.Config, onFatal FatalHandler) (Connection, error) {
 	peerConnection, err := f.api.NewPeerConnection(f.webrtcConfig)
 	if err != nil {
 		return nil, err
@@ -113,7 +120,7 @@ func (f *WebRTCFactory) CreateConnection(cfg *config.Config) (Connection, error)
 		id:             fmt.Sprintf("%d", time.Now().UnixNano()),
 		peerConnection: peerConnection,
 		config:         cfg,
-		stopCh:         make(chan struct{}),
+		onFatal:        onFatal,
 	}
 
 	// 添加音频收发器
@@ -207,34 +214,51 @@ func (c *WebRTCConnection) Start() error {
 		outputChannels:   2, // 双声道输出
 	}
 	c.voiceAgent = agent.NewVoiceAgent(c.config, c.source, c.sink, processor)
+	c.voiceAgent.OnFatal(func(err error) {
+		logger.Error("[%s] Voice agent failed, closing connection: %v", c.id, err)
+		if c.onFatal != nil {
+			c.onFatal(c.id, err)
+			return
+		}
+		c.Stop()
+	})
 
 	// 启动 VoiceAgent
-	if err := c.voiceAgent.Start(); err != nil {
+	if err := c.voiceAgent.Start(context.Background()); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// Stop 停止连接
+// Stop 停止连接，可重复及并发调用，仅第一次生效
 func (c *WebRTCConnection) Stop() {
-	select {
-	case <-c.stopCh:
-		return
-	default:
-		close(c.stopCh)
+	c.stopOnce.Do(c.stop)
+}
+
+func (c *WebRTCConnection) stop() {
+	if c.voiceAgent != nil {
+		// source 与 sink 属于 pipeline，由 VoiceAgent 按序排空并停止
+		ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+		if err := c.voiceAgent.Stop(ctx); err != nil {
+			logger.Error("[%s] Failed to drain voice agent: %v", c.id, err)
+		}
+		cancel()
+		if dir := c.config.Server.Transcript.Dir; dir != "" {
+			if err := c.voiceAgent.SaveTranscript(filepath.Join(dir, c.id+".json")); err != nil {
+				logger.Error("[%s] Failed to save transcript: %v", c.id, err)
+			}
+		}
+	} else {
 		if c.source != nil {
 			c.source.Stop()
 		}
 		if c.sink != nil {
 			c.sink.Stop()
 		}
-		if c.voiceAgent != nil {
-			c.voiceAgent.Stop()
-		}
-		if c.peerConnection != nil {
-			c.peerConnection.Close()
-		}
+	}
+	if c.peerConnection != nil {
+		c.peerConnection.Close()
 	}
 }
 
@@ -242,6 +266,11 @@ func (c *WebRTCConnection) GetID() string {
 	return c.id
 }
 
+// GetVoiceAgent 返回连接的语音代理，连接未启动时为 nil
+func (c *WebRTCConnection) GetVoiceAgent() *agent.VoiceAgent {
+	return c.voiceAgent
+}
+
 // WebRTC 特有的方法
 func (c *WebRTCConnection) SetRemoteDescription(offer webrtc.SessionDescription) error {
 	return c.peerConnection.SetRemoteDescription(offer)
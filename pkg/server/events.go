@@ -0,0 +1,47 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"streamlink/pkg/logic/pipeline"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventStreamBuffer 每个事件流订阅的缓冲区大小，客户端读取过慢时丢弃事件
+const eventStreamBuffer = 256
+
+// HandleEvents 以 Server-Sent Events 推送会话事件，可用 types 参数按逗号分隔过滤事件类型
+// 会话结束时事件流随之结束
+func (s *WHIPServer) HandleEvents(c *gin.Context) {
+	sessionID := c.Param("id")
+	voiceAgent := s.getVoiceAgent(sessionID)
+	if voiceAgent == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+
+	var types []pipeline.EventType
+	if param := c.Query("types"); param != "" {
+		for _, t := range strings.Split(param, ",") {
+			types = append(types, pipeline.EventType(strings.TrimSpace(t)))
+		}
+	}
+
+	sub := voiceAgent.Events().Subscribe(eventStreamBuffer, types...)
+	defer sub.Unsubscribe()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(event.Type), event)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
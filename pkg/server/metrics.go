@@ -0,0 +1,303 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"streamlink/pkg/logic/pipeline"
+	"streamlink/pkg/server/agent"
+
+	"github.com/gin-gonic/gin"
+)
+
+// componentStates 导出的组件状态，每个状态一条时间序列
+var componentStates = []pipeline.ComponentState{
+	pipeline.ComponentStateInitial,
+	pipeline.ComponentStateStarting,
+	pipeline.ComponentStateRunning,
+	pipeline.ComponentStateWarning,
+	pipeline.ComponentStateStopping,
+	pipeline.ComponentStateStopped,
+	pipeline.ComponentStateError,
+}
+
+// seriesKey 指标的标签组合
+type seriesKey struct {
+	profile   string
+	component string
+}
+
+// componentSeries 同一标签组合下所有会话的组件指标之和
+type componentSeries struct {
+	processed     int64
+	dropped       int64
+	errors        int64
+	blocked       int64
+	coalesced     int64
+	inputQueue    int
+	outputQueue   int
+	states        map[pipeline.ComponentState]int
+	latencies     map[string]*pipeline.HistogramSnapshot
+	hasLiveGauges bool
+}
+
+// metricsSet 按标签聚合的组件指标
+type metricsSet map[seriesKey]*componentSeries
+
+func (m metricsSet) series(key seriesKey) *componentSeries {
+	s, ok := m[key]
+	if !ok {
+		s = &componentSeries{
+			states:    make(map[pipeline.ComponentState]int),
+			latencies: make(map[string]*pipeline.HistogramSnapshot),
+		}
+		m[key] = s
+	}
+	return s
+}
+
+// addCounters 累加计数器与直方图
+func (m metricsSet) addCounters(key seriesKey, health pipeline.ComponentHealth, latencies map[string]pipeline.HistogramSnapshot) {
+	s := m.series(key)
+	s.processed += health.ProcessedCount
+	s.dropped += health.DroppedCount
+	s.errors += health.ErrorCount
+	s.blocked += health.Backpressure.Blocked
+	s.coalesced += health.Backpressure.Coalesced
+	for name, h := range latencies {
+		s.mergeLatency(name, h)
+	}
+}
+
+func (s *componentSeries) mergeLatency(name string, h pipeline.HistogramSnapshot) {
+	merged, ok := s.latencies[name]
+	if !ok {
+		merged = &pipeline.HistogramSnapshot{}
+		s.latencies[name] = merged
+	}
+	merged.Merge(h)
+}
+
+// addAgent 将一个会话的组件指标加入聚合，live 为 true 时同时统计队列深度与状态
+// 被热替换下来的组件只计入计数器与直方图，保证替换后计数器不回退
+func (m metricsSet) addAgent(a *agent.VoiceAgent, live bool) {
+	profile := a.Profile()
+	current, replaced := a.ComponentsWithReplaced()
+	for _, c := range current {
+		m.addComponent(profile, c, live)
+	}
+	for _, c := range replaced {
+		m.addComponent(profile, c, false)
+	}
+}
+
+func (m metricsSet) addComponent(profile string, c pipeline.Component, live bool) {
+	key := seriesKey{profile: profile, component: componentName(c)}
+	health := c.GetHealth()
+	var latencies map[string]pipeline.HistogramSnapshot
+	if l, ok := c.(interface {
+		GetLatencies() map[string]pipeline.HistogramSnapshot
+	}); ok {
+		latencies = l.GetLatencies()
+	}
+	m.addCounters(key, health, latencies)
+	if live {
+		s := m.series(key)
+		s.inputQueue += health.InputQueueSize
+		s.outputQueue += health.OutputQueueSize
+		s.states[health.State]++
+		s.hasLiveGauges = true
+	}
+}
+
+// merge 将 other 的计数器与直方图累加到 m
+func (m metricsSet) merge(other metricsSet) {
+	for key, o := range other {
+		s := m.series(key)
+		s.processed += o.processed
+		s.dropped += o.dropped
+		s.errors += o.errors
+		s.blocked += o.blocked
+		s.coalesced += o.coalesced
+		for name, h := range o.latencies {
+			s.mergeLatency(name, *h)
+		}
+	}
+}
+
+// metricsCollector 汇总所有会话的组件指标
+// 已结束会话的计数器与直方图计入 retired，保证导出的计数器单调递增
+// 正在停止的会话已从 connections 移除但尚未计入 retired，期间记录在 stopping 中
+type metricsCollector struct {
+	mu       sync.Mutex
+	retired  metricsSet
+	stopping map[string]interface{}
+}
+
+func newMetricsCollector() *metricsCollector {
+	return &metricsCollector{
+		retired:  make(metricsSet),
+		stopping: make(map[string]interface{}),
+	}
+}
+
+// retire 记录即将移除的会话的最终指标，调用方需持有 mu
+func (c *metricsCollector) retire(conn interface{}) {
+	if a := voiceAgentOf(conn); a != nil {
+		c.retired.addAgent(a, false)
+	}
+}
+
+// voiceAgentOf 返回连接的语音代理，连接未启动时为 nil
+func voiceAgentOf(conn interface{}) *agent.VoiceAgent {
+	if holder, ok := conn.(interface{ GetVoiceAgent() *agent.VoiceAgent }); ok {
+		return holder.GetVoiceAgent()
+	}
+	return nil
+}
+
+// HandleMetrics 以 Prometheus 文本格式导出所有会话的组件指标
+func (s *WHIPServer) HandleMetrics(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(http.StatusOK)
+	s.writeMetrics(c.Writer)
+}
+
+// writeMetrics 聚合当前会话与已结束会话的指标并写出
+func (s *WHIPServer) writeMetrics(w io.Writer) {
+	set := make(metricsSet)
+	sessions := 0
+
+	// 持有锁期间会话不会从 connections 移入 retired，避免重复或遗漏计数
+	s.metrics.mu.Lock()
+	s.connections.Range(func(_, conn interface{}) bool {
+		sessions++
+		if a := voiceAgentOf(conn); a != nil {
+			set.addAgent(a, true)
+		}
+		return true
+	})
+	// 正在停止的会话只计入计数器，不再算作活跃会话
+	for _, conn := range s.metrics.stopping {
+		if a := voiceAgentOf(conn); a != nil {
+			set.addAgent(a, false)
+		}
+	}
+	set.merge(s.metrics.retired)
+	s.metrics.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP streamlink_sessions Number of active sessions.")
+	fmt.Fprintln(w, "# TYPE streamlink_sessions gauge")
+	fmt.Fprintf(w, "streamlink_sessions %d\n", sessions)
+	writeComponentMetrics(w, set)
+}
+
+// writeComponentMetrics 按 Prometheus 文本格式写出组件指标，标签按字典序排列保证输出稳定
+func writeComponentMetrics(w io.Writer, set metricsSet) {
+	keys := make([]seriesKey, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].profile != keys[j].profile {
+			return keys[i].profile < keys[j].profile
+		}
+		return keys[i].component < keys[j].component
+	})
+
+	counters := []struct {
+		name, help string
+		value      func(*componentSeries) int64
+	}{
+		{"streamlink_component_processed_total", "Packets processed by the component.", func(s *componentSeries) int64 { return s.processed }},
+		{"streamlink_component_dropped_total", "Packets dropped by the component.", func(s *componentSeries) int64 { return s.dropped }},
+		{"streamlink_component_errors_total", "Errors reported by the component.", func(s *componentSeries) int64 { return s.errors }},
+		{"streamlink_component_blocked_total", "Sends that blocked on a full output channel.", func(s *componentSeries) int64 { return s.blocked }},
+		{"streamlink_component_coalesced_total", "Packets merged by the coalesce backpressure policy.", func(s *componentSeries) int64 { return s.coalesced }},
+	}
+	for _, m := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", m.name, m.help, m.name)
+		for _, key := range keys {
+			fmt.Fprintf(w, "%s{%s} %d\n", m.name, labels(key), m.value(set[key]))
+		}
+	}
+
+	gauges := []struct {
+		name, help string
+		value      func(*componentSeries) int
+	}{
+		{"streamlink_component_input_queue_depth", "Packets queued on the component input channel.", func(s *componentSeries) int { return s.inputQueue }},
+		{"streamlink_component_output_queue_depth", "Packets queued on the component output channel.", func(s *componentSeries) int { return s.outputQueue }},
+	}
+	for _, m := range gauges {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+		for _, key := range keys {
+			if set[key].hasLiveGauges {
+				fmt.Fprintf(w, "%s{%s} %d\n", m.name, labels(key), m.value(set[key]))
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP streamlink_component_state Number of components in each state.")
+	fmt.Fprintln(w, "# TYPE streamlink_component_state gauge")
+	for _, key := range keys {
+		s := set[key]
+		if !s.hasLiveGauges {
+			continue
+		}
+		for _, state := range componentStates {
+			fmt.Fprintf(w, "streamlink_component_state{%s,state=%s} %d\n", labels(key), quote(state.String()), s.states[state])
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP streamlink_latency_seconds Turn latency by stage.")
+	fmt.Fprintln(w, "# TYPE streamlink_latency_seconds histogram")
+	for _, key := range keys {
+		s := set[key]
+		stages := make([]string, 0, len(s.latencies))
+		for stage := range s.latencies {
+			stages = append(stages, stage)
+		}
+		sort.Strings(stages)
+		for _, stage := range stages {
+			writeHistogram(w, "streamlink_latency_seconds", labels(key)+",stage="+quote(stage), *s.latencies[stage])
+		}
+	}
+}
+
+// writeHistogram 写出直方图的累积桶、总和与样本数
+func writeHistogram(w io.Writer, name, labels string, h pipeline.HistogramSnapshot) {
+	var cumulative uint64
+	for i, bound := range h.Bounds {
+		cumulative += h.Counts[i]
+		fmt.Fprintf(w, "%s_bucket{%s,le=%q} %d\n", name, labels, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.Count)
+	fmt.Fprintf(w, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(h.Sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count{%s} %d\n", name, labels, h.Count)
+}
+
+// labels 返回 profile 与 component 标签
+func labels(key seriesKey) string {
+	return "profile=" + quote(key.profile) + ",component=" + quote(key.component)
+}
+
+// labelEscaper 按 Prometheus 文本格式转义标签值
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quote(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
+
+// componentName 获取组件名称
+func componentName(c pipeline.Component) string {
+	if named, ok := c.(interface{ GetName() string }); ok {
+		return named.GetName()
+	}
+	return fmt.Sprintf("%T", c)
+}
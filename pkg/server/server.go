@@ -20,10 +20,11 @@ type WHIPServer struct {
 	udpMux        ice.UDPMux
 	config        *config.Config
 	webrtcFactory *connection.WebRTCFactory
+	metrics       *metricsCollector
 }
 
 func NewVoiceAgentServer() *WHIPServer {
-	return &WHIPServer{}
+	return &WHIPServer{metrics: newMetricsCollector()}
 }
 
 func (s *WHIPServer) Init(config *config.Config) error {
@@ -73,7 +74,7 @@ func (s *WHIPServer) Init(config *config.Config) error {
 
 func (s *WHIPServer) HandleNewConnection(offer *webrtc.SessionDescription) (*webrtc.SessionDescription, string, error) {
 	// 使用工厂创建新连接
-	conn, err := s.webrtcFactory.CreateConnection(s.config)
+	conn, err := s.webrtcFactory.CreateConnection(s.config, s.handleFatal)
 	if err != nil {
 		return nil, "", err
 	}
@@ -95,20 +96,41 @@ func (s *WHIPServer) HandleNewConnection(offer *webrtc.SessionDescription) (*web
 	}
 	logger.Info("answer: %v", answer)
 
+	// 先保存连接再启动，启动后的升级失败才能通过 DelConnection 移除会话
+	s.connections.Store(conn.GetID(), conn)
+
 	// 启动连接
 	if err := conn.Start(); err != nil {
-		conn.Stop()
+		s.DelConnection(conn.GetID())
 		return nil, "", err
 	}
 
-	// 保存连接
-	s.connections.Store(conn.GetID(), conn)
-
 	return answer, conn.GetID(), nil
 }
 
 func (s *WHIPServer) DelConnection(id string) {
-	if conn, exists := s.connections.LoadAndDelete(id); exists {
-		conn.(connection.Connection).Stop()
+	// 先认领连接，并发的 DELETE 或升级只有一方能拿到并停止它
+	s.metrics.mu.Lock()
+	conn, exists := s.connections.LoadAndDelete(id)
+	if exists {
+		s.metrics.stopping[id] = conn
 	}
+	s.metrics.mu.Unlock()
+	if !exists {
+		return
+	}
+
+	conn.(connection.Connection).Stop()
+
+	// 停止后会话的最终指标计入已结束会话的累计值
+	s.metrics.mu.Lock()
+	defer s.metrics.mu.Unlock()
+	delete(s.metrics.stopping, id)
+	s.metrics.retire(conn)
+}
+
+// handleFatal 在会话不可恢复时移除并停止连接，与 DELETE 请求走同一路径
+func (s *WHIPServer) handleFatal(id string, err error) {
+	logger.Error("[%s] Session failed, removing connection: %v", id, err)
+	s.DelConnection(id)
 }
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleTraces 返回会话最近完成的轮次延迟追踪
+func (s *WHIPServer) HandleTraces(c *gin.Context) {
+	sessionID := c.Param("id")
+	voiceAgent := s.getVoiceAgent(sessionID)
+	if voiceAgent == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"session": sessionID, "traces": voiceAgent.Traces()})
+}
+
+// HandleTrace 返回会话指定轮次的延迟追踪
+func (s *WHIPServer) HandleTrace(c *gin.Context) {
+	sessionID := c.Param("id")
+	turnSeq, err := strconv.Atoi(c.Param("turn"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid turn: " + c.Param("turn")})
+		return
+	}
+	voiceAgent := s.getVoiceAgent(sessionID)
+	if voiceAgent == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+	trace, ok := voiceAgent.Trace(turnSeq)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trace not found"})
+		return
+	}
+	c.JSON(http.StatusOK, trace)
+}
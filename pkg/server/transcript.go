@@ -0,0 +1,57 @@
+package server
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"streamlink/pkg/logger"
+	"streamlink/pkg/logic/pipeline"
+	"streamlink/pkg/server/agent"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleTranscript 返回会话中用户与代理的发言，会话已结束时读取保存的记录
+// 正在排空停止的会话尚未保存记录，仍从其语音代理读取
+func (s *WHIPServer) HandleTranscript(c *gin.Context) {
+	sessionID := c.Param("id")
+	voiceAgent := s.getVoiceAgent(sessionID)
+	if voiceAgent == nil {
+		voiceAgent = s.stoppingVoiceAgent(sessionID)
+	}
+	if voiceAgent != nil {
+		c.JSON(http.StatusOK, gin.H{"session": sessionID, "entries": voiceAgent.Transcript()})
+		return
+	}
+
+	if s.config == nil || s.config.Server.Transcript.Dir == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not active and transcript persistence is disabled (server.transcript.dir is empty)"})
+		return
+	}
+	// 会话 ID 作为文件名，不允许跳出记录目录
+	if strings.ContainsAny(sessionID, `/\`) || strings.Contains(sessionID, "..") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+	entries, err := pipeline.LoadTranscript(filepath.Join(s.config.Server.Transcript.Dir, sessionID+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+	if err != nil {
+		logger.Error("[%s] Failed to load transcript: %v", sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"session": sessionID, "entries": entries})
+}
+
+// stoppingVoiceAgent 查找已从 connections 移除、正在停止的会话的语音代理
+func (s *WHIPServer) stoppingVoiceAgent(sessionID string) *agent.VoiceAgent {
+	s.metrics.mu.Lock()
+	defer s.metrics.mu.Unlock()
+	return voiceAgentOf(s.metrics.stopping[sessionID])
+}